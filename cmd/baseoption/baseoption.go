@@ -32,6 +32,7 @@ func AddRootInputOptionsToContext(ctx context.Context, cmd *cobra.Command) conte
 		ConfigFileOnly:      configFileOnly,
 		VerbosityWithCaller: verbosityWithCaller,
 		OutputFormat:        outputFormat,
+		Version:             cmd.Root().Version,
 	}
 
 	return model.WithCLIOpt(ctx, cliOpt)
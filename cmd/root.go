@@ -10,6 +10,7 @@ import (
 	"itiquette/git-provider-sync/cmd/mancmd"
 	"itiquette/git-provider-sync/cmd/printcmd"
 	"itiquette/git-provider-sync/cmd/synccmd"
+	"itiquette/git-provider-sync/cmd/verifycmd"
 	"itiquette/git-provider-sync/internal/model"
 
 	"github.com/spf13/cobra"
@@ -38,7 +39,7 @@ Allows syncing to multiple mirror destinations.`,
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 
 	// Add subcommands,
-	rootCmd.AddCommand(mancmd.NewManCommand(), printcmd.NewPrintCommand(), synccmd.NewSyncCommand())
+	rootCmd.AddCommand(mancmd.NewManCommand(), printcmd.NewPrintCommand(), synccmd.NewSyncCommand(), verifycmd.NewVerifyCommand())
 
 	return rootCmd
 }
@@ -22,7 +22,7 @@ func TestExecuteRootCommandNoArgumentShowHelp(t *testing.T) {
 	cmdOutput := bytes.NewBufferString("")
 	cmd.SetOut(cmdOutput)
 
-	require.Len(cmd.Commands(), 3)
+	require.Len(cmd.Commands(), 4)
 
 	subCmdNames := make([]string, 0, 2)
 	for _, v := range cmd.Commands() {
@@ -30,6 +30,7 @@ func TestExecuteRootCommandNoArgumentShowHelp(t *testing.T) {
 	}
 
 	require.Contains(subCmdNames, "print", "sync")
+	require.Contains(subCmdNames, "verify")
 
 	_ = cmd.Execute()
 
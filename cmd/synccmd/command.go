@@ -56,9 +56,15 @@ func addInputOptionsToContext(ctx context.Context, flags *syncInputOption) conte
 
 	cliOpts.AlphaNumHyphName = flags.alphaNumHyphName
 	cliOpts.ActiveFromLimit = flags.activeFromLimit
+	cliOpts.ContinueOnError = flags.continueOnError
 	cliOpts.DryRun = flags.dryRun
 	cliOpts.ForcePush = flags.forcePush
+	cliOpts.Full = flags.full
 	cliOpts.IgnoreInvalidName = flags.ignoreInvalidName
+	cliOpts.Parallel = flags.parallel
+	cliOpts.Prune = flags.prune
+	cliOpts.PruneDryRun = flags.pruneDryRun
+	cliOpts.RetentionDryRun = flags.retentionDryRun
 
 	return model.WithCLIOpt(ctx, cliOpts)
 }
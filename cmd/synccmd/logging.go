@@ -6,8 +6,11 @@
 package synccmd
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"path"
+	"slices"
 	"strings"
 
 	"itiquette/git-provider-sync/internal/interfaces"
@@ -25,6 +28,10 @@ var (
 
 func initMirrorSync(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg gpsconfig.MirrorConfig, repositories []interfaces.GitRepository) context.Context {
 	meta := model.NewSyncRunMetainfo(0, syncCfg.GetDomain(), mirrorCfg.ProviderType, len(repositories))
+	if runMeta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(*model.SyncRunMetainfo); ok {
+		meta.SetParent(runMeta)
+	}
+
 	ctx = context.WithValue(ctx, model.SyncRunMetainfoKey{}, meta)
 
 	logSyncStart(ctx, mirrorCfg)
@@ -44,6 +51,12 @@ func logSyncRunInfo(logger *zerolog.Logger, mirrorCfg gpsconfig.MirrorConfig) {
 		logger.Info().Str("directory path", mirrorCfg.Path).Msg("Targeting")
 	case gpsconfig.ARCHIVE:
 		logger.Info().Str("archive directory path", mirrorCfg.Path).Msg("Targeting")
+	case gpsconfig.S3:
+		logger.Info().
+			Str("bucket", mirrorCfg.S3.Bucket).
+			Str("endpoint", mirrorCfg.S3.Endpoint).
+			Str("prefix", mirrorCfg.S3.Prefix).
+			Msg("Targeting")
 	default:
 		logger.Info().
 			Str("ProviderType", mirrorCfg.ProviderType).
@@ -69,26 +82,74 @@ func summary(ctx context.Context, syncCfg gpsconfig.SyncConfig) {
 		Msg("Completed sync run")
 
 	logger.Info().Msgf("Sync request: %d repositories", syncRunMetaInfo.Total)
+	logger.Info().Msgf("Synced: %d repositories", syncRunMetaInfo.SyncedCount())
+
+	if failed := len(syncRunMetaInfo.Errors()); failed > 0 {
+		logger.Warn().Msgf("Failed: %d", failed)
+	}
+
 	logFailures(logger, syncRunMetaInfo)
 }
 
+// logFailureTable logs every failure recorded during a sync run, one entry per row,
+// sorted by source, target, category and repository.
+func logFailureTable(logger *zerolog.Logger, failures []model.SyncError) {
+	slices.SortStableFunc(failures, func(a, b model.SyncError) int {
+		return cmp.Or(
+			cmp.Compare(a.Source, b.Source),
+			cmp.Compare(a.Target, b.Target),
+			cmp.Compare(a.Category, b.Category),
+			cmp.Compare(a.Repository, b.Repository),
+		)
+	})
+
+	logger.Error().Int("failures", len(failures)).Msg("Sync run completed with failures")
+
+	for _, failure := range failures {
+		logger.Error().
+			Str("category", failure.Category).
+			Str("source", failure.Source).
+			Str("target", failure.Target).
+			Str("repository", failure.Repository).
+			Err(failure.Err).
+			Msg("Failure")
+	}
+}
+
+// sourceLabel identifies a sync source in failure reports.
+func sourceLabel(syncCfg gpsconfig.SyncConfig) string {
+	return syncCfg.GetDomain() + "/" + syncCfg.Owner
+}
+
+// targetLabel identifies a mirror target in failure reports.
+func targetLabel(mirrorCfg gpsconfig.MirrorConfig) string {
+	if mirrorCfg.IsArchive() || mirrorCfg.IsDirectory() {
+		return mirrorCfg.ProviderType + ":" + mirrorCfg.Path
+	}
+
+	if mirrorCfg.ProviderType == gpsconfig.S3 {
+		return gpsconfig.S3 + ":" + path.Join(mirrorCfg.S3.Bucket, mirrorCfg.S3.Prefix)
+	}
+
+	return mirrorCfg.GetDomain() + "/" + mirrorCfg.Owner
+}
+
 func logFailures(logger *zerolog.Logger, meta *model.SyncRunMetainfo) {
-	metaFailPtr := (*meta.Fail)
-	if len(metaFailPtr) == 0 {
+	if !meta.HasFailures() {
 		return
 	}
 
-	if invalidCount := len(metaFailPtr); invalidCount > 0 {
+	if invalid := meta.Failures(model.FailureInvalid); len(invalid) > 0 {
 		logger.Info().
-			Int("invalidCount", invalidCount).
-			Strs("repositories", metaFailPtr["invalid"]).
+			Int("invalidCount", len(invalid)).
+			Strs("repositories", invalid).
 			Msg("skipped repositories due to invalid naming")
 	}
 
-	if upToDateCount := len(metaFailPtr["uptodate"]); upToDateCount > 0 {
+	if upToDate := meta.Failures("uptodate"); len(upToDate) > 0 {
 		logger.Info().
-			Int("upToDateCount", upToDateCount).
-			Strs("repositories", metaFailPtr["uptodate"]).
+			Int("upToDateCount", len(upToDate)).
+			Strs("repositories", upToDate).
 			Msg("ignored up-to-date repositories")
 	}
 }
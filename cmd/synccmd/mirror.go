@@ -11,15 +11,18 @@ import (
 	"fmt"
 	"strings"
 
+	"itiquette/git-provider-sync/internal/configuration"
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/mirror/archive"
 	"itiquette/git-provider-sync/internal/mirror/directory"
 	"itiquette/git-provider-sync/internal/mirror/gitbinary"
 	"itiquette/git-provider-sync/internal/mirror/gitlib"
+	"itiquette/git-provider-sync/internal/mirror/objectstore"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 	"itiquette/git-provider-sync/internal/provider"
+	"itiquette/git-provider-sync/internal/workerpool"
 )
 
 var ErrInvalidRepoName = errors.New("invalid repository name")
@@ -28,6 +31,14 @@ func toMirror(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg gpsco
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering toMirror")
 
+	// Read once for the mirror, so its provider client and git transports share the token
+	authCfg, err := configuration.ResolveCredentials(ctx, mirrorCfg.Auth)
+	if err != nil {
+		return fmt.Errorf("mirror %s: %w", targetLabel(mirrorCfg), err)
+	}
+
+	mirrorCfg.Auth = authCfg
+
 	ctx = initMirrorSync(ctx, syncCfg, mirrorCfg, repositories)
 
 	client, err := createMirrorProviderClient(ctx, syncCfg, mirrorCfg)
@@ -35,10 +46,34 @@ func toMirror(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg gpsco
 		return fmt.Errorf("failed to create mirror provider client: %w", err)
 	}
 
-	for _, repo := range repositories {
+	concurrency := model.Concurrency(ctx, syncCfg)
+	continueOnError := model.ContinueOnError(ctx, syncCfg)
+	logger.Debug().Int("concurrency", concurrency).Bool("continueOnError", continueOnError).Int("repositories", len(repositories)).Msg("toMirror")
+
+	err = workerpool.Run(ctx, concurrency, len(repositories), func(ctx context.Context, index int) error {
+		repo := repositories[index]
+		ctx = log.WithRepository(ctx, repo.ProjectInfo().OriginalName)
+
 		if err := processRepository(ctx, syncCfg, mirrorCfg, client, repo); err != nil {
-			return fmt.Errorf("failed to process repository: %w", err)
+			err = fmt.Errorf("failed to process repository: %w", err)
+			if !continueOnError {
+				return err
+			}
+
+			log.Logger(ctx).Error().Err(err).Msg("Repository failed, continuing")
+			model.RecordError(ctx, model.SyncError{
+				Category:   failureCategory(err),
+				Source:     sourceLabel(syncCfg),
+				Target:     targetLabel(mirrorCfg),
+				Repository: repo.ProjectInfo().OriginalName,
+				Err:        err,
+			})
 		}
+
+		return nil
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
 	}
 
 	summary(ctx, syncCfg)
@@ -46,6 +81,15 @@ func toMirror(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg gpsco
 	return nil
 }
 
+// failureCategory classifies a processRepository error into one of the model.Failure* categories.
+func failureCategory(err error) string {
+	if errors.Is(err, ErrInvalidRepoName) {
+		return model.FailureInvalid
+	}
+
+	return provider.FailureCategory(err)
+}
+
 func processRepository(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg gpsconfig.MirrorConfig, client interfaces.GitProvider, repo interfaces.GitRepository) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering processRepository")
@@ -73,11 +117,21 @@ func processRepository(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirror
 
 	if mirrorCfg.ProviderType == gpsconfig.DIRECTORY {
 		p := model.NewPullOption(repo.ProjectInfo().Name(ctx), "", syncCfg, gpsconfig.AuthConfig{}, "", mirrorCfg.Path)
+		p.Prune = model.Prune(ctx, mirrorCfg)
+		p.PruneDryRun = model.CLIOptions(ctx).PruneDryRun
 		if err := writer.Pull(ctx, p); err != nil {
 			return fmt.Errorf("failed to pull repository for directory target: %w", err)
 		}
 	}
 
+	if syncCfg.Submodules {
+		if err := provider.ReportSubmodules(ctx, syncCfg, mirrorCfg, repo); err != nil {
+			return fmt.Errorf("failed to map submodules: %w", err)
+		}
+	}
+
+	recordSynced(ctx, syncCfg, mirrorCfg, repo)
+
 	return nil
 }
 
@@ -99,7 +153,7 @@ func validateRepository(ctx context.Context, mirrorCfg gpsconfig.MirrorConfig, c
 	}
 
 	if meta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(*model.SyncRunMetainfo); ok {
-		(*meta.Fail)["invalid"] = append((*meta.Fail)["invalid"], name)
+		meta.AddFailure(model.FailureInvalid, name)
 
 		if cliOpts.IgnoreInvalidName || mirrorCfg.Settings.IgnoreInvalidName {
 			return true, nil
@@ -121,7 +175,7 @@ func prepareRepository(ctx context.Context, mirrorCfg gpsconfig.MirrorConfig, re
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering prepareRepository")
 
-	if mirrorCfg.ProviderType == gpsconfig.ARCHIVE {
+	if mirrorCfg.WritesArchives() {
 		return nil
 	}
 
@@ -154,7 +208,7 @@ func pushRepository(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering pushRepository")
 
-	writer, err := getMirrorWriter(mirrorCfg)
+	writer, err := getMirrorWriter(ctx, mirrorCfg)
 	if err != nil {
 		return nil, fmt.Errorf("get mirror writer: %w", err)
 	}
@@ -170,14 +224,33 @@ func pushRepository(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg
 	return writer, nil
 }
 
-func getMirrorWriter(mirrorCfg gpsconfig.MirrorConfig) (interfaces.MirrorWriter, error) {
+func getMirrorWriter(ctx context.Context, mirrorCfg gpsconfig.MirrorConfig) (interfaces.MirrorWriter, error) {
 	switch strings.ToLower(mirrorCfg.ProviderType) {
 	case gpsconfig.ARCHIVE:
 		gitHandler := archive.NewGitHandler(gitlib.NewService())
 		storageHandler := archive.NewStorageHandler()
-		archiverHandler := archive.NewHandler()
+		encryption, err := archive.NewEncryption(mirrorCfg.Encryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create archive encryption: %w", err)
+		}
 
-		return archive.NewService(*gitHandler, storageHandler, archiverHandler), nil
+		archiverHandler := archive.NewHandler().WithCompressionLevel(mirrorCfg.Settings.CompressionLevel).WithEncryption(encryption)
+
+		return archive.NewService(*gitHandler, storageHandler, archiverHandler).WithEncryption(encryption), nil
+	case gpsconfig.S3:
+		encryption, err := archive.NewEncryption(mirrorCfg.Encryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create archive encryption: %w", err)
+		}
+
+		client, err := provider.NewObjectStoreClient(ctx, mirrorCfg)
+		if err != nil {
+			return nil, fmt.Errorf("create object storage client: %w", err)
+		}
+
+		archiverHandler := archive.NewHandler().WithCompressionLevel(mirrorCfg.Settings.CompressionLevel).WithEncryption(encryption)
+
+		return objectstore.NewService(client, archive.NewGitHandler(gitlib.NewService()), archiverHandler).WithEncryption(encryption), nil
 	case gpsconfig.DIRECTORY:
 		gitHandler := directory.NewGitHandler(gitlib.NewService())
 		storageHandler := directory.NewStorageHandler()
@@ -198,7 +271,7 @@ func getMirrorWriter(mirrorCfg gpsconfig.MirrorConfig) (interfaces.MirrorWriter,
 }
 
 func incrementSyncCount(ctx context.Context) {
-	if meta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(model.SyncRunMetainfo); ok {
-		meta.Total++
+	if meta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(*model.SyncRunMetainfo); ok {
+		meta.IncrementSynced()
 	}
 }
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package synccmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/configuration"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider"
+)
+
+func TestFailureCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "invalid name", err: fmt.Errorf("wrapped: %w: my repo", ErrInvalidRepoName), want: model.FailureInvalid},
+		{name: "create", err: fmt.Errorf("wrapped: %w", provider.ErrCreateRepository), want: model.FailureCreate},
+		{name: "unknown", err: errors.New("unknown"), want: model.FailurePush},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, failureCategory(tabletest.err))
+		})
+	}
+}
+
+func TestToMirrorUnresolvedCredentials(t *testing.T) {
+	t.Setenv("GPS_TEST_MIRROR_TOKEN", "")
+
+	mirrorCfg := gpsconfig.MirrorConfig{BaseConfig: gpsconfig.BaseConfig{ProviderType: gpsconfig.GITHUB, Owner: "owner"}}
+	mirrorCfg.Auth.TokenEnv = "GPS_TEST_MIRROR_TOKEN"
+
+	// Only this mirror fails, the source and other mirrors keep their credentials
+	err := toMirror(model.WithCLIOpt(context.Background(), model.CLIOption{}), gpsconfig.SyncConfig{}, mirrorCfg, nil)
+	require.ErrorIs(t, err, configuration.ErrResolveToken)
+}
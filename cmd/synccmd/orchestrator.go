@@ -7,12 +7,16 @@ package synccmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"itiquette/git-provider-sync/internal/configuration"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 )
 
+var ErrSyncFailures = errors.New("sync run completed with failures")
+
 func sync(ctx context.Context, cfg *gpsconfig.AppConfiguration) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering sync")
@@ -25,14 +29,36 @@ func sync(ctx context.Context, cfg *gpsconfig.AppConfiguration) error {
 
 	//defer cleanup(ctx)
 
+	runMeta := model.NewSyncRunMetainfo(0, "", "", 0)
+	ctx = context.WithValue(ctx, model.SyncRunMetainfoKey{}, runMeta)
+
+	ctx = withSyncState(ctx)
+	defer saveSyncState(ctx)
+
 	for envName, environments := range cfg.GitProviderSyncConfs {
 		for syncCfgName, syncCfg := range environments {
 			if err := sourceToMirror(ctx, syncCfg); err != nil {
-				return fmt.Errorf("failed to mirror environment: %s, syncCfg: %s, %w", envName, syncCfgName, err)
+				err = fmt.Errorf("failed to mirror environment: %s, syncCfg: %s, %w", envName, syncCfgName, err)
+				if !model.ContinueOnError(ctx, syncCfg) {
+					return err
+				}
+
+				logger.Error().Err(err).Msg("Source failed, continuing")
+				runMeta.AddError(model.SyncError{
+					Category: model.FailureSource,
+					Source:   sourceLabel(syncCfg),
+					Err:      err,
+				})
 			}
 		}
 	}
 
+	if failures := runMeta.Errors(); len(failures) > 0 {
+		logFailureTable(logger, failures)
+
+		return fmt.Errorf("%w: %d failures", ErrSyncFailures, len(failures))
+	}
+
 	logger.Info().Msg("All syncs completed")
 
 	return nil
@@ -42,6 +68,11 @@ func sourceToMirror(ctx context.Context, syncCfg gpsconfig.SyncConfig) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering sourceToMirror")
 
+	syncCfg, err := resolveCredentials(ctx, syncCfg)
+	if err != nil {
+		return err
+	}
+
 	repositories, err := sourceRepositories(ctx, syncCfg)
 	if err != nil {
 		return fmt.Errorf("failed to fetch source repositories: %w", err)
@@ -49,13 +80,38 @@ func sourceToMirror(ctx context.Context, syncCfg gpsconfig.SyncConfig) error {
 
 	for _, mirrorCfg := range syncCfg.Mirrors {
 		if err := toMirror(ctx, syncCfg, mirrorCfg, repositories); err != nil {
-			return fmt.Errorf("failed to sync to mirror: %w", err)
+			err = fmt.Errorf("failed to sync to mirror: %w", err)
+			if !model.ContinueOnError(ctx, syncCfg) {
+				return err
+			}
+
+			logger.Error().Err(err).Msg("Mirror failed, continuing")
+			model.RecordError(ctx, model.SyncError{
+				Category: model.FailureMirror,
+				Source:   sourceLabel(syncCfg),
+				Target:   targetLabel(mirrorCfg),
+				Err:      err,
+			})
 		}
 	}
 
 	return nil
 }
 
+// resolveCredentials reads the token of the source from its credential source,
+// once per run, so the provider client and the git transports share it.
+// Mirrors resolve theirs in toMirror, so a mirror whose token cannot be read fails alone.
+func resolveCredentials(ctx context.Context, syncCfg gpsconfig.SyncConfig) (gpsconfig.SyncConfig, error) {
+	authCfg, err := configuration.ResolveCredentials(ctx, syncCfg.Auth)
+	if err != nil {
+		return syncCfg, fmt.Errorf("source %s: %w", sourceLabel(syncCfg), err)
+	}
+
+	syncCfg.Auth = authCfg
+
+	return syncCfg, nil
+}
+
 // func cleanup(ctx context.Context) {
 // 	logger := log.Logger(ctx)
 // 	logger.Trace().Msg("Entering cleanup")
@@ -11,6 +11,8 @@ import (
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/mirror/directory"
 	"itiquette/git-provider-sync/internal/mirror/gitbinary"
 	"itiquette/git-provider-sync/internal/mirror/gitlib"
 	"itiquette/git-provider-sync/internal/model"
@@ -51,11 +53,21 @@ func sourceRepositories(ctx context.Context, syncCfg gpsconfig.SyncConfig) ([]in
 		return nil, fmt.Errorf("get source reader: %w", err)
 	}
 
+	listed := projectInfos
+	projectInfos = skipUnchanged(ctx, syncCfg, reader, projectInfos)
+
 	repositories, err := provider.Clone(ctx, reader, syncCfg, projectInfos)
 	if err != nil {
 		return nil, fmt.Errorf("clone repositories: %w", err)
 	}
 
+	if syncCfg.Submodules {
+		repositories, err = provider.AddSubmoduleRepositories(ctx, reader, syncCfg, providerClient, listed, repositories)
+		if err != nil {
+			return nil, fmt.Errorf("add submodule repositories: %w", err)
+		}
+	}
+
 	return repositories, nil
 }
 
@@ -63,6 +75,22 @@ func getSourceReader(ctx context.Context, syncCfg gpsconfig.SyncConfig) (interfa
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering getSourceReader")
 
+	switch syncCfg.ProviderType {
+	case gpsconfig.ARCHIVE:
+		encryption, err := archive.NewEncryption(syncCfg.Encryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create archive encryption: %w", err)
+		}
+
+		logger.Debug().Msg("Initialized archive SourceReader")
+
+		return archive.NewReader().WithEncryption(encryption), nil
+	case gpsconfig.DIRECTORY:
+		logger.Debug().Msg("Initialized directory SourceReader")
+
+		return directory.NewReader(), nil
+	}
+
 	if !syncCfg.UseGitBinary {
 		logger.Debug().Msg("Initialized go-git SourceReader")
 
@@ -88,6 +116,10 @@ func createProviderClient(ctx context.Context, syncCfg gpsconfig.SyncConfig) (in
 		AuthCfg:      syncCfg.Auth,
 		Domain:       syncCfg.Domain,
 		Repositories: syncCfg.Repositories,
+		URLs:         syncCfg.URLs,
+		URLTemplate:  syncCfg.URLTemplate,
+		Path:         syncCfg.Path,
+		Encryption:   syncCfg.Encryption,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize provider client: %w", err)
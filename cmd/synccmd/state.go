@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// state.go - Skipping repositories unchanged since the last sync run
+package synccmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/syncstate"
+	"itiquette/git-provider-sync/internal/workerpool"
+)
+
+// withSyncState loads the sync state and adds it to ctx.
+// The state is not used in dry-run mode, or when it cannot be loaded.
+func withSyncState(ctx context.Context) context.Context {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering withSyncState")
+
+	if model.CLIOptions(ctx).DryRun {
+		return ctx
+	}
+
+	path, err := syncstate.DefaultPath()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Sync state disabled, every repository will be synced")
+
+		return ctx
+	}
+
+	store, err := syncstate.Load(path)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Sync state disabled, every repository will be synced")
+
+		return ctx
+	}
+
+	logger.Debug().Str("path", path).Msg("Loaded sync state")
+
+	return syncstate.WithStore(ctx, store)
+}
+
+// saveSyncState writes the sync state of ctx, if any.
+func saveSyncState(ctx context.Context) {
+	store := syncstate.FromContext(ctx)
+	if store == nil {
+		return
+	}
+
+	if err := store.Save(ctx); err != nil {
+		log.Logger(ctx).Warn().Err(err).Msg("Failed to save sync state")
+	}
+}
+
+// skipUnchanged lists the refs of every source repository and leaves out the repositories
+// whose refs equal those last pushed to every mirror of syncCfg.
+// The listed refs are kept in ProjectInfo.RefTips to be recorded once pushed.
+// Repositories whose refs cannot be listed are kept. With the full option nothing is left out.
+func skipUnchanged(ctx context.Context, syncCfg gpsconfig.SyncConfig, reader interfaces.SourceReader, projectInfos []model.ProjectInfo) []model.ProjectInfo {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering skipUnchanged")
+
+	store := syncstate.FromContext(ctx)
+	lister, ok := reader.(interfaces.RefLister)
+
+	if store == nil || !ok {
+		return projectInfos
+	}
+
+	full := model.CLIOptions(ctx).Full
+	keep := make([]bool, len(projectInfos))
+
+	_ = workerpool.Run(ctx, model.Concurrency(ctx, syncCfg), len(projectInfos), func(ctx context.Context, index int) error {
+		projectInfo := &projectInfos[index]
+		ctx = log.WithRepository(ctx, projectInfo.OriginalName)
+
+		refs, err := lister.ListRefs(ctx, refListOption(ctx, syncCfg, *projectInfo))
+		if err != nil {
+			log.Logger(ctx).Warn().Err(err).Msg("Failed to list refs, syncing repository")
+
+			keep[index] = true
+
+			return nil
+		}
+
+		projectInfo.RefTips = refs
+
+		if full || !unchangedInAllMirrors(store, syncCfg, projectInfo.OriginalName, refs) {
+			keep[index] = true
+
+			return nil
+		}
+
+		log.Logger(ctx).Info().Msg("Unchanged since last sync, skipping")
+
+		return nil
+	})
+
+	kept := make([]model.ProjectInfo, 0, len(projectInfos))
+
+	for index, projectInfo := range projectInfos {
+		if keep[index] {
+			kept = append(kept, projectInfo)
+		}
+	}
+
+	logger.Debug().Int("repositories", len(projectInfos)).Int("skipped", len(projectInfos)-len(kept)).Msg("skipUnchanged")
+
+	return kept
+}
+
+func unchangedInAllMirrors(store *syncstate.Store, syncCfg gpsconfig.SyncConfig, name string, refs map[string]string) bool {
+	for _, mirrorCfg := range syncCfg.Mirrors {
+		if !store.Unchanged(syncstate.Key(sourceLabel(syncCfg), targetLabel(mirrorCfg), name), refs) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// refListOption builds the option used to list the refs of a source repository.
+func refListOption(ctx context.Context, syncCfg gpsconfig.SyncConfig, projectInfo model.ProjectInfo) model.CloneOption {
+	url := projectInfo.HTTPSURL
+	if strings.EqualFold(syncCfg.Auth.Protocol, gpsconfig.SSH) {
+		url = projectInfo.SSHURL
+	}
+
+	return model.CloneOption{
+		Name:      projectInfo.Name(ctx),
+		URL:       url,
+		SourceCfg: syncCfg,
+		AuthCfg:   syncCfg.Auth,
+	}
+}
+
+// recordSynced records the refs pushed for repo to mirrorCfg in the sync state of ctx.
+func recordSynced(ctx context.Context, syncCfg gpsconfig.SyncConfig, mirrorCfg gpsconfig.MirrorConfig, repo interfaces.GitRepository) {
+	store := syncstate.FromContext(ctx)
+	if store == nil || repo.ProjectInfo().RefTips == nil {
+		return
+	}
+
+	key := syncstate.Key(sourceLabel(syncCfg), targetLabel(mirrorCfg), repo.ProjectInfo().OriginalName)
+	store.Record(key, repo.ProjectInfo().RefTips, time.Now())
+}
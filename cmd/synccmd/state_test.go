@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package synccmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/syncstate"
+)
+
+type fakeRefLister struct {
+	refs map[string]map[string]string
+}
+
+func (f fakeRefLister) Clone(context.Context, model.CloneOption) (model.Repository, error) {
+	return model.Repository{}, nil
+}
+
+func (f fakeRefLister) ListRefs(_ context.Context, opt model.CloneOption) (map[string]string, error) {
+	refs, ok := f.refs[opt.Name]
+	if !ok {
+		return nil, errors.New("unreachable")
+	}
+
+	return refs, nil
+}
+
+func TestSkipUnchanged(t *testing.T) {
+	syncCfg := gpsconfig.SyncConfig{
+		BaseConfig: gpsconfig.BaseConfig{Domain: "gitlab.com", Owner: "owner"},
+		Mirrors: map[string]gpsconfig.MirrorConfig{
+			"a": {BaseConfig: gpsconfig.BaseConfig{Domain: "github.com", Owner: "owner"}},
+			"b": {BaseConfig: gpsconfig.BaseConfig{Domain: "gitea.com", Owner: "owner"}},
+		},
+	}
+
+	unchanged := map[string]string{"refs/heads/main": "abc"}
+	reader := fakeRefLister{refs: map[string]map[string]string{
+		"unchanged":      unchanged,
+		"changed":        {"refs/heads/main": "def"},
+		"partly-pushed":  unchanged,
+		"never-recorded": unchanged,
+	}}
+
+	projectInfos := []model.ProjectInfo{
+		{OriginalName: "unchanged"},
+		{OriginalName: "changed"},
+		{OriginalName: "partly-pushed"},
+		{OriginalName: "never-recorded"},
+		{OriginalName: "unlistable"},
+	}
+
+	newStore := func(t *testing.T) *syncstate.Store {
+		t.Helper()
+
+		store, err := syncstate.Load(filepath.Join(t.TempDir(), "state.json"))
+		require.NoError(t, err)
+
+		for _, mirrorCfg := range syncCfg.Mirrors {
+			for _, name := range []string{"unchanged", "changed"} {
+				store.Record(syncstate.Key(sourceLabel(syncCfg), targetLabel(mirrorCfg), name), unchanged, time.Now())
+			}
+		}
+
+		store.Record(syncstate.Key(sourceLabel(syncCfg), targetLabel(syncCfg.Mirrors["a"]), "partly-pushed"), unchanged, time.Now())
+
+		return store
+	}
+
+	names := func(infos []model.ProjectInfo) []string {
+		result := make([]string, 0, len(infos))
+		for _, info := range infos {
+			result = append(result, info.OriginalName)
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name string
+		full bool
+		want []string
+	}{
+		{
+			name: "skips repositories unchanged in every mirror",
+			want: []string{"changed", "partly-pushed", "never-recorded", "unlistable"},
+		},
+		{
+			name: "full keeps every repository",
+			full: true,
+			want: []string{"unchanged", "changed", "partly-pushed", "never-recorded", "unlistable"},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			ctx := model.WithCLIOpt(context.Background(), model.CLIOption{Full: tabletest.full})
+			ctx = syncstate.WithStore(ctx, newStore(t))
+
+			got := skipUnchanged(ctx, syncCfg, reader, append([]model.ProjectInfo(nil), projectInfos...))
+			require.Equal(t, tabletest.want, names(got))
+
+			for _, info := range got {
+				if info.OriginalName == "unlistable" {
+					require.Nil(t, info.RefTips)
+				} else {
+					require.Equal(t, reader.refs[info.OriginalName], info.RefTips)
+				}
+			}
+		})
+	}
+}
+
+func TestSkipUnchangedWithoutStore(t *testing.T) {
+	projectInfos := []model.ProjectInfo{{OriginalName: "repo"}}
+
+	got := skipUnchanged(model.WithCLIOpt(context.Background(), model.CLIOption{}), gpsconfig.SyncConfig{}, fakeRefLister{}, projectInfos)
+	require.Equal(t, projectInfos, got)
+}
@@ -7,36 +7,57 @@ package synccmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 )
 
+var ErrInvalidParallel = errors.New("parallel must not be negative")
+
 type syncInputOption struct {
 	activeFromLimit   string
 	alphaNumHyphName  bool
+	continueOnError   bool
 	dryRun            bool
 	forcePush         bool
+	full              bool
 	ignoreInvalidName bool
+	parallel          int
+	prune             bool
+	pruneDryRun       bool
+	retentionDryRun   bool
 }
 
 func addSyncInputOptions(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.Bool("alphanumhyph-name", false, "Mirror target name will only contain alpha numeric or hyphen (lessen incompatible characters)")
+	flags.Bool("continue-on-error", false, "Record failing repositories, mirrors and sources and continue, exit non-zero at the end")
 	flags.Bool("dry-run", false, "Simulate sync run without performing clone and push actions")
 	flags.Bool("force-push", false, "Overwrite existing mirror target with force")
+	flags.Bool("full", false, "Clone and push every repository, even those unchanged since the last sync run")
 	flags.Bool("ignore-invalid-name", false, "Don't fail on invalid mirror target names, ignore them")
 	flags.String("active-from-limit", "", "A negative time duration (e.g., '-1h') to consider repositories active from")
+	flags.Int("parallel", 0, "Number of repositories to clone and push concurrently (overrides the concurrency setting)")
+	flags.Bool("prune", false, "Delete branches and tags at the mirrors that were deleted at the source")
+	flags.Bool("prune-dry-run", false, "List the branches and tags pruning would delete at the mirrors, without deleting them")
+	flags.Bool("retention-dry-run", false, "List the archives retention would delete at the archive mirrors, without deleting them")
 }
 
 func (sio syncInputOption) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 	return logger.Debug(). //nolint:zerologlint
 				Bool("alphaNumHyphName", sio.alphaNumHyphName).
+				Bool("continueOnError", sio.continueOnError).
 				Bool("dryRun", sio.dryRun).
 				Bool("forcePush", sio.forcePush).
+				Bool("full", sio.full).
 				Bool("ignoreInvalidName", sio.ignoreInvalidName).
-				Str("activeFromLimit", sio.activeFromLimit)
+				Str("activeFromLimit", sio.activeFromLimit).
+				Int("parallel", sio.parallel).
+				Bool("prune", sio.prune).
+				Bool("pruneDryRun", sio.pruneDryRun).
+				Bool("retentionDryRun", sio.retentionDryRun)
 }
 
 func getSyncInputOptions(_ context.Context, cmd *cobra.Command) (*syncInputOption, error) {
@@ -48,6 +69,10 @@ func getSyncInputOptions(_ context.Context, cmd *cobra.Command) (*syncInputOptio
 		return nil, fmt.Errorf("get alphanumhyph-name flag: %w", err)
 	}
 
+	if flags.continueOnError, err = cmd.Flags().GetBool("continue-on-error"); err != nil {
+		return nil, fmt.Errorf("get continue-on-error flag: %w", err)
+	}
+
 	if flags.dryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
 		return nil, fmt.Errorf("get dry-run flag: %w", err)
 	}
@@ -56,6 +81,10 @@ func getSyncInputOptions(_ context.Context, cmd *cobra.Command) (*syncInputOptio
 		return nil, fmt.Errorf("get force-push flag: %w", err)
 	}
 
+	if flags.full, err = cmd.Flags().GetBool("full"); err != nil {
+		return nil, fmt.Errorf("get full flag: %w", err)
+	}
+
 	if flags.ignoreInvalidName, err = cmd.Flags().GetBool("ignore-invalid-name"); err != nil {
 		return nil, fmt.Errorf("get ignore-invalid-name flag: %w", err)
 	}
@@ -64,5 +93,25 @@ func getSyncInputOptions(_ context.Context, cmd *cobra.Command) (*syncInputOptio
 		return nil, fmt.Errorf("get active-from-limit flag: %w", err)
 	}
 
+	if flags.parallel, err = cmd.Flags().GetInt("parallel"); err != nil {
+		return nil, fmt.Errorf("get parallel flag: %w", err)
+	}
+
+	if flags.prune, err = cmd.Flags().GetBool("prune"); err != nil {
+		return nil, fmt.Errorf("get prune flag: %w", err)
+	}
+
+	if flags.pruneDryRun, err = cmd.Flags().GetBool("prune-dry-run"); err != nil {
+		return nil, fmt.Errorf("get prune-dry-run flag: %w", err)
+	}
+
+	if flags.retentionDryRun, err = cmd.Flags().GetBool("retention-dry-run"); err != nil {
+		return nil, fmt.Errorf("get retention-dry-run flag: %w", err)
+	}
+
+	if flags.parallel < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidParallel, flags.parallel)
+	}
+
 	return flags, nil
 }
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package verifycmd provides the command verifying the archives written by archive mirrors.
+// It proves a backup complete and uncorrupted, before it is needed for a restore.
+package verifycmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"itiquette/git-provider-sync/cmd/baseoption"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/spf13/cobra"
+)
+
+var ErrVerifyArchives = errors.New("archives failed verification")
+
+// NewVerifyCommand creates and returns a new cobra.Command for the 'verify' subcommand.
+// It verifies archives, given as archive files or directories holding archives.
+//
+// Example usage:
+//
+//	git-provider-sync verify /backup/archives
+//	git-provider-sync verify --identity-file /secrets/backup.key /backup/archives
+func NewVerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify <archive or directory>...",
+		Short: "Verify archives written by archive mirrors",
+		Long: `The 'verify' command verifies archives written by archive mirrors, given as archive files or directories holding them.
+The checksum of each archive is compared with its manifest, then the archive is unpacked to a temporary directory
+and the connectivity of the repository inside is checked: every object reachable from its refs must be present and intact.
+Encrypted archives are decrypted with an age identity file or passphrase file.`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  runVerify,
+	}
+
+	cmd.Flags().String("identity-file", "", "age identity file to decrypt encrypted archives with")
+	cmd.Flags().String("passphrase-file", "", "File holding the passphrase to decrypt encrypted archives with")
+
+	return cmd
+}
+
+// runVerify executes the logic for the 'verify' command, exiting non-zero when an archive fails verification.
+func runVerify(cmd *cobra.Command, args []string) {
+	ctx := cmd.Root().Context()
+	ctx = baseoption.AddRootInputOptionsToContext(ctx, cmd)
+	opts := model.CLIOptions(ctx)
+
+	ctx = log.InitLogger(ctx, cmd, opts.VerbosityWithCaller, opts.OutputFormat)
+
+	identityFile, _ := cmd.Flags().GetString("identity-file")
+	passphraseFile, _ := cmd.Flags().GetString("passphrase-file")
+
+	encryption, err := archive.NewEncryption(gpsconfig.EncryptionConfig{IdentityFile: identityFile, PassphraseFile: passphraseFile})
+	if err != nil {
+		model.HandleError(ctx, err)
+
+		return
+	}
+
+	err = verifyArchives(ctx, args, encryption)
+	model.HandleError(ctx, err)
+}
+
+// verifyArchives verifies the archives at paths, the archives of every repository for a directory,
+// decrypting encrypted archives with the encryption. Every archive is verified, failures are logged
+// and counted in the returned error.
+func verifyArchives(ctx context.Context, paths []string, encryption *archive.Encryption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering verifyArchives")
+
+	archivePaths, err := resolveArchives(paths)
+	if err != nil {
+		return err
+	}
+
+	reader := archive.NewReader().WithEncryption(encryption)
+	failed := 0
+
+	for _, archivePath := range archivePaths {
+		result, err := reader.Verify(ctx, archivePath)
+		if err != nil {
+			logger.Error().Err(err).Str("archive", archivePath).Msg("Archive failed verification")
+
+			failed++
+
+			continue
+		}
+
+		if !result.HasManifest {
+			logger.Warn().Str("archive", archivePath).Msg("Archive has no manifest, its checksum is not verified")
+		}
+
+		logger.Info().Str("archive", archivePath).Int("refs", result.Refs).Int("objects", result.Objects).Msg("Archive verified")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrVerifyArchives, failed, len(archivePaths))
+	}
+
+	logger.Info().Int("archives", len(archivePaths)).Msg("All archives verified")
+
+	return nil
+}
+
+// resolveArchives returns the archive paths, replacing directories by the archives in them.
+func resolveArchives(paths []string) ([]string, error) {
+	archivePaths := []string{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrVerifyArchives, err)
+		}
+
+		if !info.IsDir() {
+			archivePaths = append(archivePaths, path)
+
+			continue
+		}
+
+		archives, err := archive.ListArchives(path, "")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrVerifyArchives, err)
+		}
+
+		for _, found := range archives {
+			archivePaths = append(archivePaths, found.Path)
+		}
+	}
+
+	if len(archivePaths) == 0 {
+		return nil, fmt.Errorf("%w: no archives found", ErrVerifyArchives)
+	}
+
+	return archivePaths, nil
+}
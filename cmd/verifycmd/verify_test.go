@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package verifycmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/mirror/archive"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveArchives(t *testing.T) {
+	dir := t.TempDir()
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	older := filepath.Join(dir, "tools"+archive.FormatArchiveTimestamp(createdAt)+".tar.gz")
+	newer := filepath.Join(dir, "tools"+archive.FormatArchiveTimestamp(createdAt.Add(time.Hour))+".bundle")
+	other := filepath.Join(dir, "notes.txt")
+
+	for _, path := range []string{older, newer, other, archive.ManifestPath(older)} {
+		require.NoError(t, os.WriteFile(path, []byte("archive"), 0o600))
+	}
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "directory lists archives newest first",
+			paths: []string{dir},
+			want:  []string{newer, older},
+		},
+		{
+			name:  "archive file",
+			paths: []string{older},
+			want:  []string{older},
+		},
+		{
+			name:    "missing path",
+			paths:   []string{filepath.Join(dir, "missing")},
+			wantErr: true,
+		},
+		{
+			name:    "directory without archives",
+			paths:   []string{t.TempDir()},
+			wantErr: true,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			archives, err := resolveArchives(tabletest.paths)
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, ErrVerifyArchives)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.want, archives)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package configuration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrResolveToken = errors.New("failed to resolve token")
+
+// ResolveCredentials returns the auth configuration with the token read from its credential source,
+// token_file, token_command or token_env, and the source cleared, so resolving again is a no-op.
+// Sources are read when clients are created, never at load time, so a rotated secret is picked up
+// by the next run without changing the configuration.
+// The token is never logged, errors name the source but not its content.
+func ResolveCredentials(ctx context.Context, authCfg config.AuthConfig) (config.AuthConfig, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering ResolveCredentials")
+
+	var (
+		token string
+		err   error
+	)
+
+	switch {
+	case authCfg.TokenFile != "":
+		logger.Debug().Str("tokenFile", authCfg.TokenFile).Msg("Reading token from file")
+		token, err = tokenFromFile(authCfg.TokenFile)
+	case authCfg.TokenCommand != "":
+		logger.Debug().Msg("Reading token from command")
+		token, err = tokenFromCommand(ctx, authCfg.TokenCommand)
+	case authCfg.TokenEnv != "":
+		logger.Debug().Str("tokenEnv", authCfg.TokenEnv).Msg("Reading token from environment")
+		token, err = tokenFromEnv(authCfg.TokenEnv)
+	default:
+		return authCfg, nil
+	}
+
+	if err != nil {
+		return authCfg, err
+	}
+
+	authCfg.Token = token
+	authCfg.TokenFile = ""
+	authCfg.TokenCommand = ""
+	authCfg.TokenEnv = ""
+
+	return authCfg, nil
+}
+
+func tokenFromFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: token_file %s: %w", ErrResolveToken, path, err)
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("%w: token_file %s is empty", ErrResolveToken, path)
+	}
+
+	return token, nil
+}
+
+// tokenFromCommand runs the command with the shell and takes the first line of its output,
+// as password managers such as pass print the secret there. Its stderr is passed through,
+// so a command can prompt for a passphrase.
+func tokenFromCommand(ctx context.Context, command string) (string, error) {
+	var stdout bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: token_command: %w", ErrResolveToken, err)
+	}
+
+	firstLine, _, _ := strings.Cut(stdout.String(), "\n")
+
+	token := strings.TrimSpace(firstLine)
+	if token == "" {
+		return "", fmt.Errorf("%w: token_command printed no token", ErrResolveToken)
+	}
+
+	return token, nil
+}
+
+func tokenFromEnv(name string) (string, error) {
+	token := strings.TrimSpace(os.Getenv(name))
+	if token == "" {
+		return "", fmt.Errorf("%w: token_env %s is not set", ErrResolveToken, name)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package configuration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCredentials(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+
+	emptyFile := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("\n"), 0o600))
+
+	t.Setenv("TEST_GPS_TOKEN", "env-token")
+
+	tests := []struct {
+		name    string
+		authCfg config.AuthConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "literal token", authCfg: config.AuthConfig{Token: "literal-token"}, want: "literal-token"},
+		{name: "no token", authCfg: config.AuthConfig{}},
+		{name: "token file", authCfg: config.AuthConfig{TokenFile: tokenFile}, want: "file-token"},
+		{name: "missing token file", authCfg: config.AuthConfig{TokenFile: tokenFile + ".missing"}, wantErr: true},
+		{name: "empty token file", authCfg: config.AuthConfig{TokenFile: emptyFile}, wantErr: true},
+		{name: "token command first line", authCfg: config.AuthConfig{TokenCommand: "printf 'command-token\\nlogin: me\\n'"}, want: "command-token"},
+		{name: "failing token command", authCfg: config.AuthConfig{TokenCommand: "exit 1"}, wantErr: true},
+		{name: "silent token command", authCfg: config.AuthConfig{TokenCommand: "true"}, wantErr: true},
+		{name: "token env", authCfg: config.AuthConfig{TokenEnv: "TEST_GPS_TOKEN"}, want: "env-token"},
+		{name: "unset token env", authCfg: config.AuthConfig{TokenEnv: "TEST_GPS_TOKEN_UNSET"}, wantErr: true},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			got, err := ResolveCredentials(context.Background(), tabletest.authCfg)
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, ErrResolveToken)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.want, got.Token)
+			require.Empty(t, got.TokenFile)
+			require.Empty(t, got.TokenCommand)
+			require.Empty(t, got.TokenEnv)
+		})
+	}
+}
+
+func TestResolveCredentialsRereadsSource(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	authCfg := config.AuthConfig{TokenFile: tokenFile}
+
+	// a rotated secret is picked up by the next resolve, without changing the configuration
+	for _, token := range []string{"first-token", "rotated-token"} {
+		require.NoError(t, os.WriteFile(tokenFile, []byte(token), 0o600))
+
+		got, err := ResolveCredentials(context.Background(), authCfg)
+		require.NoError(t, err)
+		require.Equal(t, token, got.Token)
+	}
+}
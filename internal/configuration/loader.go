@@ -52,12 +52,27 @@ func processEnvKey(str string, prefix string) string {
 		"provider_type",
 		"owner_type",
 		"active_from_limit",
+		"cache_dir",
+		"clone_mode",
+		"compression_level",
 		"include_forks",
+		"fail_fast",
 		"use_git_binary",
+		"url_template",
 		"cert_dir_path",
+		"github_app_id",
+		"github_app_installation_id",
+		"github_app_private_key_path",
 		"http_scheme",
 		"proxy_url",
+		"token_command",
+		"token_env",
+		"token_file",
+		"known_hosts_path",
 		"ssh_command",
+		"ssh_passphrase_env",
+		"ssh_passphrase_file",
+		"ssh_private_key_path",
 		"ssh_url_rewrite_from",
 		"ssh_url_rewrite_to",
 		"alphanumhyph_name",
@@ -65,6 +80,20 @@ func processEnvKey(str string, prefix string) string {
 		"force_push",
 		"github_uploadurl",
 		"ignore_invalid_name",
+		"keep_daily",
+		"keep_last",
+		"keep_monthly",
+		"keep_weekly",
+		"max_age",
+		"rewrite_submodule_urls",
+		"require_signed",
+		"signing_keys_path",
+		"allowed_signers_path",
+		"verify_history",
+		// after ssh_passphrase_file, which ends in passphrase_file
+		"identity_file",
+		"passphrase_file",
+		"recipients_file",
 	}
 
 	lowered := strings.ToLower(strings.TrimPrefix(str, prefix))
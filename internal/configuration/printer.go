@@ -50,6 +50,22 @@ func printSyncConfig(name string, syncCfg model.SyncConfig, writer io.Writer, le
 	fmt.Fprintf(writer, "%sOwner Type: %s\n", indent, syncCfg.OwnerType)
 
 	// Print optional fields only if they have non-default values
+	if len(syncCfg.URLs) > 0 {
+		fmt.Fprintf(writer, "%sURLs:\n", indent)
+
+		for _, url := range syncCfg.URLs {
+			fmt.Fprintf(writer, "%s  %s\n", indent, url)
+		}
+	}
+
+	if syncCfg.URLTemplate != "" {
+		fmt.Fprintf(writer, "%sURL Template: %s\n", indent, syncCfg.URLTemplate)
+	}
+
+	if syncCfg.Path != "" {
+		fmt.Fprintf(writer, "%sPath: %s\n", indent, syncCfg.Path)
+	}
+
 	if syncCfg.IncludeForks {
 		fmt.Fprintf(writer, "%sInclude Forks: %t\n", indent, syncCfg.IncludeForks)
 	}
@@ -62,11 +78,35 @@ func printSyncConfig(name string, syncCfg model.SyncConfig, writer io.Writer, le
 		fmt.Fprintf(writer, "%sActive From Limit: %s\n", indent, syncCfg.ActiveFromLimit)
 	}
 
+	if syncCfg.CacheDir != "" {
+		fmt.Fprintf(writer, "%sCache Dir: %s\n", indent, syncCfg.CacheDir)
+	}
+
+	if syncCfg.CloneMode != "" {
+		fmt.Fprintf(writer, "%sClone Mode: %s\n", indent, syncCfg.CloneMode)
+	}
+
+	if syncCfg.Submodules {
+		fmt.Fprintf(writer, "%sSubmodules: %t\n", indent, syncCfg.Submodules)
+	}
+
+	if syncCfg.Concurrency > 1 {
+		fmt.Fprintf(writer, "%sConcurrency: %d\n", indent, syncCfg.Concurrency)
+	}
+
+	if !syncCfg.IsFailFast() {
+		fmt.Fprintf(writer, "%sFail Fast: %t\n", indent, syncCfg.IsFailFast())
+	}
+
 	// Print Auth Configuration
 	if !isEmptyAuthConfig(syncCfg.Auth) {
 		printAuthConfig(syncCfg.Auth, writer, level+1)
 	}
 
+	if syncCfg.Encryption.IsEnabled() {
+		printEncryptionConfig(syncCfg.Encryption, writer, level+1)
+	}
+
 	// Print Repositories Configuration
 	if !isEmptyRepositoriesOption(syncCfg.Repositories) {
 		printRepositoriesOption(syncCfg.Repositories, writer, level+1)
@@ -97,10 +137,33 @@ func printAuthConfig(authCfg model.AuthConfig, writer io.Writer, level int) {
 		fmt.Fprintf(writer, "%sHTTP Scheme: %s\n", indent, authCfg.HTTPScheme)
 	}
 
+	if authCfg.Username != "" {
+		fmt.Fprintf(writer, "%sUsername: %s\n", indent, authCfg.Username)
+	}
+
 	if authCfg.Token != "" {
 		fmt.Fprintf(writer, "%sToken: <*****>\n", indent)
 	}
 
+	if authCfg.TokenFile != "" {
+		fmt.Fprintf(writer, "%sToken File: %s\n", indent, authCfg.TokenFile)
+	}
+
+	// The command line may hold a secret itself, so it is masked like a token
+	if authCfg.TokenCommand != "" {
+		fmt.Fprintf(writer, "%sToken Command: <*****>\n", indent)
+	}
+
+	if authCfg.TokenEnv != "" {
+		fmt.Fprintf(writer, "%sToken Env: %s\n", indent, authCfg.TokenEnv)
+	}
+
+	if authCfg.IsGitHubApp() {
+		fmt.Fprintf(writer, "%sGitHub App ID: %d\n", indent, authCfg.GitHubAppID)
+		fmt.Fprintf(writer, "%sGitHub App Installation ID: %d\n", indent, authCfg.GitHubAppInstallationID)
+		fmt.Fprintf(writer, "%sGitHub App Private Key Path: %s\n", indent, authCfg.GitHubAppPrivateKeyPath)
+	}
+
 	if authCfg.ProxyURL != "" {
 		fmt.Fprintf(writer, "%sProxy URL: %s\n", indent, authCfg.ProxyURL)
 	}
@@ -110,13 +173,30 @@ func printAuthConfig(authCfg model.AuthConfig, writer io.Writer, level int) {
 	}
 
 	// Print SSH configuration if any SSH-related fields are set
-	if authCfg.SSHCommand != "" || authCfg.SSHURLRewriteFrom != "" || authCfg.SSHURLRewriteTo != "" {
+	if authCfg.SSHCommand != "" || authCfg.SSHURLRewriteFrom != "" || authCfg.SSHURLRewriteTo != "" ||
+		authCfg.SSHPrivateKeyPath != "" || authCfg.KnownHostsPath != "" {
 		fmt.Fprintf(writer, "\n%sSSH Configuration:\n", indent)
 
 		if authCfg.SSHCommand != "" {
 			fmt.Fprintf(writer, "%sCommand: %s\n", indent, authCfg.SSHCommand)
 		}
 
+		if authCfg.SSHPrivateKeyPath != "" {
+			fmt.Fprintf(writer, "%sPrivate Key Path: %s\n", indent, authCfg.SSHPrivateKeyPath)
+		}
+
+		if authCfg.SSHPassphraseEnv != "" {
+			fmt.Fprintf(writer, "%sPassphrase Env: %s\n", indent, authCfg.SSHPassphraseEnv)
+		}
+
+		if authCfg.SSHPassphraseFile != "" {
+			fmt.Fprintf(writer, "%sPassphrase File: %s\n", indent, authCfg.SSHPassphraseFile)
+		}
+
+		if authCfg.KnownHostsPath != "" {
+			fmt.Fprintf(writer, "%sKnown Hosts Path: %s\n", indent, authCfg.KnownHostsPath)
+		}
+
 		if authCfg.SSHURLRewriteFrom != "" {
 			fmt.Fprintf(writer, "%sURL Rewrite From: %s\n", indent, authCfg.SSHURLRewriteFrom)
 		}
@@ -154,6 +234,14 @@ func printMirrorConfig(name string, mirrorCfg model.MirrorConfig, writer io.Writ
 		fmt.Fprintf(writer, "%sPath: %s\n", indent, mirrorCfg.Path)
 	}
 
+	if mirrorCfg.URLTemplate != "" {
+		fmt.Fprintf(writer, "%sURL Template: %s\n", indent, mirrorCfg.URLTemplate)
+	}
+
+	if mirrorCfg.S3 != (model.S3Config{}) {
+		printS3Config(mirrorCfg.S3, writer, level+1)
+	}
+
 	// Print Mirror Settings if they're not empty
 	if !isEmptyMirrorSettings(mirrorCfg.Settings) {
 		printMirrorSettings(mirrorCfg.Settings, writer, level+1)
@@ -163,6 +251,56 @@ func printMirrorConfig(name string, mirrorCfg model.MirrorConfig, writer io.Writ
 	if !isEmptyAuthConfig(mirrorCfg.Auth) {
 		printAuthConfig(mirrorCfg.Auth, writer, level+1)
 	}
+
+	if mirrorCfg.Encryption.IsEnabled() {
+		printEncryptionConfig(mirrorCfg.Encryption, writer, level+1)
+	}
+}
+
+// printS3Config writes the object storage details of an s3 mirror with proper indentation.
+func printS3Config(s3Cfg model.S3Config, writer io.Writer, level int) {
+	indent := strings.Repeat(" ", level*indentSize)
+	fmt.Fprintf(writer, "\n%sS3:\n", indent)
+	fmt.Fprintf(writer, "%sBucket: %s\n", indent, s3Cfg.Bucket)
+
+	if s3Cfg.Endpoint != "" {
+		fmt.Fprintf(writer, "%sEndpoint: %s\n", indent, s3Cfg.Endpoint)
+	}
+
+	if s3Cfg.Prefix != "" {
+		fmt.Fprintf(writer, "%sPrefix: %s\n", indent, s3Cfg.Prefix)
+	}
+
+	if s3Cfg.Region != "" {
+		fmt.Fprintf(writer, "%sRegion: %s\n", indent, s3Cfg.Region)
+	}
+}
+
+// printEncryptionConfig writes archive encryption details with proper indentation.
+// Recipients are public keys, the identities and passphrase are only named by their files.
+func printEncryptionConfig(encryptionCfg model.EncryptionConfig, writer io.Writer, level int) {
+	indent := strings.Repeat(" ", level*indentSize)
+	fmt.Fprintf(writer, "\n%sEncryption:\n", indent)
+
+	if len(encryptionCfg.Recipients) > 0 {
+		fmt.Fprintf(writer, "%sRecipients:\n", indent)
+
+		for _, recipient := range encryptionCfg.Recipients {
+			fmt.Fprintf(writer, "%s  %s\n", indent, recipient)
+		}
+	}
+
+	if encryptionCfg.RecipientsFile != "" {
+		fmt.Fprintf(writer, "%sRecipients File: %s\n", indent, encryptionCfg.RecipientsFile)
+	}
+
+	if encryptionCfg.IdentityFile != "" {
+		fmt.Fprintf(writer, "%sIdentity File: %s\n", indent, encryptionCfg.IdentityFile)
+	}
+
+	if encryptionCfg.PassphraseFile != "" {
+		fmt.Fprintf(writer, "%sPassphrase File: %s\n", indent, encryptionCfg.PassphraseFile)
+	}
 }
 
 // printMirrorSettings writes mirror-specific settings with proper indentation.
@@ -187,6 +325,14 @@ func printMirrorSettings(settings model.MirrorSettings, writer io.Writer, level
 		fmt.Fprintf(writer, "%sForce Push: %t\n", indent, settings.ForcePush)
 	}
 
+	if settings.Format != "" {
+		fmt.Fprintf(writer, "%sFormat: %s\n", indent, settings.Format)
+	}
+
+	if settings.CompressionLevel != 0 {
+		fmt.Fprintf(writer, "%sCompression Level: %d\n", indent, settings.CompressionLevel)
+	}
+
 	if settings.GitHubUploadURL != "" {
 		fmt.Fprintf(writer, "%sGitHub Upload URL: %s\n", indent, settings.GitHubUploadURL)
 	}
@@ -195,6 +341,66 @@ func printMirrorSettings(settings model.MirrorSettings, writer io.Writer, level
 		fmt.Fprintf(writer, "%sIgnore Invalid Name: %t\n", indent, settings.IgnoreInvalidName)
 	}
 
+	if settings.KeepLast > 0 {
+		fmt.Fprintf(writer, "%sKeep Last: %d\n", indent, settings.KeepLast)
+	}
+
+	if settings.KeepDaily > 0 {
+		fmt.Fprintf(writer, "%sKeep Daily: %d\n", indent, settings.KeepDaily)
+	}
+
+	if settings.KeepWeekly > 0 {
+		fmt.Fprintf(writer, "%sKeep Weekly: %d\n", indent, settings.KeepWeekly)
+	}
+
+	if settings.KeepMonthly > 0 {
+		fmt.Fprintf(writer, "%sKeep Monthly: %d\n", indent, settings.KeepMonthly)
+	}
+
+	if settings.LFS {
+		fmt.Fprintf(writer, "%sLFS: %t\n", indent, settings.LFS)
+	}
+
+	if settings.MaxAge != "" {
+		fmt.Fprintf(writer, "%sMax Age: %s\n", indent, settings.MaxAge)
+	}
+
+	if settings.Prune {
+		fmt.Fprintf(writer, "%sPrune: %t\n", indent, settings.Prune)
+	}
+
+	if len(settings.RefSpecs) > 0 {
+		fmt.Fprintf(writer, "%sRefSpecs:\n", indent)
+
+		for _, refSpec := range settings.RefSpecs {
+			fmt.Fprintf(writer, "%s  %s\n", indent, refSpec)
+		}
+	}
+
+	if settings.VerifyHistory {
+		fmt.Fprintf(writer, "%sVerify History: %t\n", indent, settings.VerifyHistory)
+	}
+
+	if len(settings.RequireSigned) > 0 {
+		fmt.Fprintf(writer, "%sRequire Signed: %s\n", indent, strings.Join(settings.RequireSigned, ", "))
+	}
+
+	if settings.SigningKeysPath != "" {
+		fmt.Fprintf(writer, "%sSigning Keys Path: %s\n", indent, settings.SigningKeysPath)
+	}
+
+	if settings.AllowedSignersPath != "" {
+		fmt.Fprintf(writer, "%sAllowed Signers Path: %s\n", indent, settings.AllowedSignersPath)
+	}
+
+	if settings.Quarantine {
+		fmt.Fprintf(writer, "%sQuarantine: %t\n", indent, settings.Quarantine)
+	}
+
+	if settings.RewriteSubmoduleURLs {
+		fmt.Fprintf(writer, "%sRewrite Submodule URLs: %t\n", indent, settings.RewriteSubmoduleURLs)
+	}
+
 	if settings.Visibility != "" {
 		fmt.Fprintf(writer, "%sVisibility: %s\n", indent, settings.Visibility)
 	}
@@ -227,9 +433,16 @@ func isEmptyAuthConfig(authCfg model.AuthConfig) bool {
 	return authCfg.Protocol == "" &&
 		authCfg.HTTPScheme == "" &&
 		authCfg.Token == "" &&
+		authCfg.TokenFile == "" &&
+		authCfg.TokenCommand == "" &&
+		authCfg.TokenEnv == "" &&
+		!authCfg.IsGitHubApp() &&
+		authCfg.Username == "" &&
 		authCfg.ProxyURL == "" &&
 		authCfg.CertDirPath == "" &&
 		authCfg.SSHCommand == "" &&
+		authCfg.SSHPrivateKeyPath == "" &&
+		authCfg.KnownHostsPath == "" &&
 		authCfg.SSHURLRewriteFrom == "" &&
 		authCfg.SSHURLRewriteTo == ""
 }
@@ -243,7 +456,19 @@ func isEmptyMirrorSettings(settings model.MirrorSettings) bool {
 		settings.DescriptionPrefix == "" &&
 		!settings.Disabled &&
 		!settings.ForcePush &&
+		settings.Format == "" &&
+		settings.CompressionLevel == 0 &&
 		settings.GitHubUploadURL == "" &&
 		!settings.IgnoreInvalidName &&
+		!settings.HasRetention() &&
+		!settings.LFS &&
+		!settings.Prune &&
+		len(settings.RefSpecs) == 0 &&
+		!settings.RewriteSubmoduleURLs &&
+		!settings.VerifyHistory &&
+		len(settings.RequireSigned) == 0 &&
+		settings.SigningKeysPath == "" &&
+		settings.AllowedSignersPath == "" &&
+		!settings.Quarantine &&
 		settings.Visibility == ""
 }
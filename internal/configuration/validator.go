@@ -11,6 +11,8 @@ import (
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/mirror/gitbinary"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/azuredevops"
+	"itiquette/git-provider-sync/internal/provider/plaingit"
 	"net"
 	"net/url"
 	"os"
@@ -19,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"filippo.io/age"
 	"golang.org/x/crypto/ssh/agent"
 )
 
@@ -32,22 +35,26 @@ const (
 
 var (
 	// Provider Type Errors remain the same.
-	ErrUnsupportedProvider          = errors.New("unsupported provider")
-	ErrUnsupportedArchiveProvider   = errors.New("source provider: does not support reading from archive")
-	ErrUnsupportedDirectoryProvider = errors.New("source provider: does not support reading from directory")
-	ErrInvalidURL                   = errors.New("invalid URL")
+	ErrUnsupportedProvider = errors.New("unsupported provider")
+	ErrInvalidURL          = errors.New("invalid URL")
 
 	// Configuration Errors.
-	ErrNoSourceDomain  = errors.New("source provider: no domain configured")
-	ErrNoTargetDomain  = errors.New("target provider: no domain configured")
-	ErrNoMirrors       = errors.New("no mirror configurations provided")
-	ErrNoHTTPToken     = errors.New("no http token set")
-	ErrInvalidDuration = errors.New("invalid duration format")
+	ErrNoSourceDomain     = errors.New("source provider: no domain configured")
+	ErrNoTargetDomain     = errors.New("target provider: no domain configured")
+	ErrNoMirrors          = errors.New("no mirror configurations provided")
+	ErrNoHTTPToken        = errors.New("no http token set")
+	ErrInvalidDuration    = errors.New("invalid duration format")
+	ErrInvalidConcurrency = errors.New("invalid concurrency")
+	ErrNoGitURLs          = errors.New("git provider: no urls, or url_template with repositories.include, configured")
+	ErrInvalidURLTemplate = errors.New("git provider: invalid url_template")
+	ErrNoSourcePath       = errors.New("source provider: no path configured")
 
 	// Authentication Errors.
 	ErrTokenAuth        = errors.New("target provider currently only supports token auth")
 	ErrNoGitBinaryFound = errors.New("failed to find git binary")
 	ErrInvalidToken     = errors.New("invalid token format")
+	ErrSSHKeyAuth       = errors.New("invalid ssh key authentication")
+	ErrGitHubAppAuth    = errors.New("invalid GitHub App authentication")
 
 	// Protocol Errors remain the same.
 	ErrUnsupportedScheme       = errors.New("unsupported scheme")
@@ -62,18 +69,31 @@ var (
 
 	// Repository Errors.
 	ErrInvalidRepoName    = errors.New("invalid repository name")
+	ErrDuplicateRepoName  = errors.New("duplicate repository name")
 	ErrInvalidDescription = errors.New("invalid repository description")
 
 	// Path Errors.
 	ErrInvalidPath = errors.New("invalid file path")
+
+	ErrPruneArchive = errors.New("prune is not supported by archive mirrors, each archive is a full snapshot")
+
+	ErrInvalidCloneMode = errors.New("invalid clone_mode")
+	ErrSubmodules       = errors.New("invalid submodules configuration")
+	ErrVerifyConfig     = errors.New("invalid verification configuration")
+	ErrArchiveFormat    = errors.New("invalid archive format")
+	ErrRetention        = errors.New("invalid archive retention")
+	ErrEncryption       = errors.New("invalid archive encryption")
+	ErrObjectStore      = errors.New("invalid s3 configuration")
 )
 
 var (
-	ValidSourceGitProviders = []string{"github", "gitlab", "gitea"}
-	ValidMirrorTargets      = []string{"github", "gitlab", "gitea", "archive", "directory"}
+	ValidSourceGitProviders = []string{"github", "gitlab", "gitea", "forgejo", "gogs", "azuredevops", "bitbucket", "bitbucketserver", "git", "archive", "directory"}
+	ValidMirrorTargets      = []string{"github", "gitlab", "gitea", "forgejo", "gogs", "azuredevops", "bitbucket", "bitbucketserver", "git", "archive", "directory", "s3"}
 	ValidProtocolTypes      = []string{"", config.TLS, config.SSH}
 	ValidSchemeTypes        = []string{"", config.HTTPS, config.HTTP}
 	ValidOwnerTypes         = []string{"", config.USER, config.GROUP}
+	ValidCloneModes         = []string{"", config.CloneModeFull, config.CloneModeShallow, config.CloneModeBlobless, config.CloneModeTreeless}
+	ValidArchiveFormats     = []string{"", config.ArchiveFormatTarGz, config.ArchiveFormatTarZst, config.ArchiveFormatTarXz, config.ArchiveFormatBundle}
 )
 
 // ValidateConfiguration validates the entire application configuration.
@@ -124,15 +144,34 @@ func validateSyncConfig(_ string, syncCfg config.SyncConfig) error {
 		return err
 	}
 
-	if err := validateDomainName(syncCfg.GetDomain()); err != nil {
-		return fmt.Errorf("%w: %w", ErrNoSourceDomain, err)
+	switch syncCfg.ProviderType {
+	case config.GIT:
+		if err := validateGitSource(syncCfg); err != nil {
+			return err
+		}
+	case config.ARCHIVE, config.DIRECTORY:
+		if err := validateLocalSource(syncCfg); err != nil {
+			return err
+		}
+	default:
+		if err := validateDomainName(syncCfg.GetDomain()); err != nil {
+			return fmt.Errorf("%w: %w", ErrNoSourceDomain, err)
+		}
+
+		if err := validateOwner(syncCfg.Owner, syncCfg.OwnerType); err != nil {
+			return err
+		}
+
+		if err := validateProviderOwner(syncCfg.ProviderType, syncCfg.Owner); err != nil {
+			return err
+		}
 	}
 
-	if err := validateOwner(syncCfg.Owner, syncCfg.OwnerType); err != nil {
+	if err := validateAuth(syncCfg.Auth); err != nil {
 		return err
 	}
 
-	if err := validateAuth(syncCfg.Auth); err != nil {
+	if err := validateGitHubApp(syncCfg.ProviderType, syncCfg.Auth); err != nil {
 		return err
 	}
 
@@ -149,6 +188,26 @@ func validateSyncConfig(_ string, syncCfg config.SyncConfig) error {
 		}
 	}
 
+	if syncCfg.CacheDir != "" && !filepath.IsAbs(syncCfg.CacheDir) {
+		return fmt.Errorf("%w: cache_dir must be absolute: %s", ErrInvalidPath, syncCfg.CacheDir)
+	}
+
+	if syncCfg.Concurrency < 0 {
+		return fmt.Errorf("%w: must not be negative, got %d", ErrInvalidConcurrency, syncCfg.Concurrency)
+	}
+
+	if err := validateCloneMode(syncCfg); err != nil {
+		return err
+	}
+
+	if err := validateSubmodules(syncCfg); err != nil {
+		return err
+	}
+
+	if err := validateEncryption(syncCfg.ProviderType, syncCfg.Encryption, false); err != nil {
+		return err
+	}
+
 	// Validate mirrors if present
 	if len(syncCfg.Mirrors) > 0 {
 		for _, mirror := range syncCfg.Mirrors {
@@ -161,13 +220,167 @@ func validateSyncConfig(_ string, syncCfg config.SyncConfig) error {
 	return nil
 }
 
+// validateCloneMode validates that the mirrors of a source can be written from its clone.
+// A shallow clone lacks the history, so it only makes an archive snapshot.
+// A blobless or treeless clone fetches the objects it lacks from the source while pushing,
+// which only the git binary does, so the source and its mirrors must use it.
+func validateCloneMode(syncCfg config.SyncConfig) error {
+	if !slices.Contains(ValidCloneModes, syncCfg.CloneMode) {
+		return fmt.Errorf("%w: %s, valid modes are %s", ErrInvalidCloneMode, syncCfg.CloneMode, strings.Join(ValidCloneModes[1:], ", "))
+	}
+
+	if syncCfg.CloneMode == "" || syncCfg.CloneMode == config.CloneModeFull {
+		return nil
+	}
+
+	if syncCfg.ProviderType == config.ARCHIVE || syncCfg.ProviderType == config.DIRECTORY {
+		return fmt.Errorf("%w: %s is not supported by %s sources", ErrInvalidCloneMode, syncCfg.CloneMode, syncCfg.ProviderType)
+	}
+
+	if syncCfg.CacheDir != "" {
+		return fmt.Errorf("%w: %s is not supported with cache_dir, the cache keeps full clones", ErrInvalidCloneMode, syncCfg.CloneMode)
+	}
+
+	if syncCfg.IsPartialClone() && !syncCfg.UseGitBinary {
+		return fmt.Errorf("%w: %s requires use_git_binary", ErrInvalidCloneMode, syncCfg.CloneMode)
+	}
+
+	for name, mirrorCfg := range syncCfg.Mirrors {
+		if syncCfg.CloneMode == config.CloneModeShallow {
+			if !mirrorCfg.WritesArchives() {
+				return fmt.Errorf("%w: shallow clones lack the history a %s mirror needs, only archive and s3 mirrors are supported: mirror %s",
+					ErrInvalidCloneMode, mirrorCfg.ProviderType, name)
+			}
+
+			if mirrorCfg.Settings.Format == config.ArchiveFormatBundle {
+				return fmt.Errorf("%w: a git bundle of a shallow clone is not cloneable, use a tar format: mirror %s", ErrInvalidCloneMode, name)
+			}
+
+			continue
+		}
+
+		if mirrorCfg.WritesArchives() || mirrorCfg.ProviderType == config.DIRECTORY {
+			return fmt.Errorf("%w: %s clones lack objects a %s mirror stores: mirror %s", ErrInvalidCloneMode, syncCfg.CloneMode, mirrorCfg.ProviderType, name)
+		}
+
+		if !mirrorCfg.UseGitBinary {
+			return fmt.Errorf("%w: %s requires use_git_binary on mirror %s", ErrInvalidCloneMode, syncCfg.CloneMode, name)
+		}
+
+		if mirrorCfg.Settings.LFS {
+			return fmt.Errorf("%w: %s clones lack the lfs pointers, lfs is not supported: mirror %s", ErrInvalidCloneMode, syncCfg.CloneMode, name)
+		}
+	}
+
+	return nil
+}
+
+// validateSubmodules validates that submodules are followed from a provider or git source,
+// and that submodule URLs are only rewritten in directory mirrors of such a source.
+func validateSubmodules(syncCfg config.SyncConfig) error {
+	if syncCfg.Submodules && (syncCfg.ProviderType == config.ARCHIVE || syncCfg.ProviderType == config.DIRECTORY) {
+		return fmt.Errorf("%w: submodules are not supported by %s sources", ErrSubmodules, syncCfg.ProviderType)
+	}
+
+	for name, mirrorCfg := range syncCfg.Mirrors {
+		if !mirrorCfg.Settings.RewriteSubmoduleURLs {
+			continue
+		}
+
+		if mirrorCfg.ProviderType != config.DIRECTORY {
+			return fmt.Errorf("%w: rewrite_submodule_urls is only supported by directory mirrors: mirror %s", ErrSubmodules, name)
+		}
+
+		if !syncCfg.Submodules {
+			return fmt.Errorf("%w: rewrite_submodule_urls requires submodules on the source: mirror %s", ErrSubmodules, name)
+		}
+	}
+
+	return nil
+}
+
+// validateGitSource validates that a plain git source knows its repositories,
+// either as clone URLs or as a URL template expanded for each included repository name,
+// and that no two of them derive the same repository name.
+func validateGitSource(syncCfg config.SyncConfig) error {
+	urls := syncCfg.URLs
+
+	if len(urls) == 0 {
+		if syncCfg.URLTemplate == "" || len(syncCfg.Repositories.Include) == 0 {
+			return ErrNoGitURLs
+		}
+
+		if !strings.Contains(syncCfg.URLTemplate, plaingit.NamePlaceholder) {
+			return fmt.Errorf("%w: must contain %s, got %q", ErrInvalidURLTemplate, plaingit.NamePlaceholder, syncCfg.URLTemplate)
+		}
+
+		for _, name := range syncCfg.Repositories.Include {
+			urls = append(urls, plaingit.ExpandURLTemplate(syncCfg.URLTemplate, syncCfg.Owner, name))
+		}
+	}
+
+	// Repositories are named after the last element of their URL, names shared by URLs on other hosts
+	// or owners would sync to the same mirror repository
+	seen := make(map[string]string, len(urls))
+
+	for _, url := range urls {
+		name := plaingit.RepositoryName(url)
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("%w: %s and %s are both named %q", ErrDuplicateRepoName, other, url, name)
+		}
+
+		seen[name] = url
+	}
+
+	return nil
+}
+
+// validateLocalSource validates that an archive or directory source, restoring from a backup,
+// has an absolute path to an existing directory.
+func validateLocalSource(syncCfg config.SyncConfig) error {
+	if syncCfg.Path == "" {
+		return ErrNoSourcePath
+	}
+
+	if err := validatePathExists(syncCfg.Path); err != nil {
+		return fmt.Errorf("invalid source path: %w", err)
+	}
+
+	return nil
+}
+
+// validateObjectStore validates the bucket of an s3 mirror, and its endpoint when it is not AWS.
+func validateObjectStore(s3Cfg config.S3Config) error {
+	if s3Cfg.Bucket == "" {
+		return fmt.Errorf("%w: no bucket configured", ErrObjectStore)
+	}
+
+	if s3Cfg.Endpoint == "" {
+		return nil
+	}
+
+	if err := validateURL(s3Cfg.Endpoint); err != nil {
+		return fmt.Errorf("%w: endpoint: %w", ErrObjectStore, err)
+	}
+
+	return nil
+}
+
 // validateMirrorConfig validates a mirror configuration.
 func validateMirrorConfig(mirrorCfg config.MirrorConfig) error {
 	if err := validateProviderType(mirrorCfg.ProviderType, ValidMirrorTargets); err != nil {
 		return err
 	}
 
-	if mirrorCfg.ProviderType != "archive" && mirrorCfg.ProviderType != "directory" {
+	if mirrorCfg.ProviderType == config.GIT {
+		if !strings.Contains(mirrorCfg.URLTemplate, plaingit.NamePlaceholder) {
+			return fmt.Errorf("%w: must contain %s, got %q", ErrInvalidURLTemplate, plaingit.NamePlaceholder, mirrorCfg.URLTemplate)
+		}
+	} else if mirrorCfg.ProviderType == config.S3 {
+		if err := validateObjectStore(mirrorCfg.S3); err != nil {
+			return err
+		}
+	} else if mirrorCfg.ProviderType != "archive" && mirrorCfg.ProviderType != "directory" {
 		if err := validateDomainName(mirrorCfg.GetDomain()); err != nil {
 			return fmt.Errorf("%w: %w", ErrNoTargetDomain, err)
 		}
@@ -176,6 +389,10 @@ func validateMirrorConfig(mirrorCfg config.MirrorConfig) error {
 			return err
 		}
 
+		if err := validateProviderOwner(mirrorCfg.ProviderType, mirrorCfg.Owner); err != nil {
+			return err
+		}
+
 		if mirrorCfg.UseGitBinary {
 			// Note: Assuming gitbinary.ValidateGitBinary() is available
 			if _, err := gitbinary.ValidateGitBinary(); err != nil {
@@ -188,15 +405,203 @@ func validateMirrorConfig(mirrorCfg config.MirrorConfig) error {
 		return err
 	}
 
+	if err := validateGitHubApp(mirrorCfg.ProviderType, mirrorCfg.Auth); err != nil {
+		return err
+	}
+
 	if err := validateMirrorSettings(mirrorCfg.Settings); err != nil {
 		return err
 	}
 
+	if len(mirrorCfg.Settings.RefSpecs) > 0 && (mirrorCfg.WritesArchives() || mirrorCfg.ProviderType == config.DIRECTORY) {
+		return fmt.Errorf("%w: refspecs are not supported by %s mirrors", config.ErrInvalidRefSpec, mirrorCfg.ProviderType)
+	}
+
+	if mirrorCfg.Settings.Prune && mirrorCfg.WritesArchives() {
+		return ErrPruneArchive
+	}
+
+	if err := validateVerification(mirrorCfg); err != nil {
+		return err
+	}
+
+	if err := validateArchiveFormat(mirrorCfg); err != nil {
+		return err
+	}
+
+	if err := validateRetention(mirrorCfg); err != nil {
+		return err
+	}
+
+	if err := validateEncryption(mirrorCfg.ProviderType, mirrorCfg.Encryption, true); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateEncryption validates the encryption of an archive or s3 mirror, or an archive source. A mirror encrypts to X25519 recipients
+// or with a passphrase, which age does not combine, and may read its archives back with an identity file.
+// A source decrypts with an identity file or a passphrase.
+func validateEncryption(providerType string, encryption config.EncryptionConfig, mirror bool) error {
+	if !encryption.IsEnabled() {
+		return nil
+	}
+
+	if providerType != config.ARCHIVE && (!mirror || providerType != config.S3) {
+		return fmt.Errorf("%w: encryption is only supported by archive and s3 mirrors, and archive sources", ErrEncryption)
+	}
+
+	hasRecipients := len(encryption.Recipients) > 0 || encryption.RecipientsFile != ""
+
+	switch {
+	case mirror && !hasRecipients && encryption.PassphraseFile == "":
+		return fmt.Errorf("%w: recipients, recipients_file or passphrase_file is required", ErrEncryption)
+	case mirror && hasRecipients && encryption.PassphraseFile != "":
+		return fmt.Errorf("%w: recipients and passphrase_file can not be combined", ErrEncryption)
+	case !mirror && hasRecipients:
+		return fmt.Errorf("%w: a source decrypts with identity_file or passphrase_file, recipients only encrypt", ErrEncryption)
+	}
+
+	for _, recipient := range encryption.Recipients {
+		if _, err := age.ParseX25519Recipient(recipient); err != nil {
+			return fmt.Errorf("%w: %w", ErrEncryption, err)
+		}
+	}
+
+	for _, path := range []string{encryption.IdentityFile, encryption.PassphraseFile, encryption.RecipientsFile} {
+		if path == "" {
+			continue
+		}
+
+		if err := validatePathExists(path); err != nil {
+			return fmt.Errorf("%w: %w", ErrEncryption, err)
+		}
+	}
+
+	return nil
+}
+
+// validateRetention validates the retention policy of an archive or s3 mirror.
+func validateRetention(mirrorCfg config.MirrorConfig) error {
+	settings := mirrorCfg.Settings
+
+	for key, count := range map[string]int{
+		"keep_last":    settings.KeepLast,
+		"keep_daily":   settings.KeepDaily,
+		"keep_weekly":  settings.KeepWeekly,
+		"keep_monthly": settings.KeepMonthly,
+	} {
+		if count < 0 {
+			return fmt.Errorf("%w: %s must not be negative, got %d", ErrRetention, key, count)
+		}
+	}
+
+	if !settings.HasRetention() {
+		return nil
+	}
+
+	if !mirrorCfg.WritesArchives() {
+		return fmt.Errorf("%w: retention is only supported by archive and s3 mirrors", ErrRetention)
+	}
+
+	if _, err := settings.MaxAgeDuration(); err != nil {
+		return fmt.Errorf("%w: %w", ErrRetention, err)
+	}
+
+	return nil
+}
+
+// maxCompressionLevels are the highest compression levels of the compressed archive formats.
+var maxCompressionLevels = map[string]int{
+	config.ArchiveFormatTarGz:  9,
+	config.ArchiveFormatTarZst: 22,
+	config.ArchiveFormatTarXz:  9,
+}
+
+// validateArchiveFormat validates the format and compression level of an archive or s3 mirror.
+// A git bundle is not compressed, and holds the repository without the lfs objects.
+func validateArchiveFormat(mirrorCfg config.MirrorConfig) error {
+	settings := mirrorCfg.Settings
+
+	if settings.Format == "" && settings.CompressionLevel == 0 {
+		return nil
+	}
+
+	if !mirrorCfg.WritesArchives() {
+		return fmt.Errorf("%w: format and compression_level are only supported by archive and s3 mirrors", ErrArchiveFormat)
+	}
+
+	if !slices.Contains(ValidArchiveFormats, settings.Format) {
+		return fmt.Errorf("%w: %s, valid formats are %s", ErrArchiveFormat, settings.Format, strings.Join(ValidArchiveFormats[1:], ", "))
+	}
+
+	if settings.Format == config.ArchiveFormatBundle {
+		if settings.CompressionLevel != 0 {
+			return fmt.Errorf("%w: compression_level is not supported by bundles", ErrArchiveFormat)
+		}
+
+		if settings.LFS {
+			return fmt.Errorf("%w: bundles hold no lfs objects, use a tar format with lfs", ErrArchiveFormat)
+		}
+
+		return nil
+	}
+
+	format := settings.Format
+	if format == "" {
+		format = config.ArchiveFormatTarGz
+	}
+
+	if maxLevel := maxCompressionLevels[format]; settings.CompressionLevel < 0 || settings.CompressionLevel > maxLevel {
+		return fmt.Errorf("%w: compression_level %d, valid levels for %s are 1 to %d", ErrArchiveFormat, settings.CompressionLevel, format, maxLevel)
+	}
+
+	return nil
+}
+
+// validateVerification validates the verification settings of a mirror. Verification compares
+// with the refs of the mirror, which directory, archive and s3 mirrors do not push to.
+func validateVerification(mirrorCfg config.MirrorConfig) error {
+	settings := mirrorCfg.Settings
+
+	if !settings.VerifyHistory && len(settings.RequireSigned) == 0 {
+		if settings.Quarantine || settings.SigningKeysPath != "" || settings.AllowedSignersPath != "" {
+			return fmt.Errorf("%w: quarantine and signature keys require verify_history or require_signed", ErrVerifyConfig)
+		}
+
+		return nil
+	}
+
+	if mirrorCfg.WritesArchives() || mirrorCfg.ProviderType == config.DIRECTORY {
+		return fmt.Errorf("%w: verification is not supported by %s mirrors", ErrVerifyConfig, mirrorCfg.ProviderType)
+	}
+
+	for _, pattern := range settings.RequireSigned {
+		if strings.ContainsAny(pattern, "^:") || config.ValidateRefSpec(pattern) != nil {
+			return fmt.Errorf("%w: require_signed: not a ref pattern: %s", ErrVerifyConfig, pattern)
+		}
+	}
+
+	for _, path := range []string{settings.SigningKeysPath, settings.AllowedSignersPath} {
+		if path == "" {
+			continue
+		}
+
+		if err := validatePathExists(path); err != nil {
+			return fmt.Errorf("%w: %w", ErrVerifyConfig, err)
+		}
+	}
+
 	return nil
 }
 
 // validateAuth validates authentication configuration.
 func validateAuth(authCfg config.AuthConfig) error {
+	if err := validateTokenSource(authCfg); err != nil {
+		return err
+	}
+
 	if !isValidSchemeType(authCfg.HTTPScheme) {
 		return fmt.Errorf("invalid HTTP scheme: %w", ErrUnsupportedScheme)
 	}
@@ -214,7 +619,7 @@ func validateAuth(authCfg config.AuthConfig) error {
 	}
 
 	if authCfg.Protocol == config.SSH {
-		if err := checkSSHAgent(); err != nil {
+		if err := validateSSHAuth(authCfg); err != nil {
 			return err
 		}
 	}
@@ -240,6 +645,12 @@ func validateMirrorSettings(settings config.MirrorSettings) error {
 		return errors.New("invalid visibility setting")
 	}
 
+	for _, refSpec := range settings.RefSpecs {
+		if err := config.ValidateRefSpec(refSpec); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
 	return nil
 }
 
@@ -260,6 +671,93 @@ func validateOwner(owner, ownerType string) error {
 	return validateGroupName(owner)
 }
 
+// validateProviderOwner validates owner formats specific to a provider type.
+func validateProviderOwner(providerType, owner string) error {
+	if providerType == config.AZUREDEVOPS {
+		if _, _, ok := azuredevops.SplitOwner(owner); !ok {
+			return fmt.Errorf("%w: azuredevops owner must be <organization>/<project>, got %s", ErrInvalidOwner, owner)
+		}
+	}
+
+	return nil
+}
+
+// validateTokenSource validates that the token comes from one place only, a literal or a credential source.
+func validateTokenSource(authCfg config.AuthConfig) error {
+	sources := 0
+
+	for _, source := range []string{authCfg.Token, authCfg.TokenFile, authCfg.TokenCommand, authCfg.TokenEnv} {
+		if source != "" {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		return fmt.Errorf("%w: set only one of token, token_file, token_command and token_env", ErrInvalidToken)
+	}
+
+	if authCfg.TokenFile != "" && !filepath.IsAbs(authCfg.TokenFile) {
+		return fmt.Errorf("%w: token_file must be absolute: %s", ErrInvalidPath, authCfg.TokenFile)
+	}
+
+	return nil
+}
+
+// validateGitHubApp validates that GitHub App authentication is complete, and only used with GitHub.
+func validateGitHubApp(providerType string, authCfg config.AuthConfig) error {
+	if !authCfg.IsGitHubApp() && authCfg.GitHubAppInstallationID == 0 && authCfg.GitHubAppPrivateKeyPath == "" {
+		return nil
+	}
+
+	if providerType != config.GITHUB {
+		return fmt.Errorf("%w: only supported for github, got %s", ErrGitHubAppAuth, providerType)
+	}
+
+	if !authCfg.IsGitHubApp() || authCfg.GitHubAppInstallationID == 0 || authCfg.GitHubAppPrivateKeyPath == "" {
+		return fmt.Errorf("%w: github_app_id, github_app_installation_id and github_app_private_key_path are all required", ErrGitHubAppAuth)
+	}
+
+	if err := validatePathExists(authCfg.GitHubAppPrivateKeyPath); err != nil {
+		return fmt.Errorf("invalid GitHub App private key path: %w", err)
+	}
+
+	return nil
+}
+
+// validateSSHAuth validates the private key and known_hosts files, falling back to
+// requiring a running SSH agent when no private key is configured.
+func validateSSHAuth(authCfg config.AuthConfig) error {
+	if authCfg.KnownHostsPath != "" {
+		if err := validatePathExists(authCfg.KnownHostsPath); err != nil {
+			return fmt.Errorf("invalid known hosts path: %w", err)
+		}
+	}
+
+	if authCfg.SSHPassphraseEnv != "" && authCfg.SSHPassphraseFile != "" {
+		return fmt.Errorf("%w: set either ssh_passphrase_env or ssh_passphrase_file", ErrSSHKeyAuth)
+	}
+
+	if authCfg.SSHPrivateKeyPath == "" {
+		if authCfg.SSHPassphraseEnv != "" || authCfg.SSHPassphraseFile != "" {
+			return fmt.Errorf("%w: passphrase configured without ssh_private_key_path", ErrSSHKeyAuth)
+		}
+
+		return checkSSHAgent()
+	}
+
+	if err := validatePathExists(authCfg.SSHPrivateKeyPath); err != nil {
+		return fmt.Errorf("invalid ssh private key path: %w", err)
+	}
+
+	if authCfg.SSHPassphraseFile != "" {
+		if err := validatePathExists(authCfg.SSHPassphraseFile); err != nil {
+			return fmt.Errorf("invalid ssh passphrase file: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func checkSSHAgent() error {
 	sshAuthSock := os.Getenv(sshAuthSockEnv)
 	if sshAuthSock == "" {
@@ -41,6 +41,22 @@ type SourceReader interface {
 	Clone(ctx context.Context, option model.CloneOption) (model.Repository, error)
 }
 
+// RefLister is implemented by SourceReaders that can list the refs of a source
+// repository without cloning it, the equivalent of git ls-remote.
+type RefLister interface {
+	// ListRefs returns the refs of the repository described by option.
+	//
+	// Parameters:
+	//   - ctx: A context.Context for handling cancellation and timeouts.
+	//   - option: A model.CloneOption holding the repository URL and authentication.
+	//
+	// Returns:
+	//   - map[string]string: The commit hash of every ref under refs/, keyed by ref name.
+	//     An empty repository gives an empty map.
+	//   - error: An error if the refs could not be listed.
+	ListRefs(ctx context.Context, option model.CloneOption) (map[string]string, error)
+}
+
 // Example usage:
 //
 //	type GitHubReader struct {
@@ -127,6 +127,21 @@ func Logger(ctx context.Context) *zerolog.Logger {
 	return zerolog.Ctx(ctx)
 }
 
+// WithRepository returns a context whose logger adds the repository name to every log entry.
+// It is used to tell apart log output from repositories processed concurrently.
+//
+// Parameters:
+//   - ctx: The context containing the logger
+//   - name: The repository name to add to the log entries
+//
+// Returns:
+//   - context.Context with the repository scoped logger
+func WithRepository(ctx context.Context, name string) context.Context {
+	logger := Logger(ctx).With().Str("repository", name).Logger()
+
+	return logger.WithContext(ctx)
+}
+
 // getLogLevel determines the log level based on command flags.
 // It checks for the "quiet" flag first, then falls back to the "verbosity" flag.
 //
@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+)
+
+// bundleSignature starts a version 2 git bundle, which the refs, an empty line and a packfile follow.
+const bundleSignature = "# v2 git bundle"
+
+// bundleRef is a ref listed in a git bundle.
+type bundleRef struct {
+	name string
+	hash plumbing.Hash
+}
+
+// writeBundle writes the bare repository at repoDir to output as a git bundle holding all its refs and objects,
+// which git clones from directly. HEAD is listed first and the branch it points at next,
+// which is the branch git checks out when cloning.
+func writeBundle(repoDir string, output io.Writer) error {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBundle, err)
+	}
+
+	// A bundle of a shallow repository would need prerequisite commits, which no clone has
+	if shallow, err := repo.Storer.Shallow(); err != nil || len(shallow) > 0 {
+		return fmt.Errorf("%w: shallow repositories are not supported", ErrBundle)
+	}
+
+	refs, err := bundleRefs(repo)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBundle, err)
+	}
+
+	objects, err := repo.Storer.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBundle, err)
+	}
+
+	hashes := []plumbing.Hash{}
+
+	err = objects.ForEach(func(object plumbing.EncodedObject) error {
+		hashes = append(hashes, object.Hash())
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBundle, err)
+	}
+
+	writer := bufio.NewWriter(output)
+	fmt.Fprintln(writer, bundleSignature)
+
+	for _, ref := range refs {
+		fmt.Fprintf(writer, "%s %s\n", ref.hash, ref.name)
+	}
+
+	fmt.Fprintln(writer)
+
+	if _, err := packfile.NewEncoder(writer, repo.Storer, false).Encode(hashes, 10); err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveCompression, err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveCompression, err)
+	}
+
+	return nil
+}
+
+// bundleRefs returns the refs of the repository in bundle order, HEAD and the branch it points at first.
+func bundleRefs(repo *git.Repository) ([]bundleRef, error) {
+	refIter, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	refs := []bundleRef{}
+
+	err = refIter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference {
+			refs = append(refs, bundleRef{name: ref.Name().String(), hash: ref.Hash()})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	if len(refs) == 0 {
+		return nil, ErrNoRepository
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		slices.SortFunc(refs, func(a, b bundleRef) int { return strings.Compare(a.name, b.name) })
+
+		return refs, nil //nolint:nilerr // a repository without a default branch has no HEAD to list
+	}
+
+	slices.SortFunc(refs, func(a, b bundleRef) int {
+		switch {
+		case a.name == head.Name().String():
+			return -1
+		case b.name == head.Name().String():
+			return 1
+		default:
+			return strings.Compare(a.name, b.name)
+		}
+	})
+
+	return append([]bundleRef{{name: plumbing.HEAD.String(), hash: head.Hash()}}, refs...), nil
+}
+
+// readBundleRefs reads the refs listed by a git bundle, leaving the reader at its packfile.
+func readBundleRefs(reader *bufio.Reader) ([]bundleRef, error) {
+	signature, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSpace(signature) != bundleSignature {
+		return nil, fmt.Errorf("%w: not a v2 git bundle", ErrBundle)
+	}
+
+	refs := []bundleRef{}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBundle, err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return refs, nil
+		}
+
+		if strings.HasPrefix(line, "-") {
+			return nil, fmt.Errorf("%w: bundles with prerequisite commits are not supported", ErrBundle)
+		}
+
+		hash, name, found := strings.Cut(line, " ")
+		if !found || !plumbing.IsHash(hash) {
+			return nil, fmt.Errorf("%w: invalid ref line %q", ErrBundle, line)
+		}
+
+		refs = append(refs, bundleRef{name: name, hash: plumbing.NewHash(hash)})
+	}
+}
+
+// bundleHeadBranch returns the branch HEAD points at, the first branch listed at the commit of HEAD, as git guesses it
+// when cloning a bundle. It is empty when the bundle lists no HEAD.
+func bundleHeadBranch(refs []bundleRef) string {
+	headIndex := slices.IndexFunc(refs, func(ref bundleRef) bool { return ref.name == plumbing.HEAD.String() })
+	if headIndex < 0 {
+		return ""
+	}
+
+	for _, ref := range refs {
+		if ref.hash == refs[headIndex].hash && strings.HasPrefix(ref.name, "refs/heads/") {
+			return strings.TrimPrefix(ref.name, "refs/heads/")
+		}
+	}
+
+	return ""
+}
+
+// openBundle opens a git bundle and reads its refs.
+func (h *Handler) openBundle(bundlePath string) (io.Closer, *bufio.Reader, []bundleRef, error) {
+	file, err := h.openFile(bundlePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	reader := bufio.NewReader(file)
+
+	refs, err := readBundleRefs(reader)
+	if err != nil {
+		file.Close()
+
+		return nil, nil, nil, fmt.Errorf("%s: %w", bundlePath, err)
+	}
+
+	return file, reader, refs, nil
+}
+
+// unbundle creates a bare repository at repoDir holding the refs and objects of a git bundle.
+func (h *Handler) unbundle(bundlePath, repoDir string) error {
+	file, reader, refs, err := h.openBundle(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	repo, err := git.PlainInit(repoDir, true)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRepoInitialization, err)
+	}
+
+	if err := packfile.UpdateObjectStorage(repo.Storer, reader); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %s: %w", ErrBundle, bundlePath, err)
+	}
+
+	for _, ref := range refs {
+		if ref.name == plumbing.HEAD.String() {
+			continue
+		}
+
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(ref.name), ref.hash)); err != nil {
+			return fmt.Errorf("%w: %w", ErrBundle, err)
+		}
+	}
+
+	if branch := bundleHeadBranch(refs); branch != "" {
+		head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(branch))
+		if err := repo.Storer.SetReference(head); err != nil {
+			return fmt.Errorf("%w: %w", ErrBundle, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// EncryptedExtension is appended to the file name of an archive encrypted with age, such as .tar.gz.age.
+const EncryptedExtension = ".age"
+
+// Encryption methods recorded in the manifest of an encrypted archive.
+const (
+	EncryptionX25519 = "x25519"
+	EncryptionScrypt = "scrypt"
+)
+
+// Encryption encrypts archives with age to its recipients, and decrypts them with its identities.
+// A passphrase is both, as an scrypt recipient and identity.
+type Encryption struct {
+	recipients   []age.Recipient
+	identities   []age.Identity
+	method       string
+	fingerprints []string
+}
+
+// NewEncryption creates the encryption the configuration sets, nil when it sets none.
+// The recipients and passphrase are read and parsed here, so a bad key fails before any archive is written.
+func NewEncryption(cfg gpsconfig.EncryptionConfig) (*Encryption, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil // no encryption is configured
+	}
+
+	encryption := &Encryption{}
+
+	for _, recipient := range cfg.Recipients {
+		if err := encryption.addRecipients(strings.NewReader(recipient)); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.RecipientsFile != "" {
+		file, err := os.Open(cfg.RecipientsFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrEncryption, err)
+		}
+		defer file.Close()
+
+		if err := encryption.addRecipients(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.RecipientsFile, err)
+		}
+	}
+
+	if cfg.PassphraseFile != "" {
+		if err := encryption.addPassphrase(cfg.PassphraseFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.IdentityFile != "" {
+		file, err := os.Open(cfg.IdentityFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrEncryption, err)
+		}
+		defer file.Close()
+
+		identities, err := age.ParseIdentities(file)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrEncryption, cfg.IdentityFile, err)
+		}
+
+		encryption.identities = append(encryption.identities, identities...)
+	}
+
+	return encryption, nil
+}
+
+// addRecipients adds the X25519 recipients read from reader, one per line.
+func (e *Encryption) addRecipients(reader io.Reader) error {
+	recipients, err := age.ParseRecipients(reader)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrEncryption, err)
+	}
+
+	for _, recipient := range recipients {
+		if x25519, ok := recipient.(*age.X25519Recipient); ok {
+			e.fingerprints = append(e.fingerprints, Fingerprint(x25519.String()))
+		}
+	}
+
+	e.recipients = append(e.recipients, recipients...)
+	e.method = EncryptionX25519
+
+	return nil
+}
+
+// addPassphrase adds the passphrase read from the file at path, as recipient and identity.
+func (e *Encryption) addPassphrase(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrEncryption, err)
+	}
+
+	passphrase := strings.TrimRight(string(content), "\r\n")
+	if passphrase == "" {
+		return fmt.Errorf("%w: %s: empty passphrase", ErrEncryption, path)
+	}
+
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrEncryption, err)
+	}
+
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrEncryption, err)
+	}
+
+	e.recipients = append(e.recipients, recipient)
+	e.identities = append(e.identities, identity)
+	e.method = EncryptionScrypt
+
+	return nil
+}
+
+// CanDecrypt reports whether the encryption has an identity to decrypt archives with.
+func (e *Encryption) CanDecrypt() bool {
+	return e != nil && len(e.identities) > 0
+}
+
+// ManifestEncryption returns how the encryption encrypts, as recorded in the manifest of an archive.
+func (e *Encryption) ManifestEncryption() *ManifestEncryption {
+	if e == nil || len(e.recipients) == 0 {
+		return nil
+	}
+
+	return &ManifestEncryption{Method: e.method, Fingerprints: e.fingerprints}
+}
+
+// Fingerprint returns the fingerprint of an age recipient, the base64 encoded SHA-256 of its public key.
+// Unlike the public key it identifies the key an archive was encrypted to without revealing it.
+func Fingerprint(recipient string) string {
+	sum := sha256.Sum256([]byte(recipient))
+
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// IsEncrypted reports whether the archive at path is encrypted with age, by its file extension.
+func IsEncrypted(path string) bool {
+	return strings.HasSuffix(path, EncryptedExtension)
+}
+
+// decryptingReader reads an archive file through age decryption.
+type decryptingReader struct {
+	io.Reader
+	io.Closer
+}
+
+// createFile creates the archive file at targetPath.
+func createFile(targetPath string) (*os.File, error) {
+	file, err := os.Create(targetPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrArchiveCreation, targetPath, err)
+	}
+
+	if err := os.Chmod(targetPath, 0o644); err != nil {
+		file.Close()
+
+		return nil, fmt.Errorf("failed to set permissions on %s: %w", targetPath, err)
+	}
+
+	return file, nil
+}
+
+// encrypt returns a writer to output, encrypting what is written when the archive at targetPath is named as encrypted.
+// Closing the writer finishes the encryption, not output.
+func (h *Handler) encrypt(targetPath string, output io.Writer) (io.WriteCloser, error) {
+	if !IsEncrypted(targetPath) {
+		return nopWriteCloser{Writer: output}, nil
+	}
+
+	if h.encryption == nil || len(h.encryption.recipients) == 0 {
+		return nil, fmt.Errorf("%w: %s: no recipient or passphrase to encrypt to", ErrEncryption, targetPath)
+	}
+
+	writer, err := age.Encrypt(output, h.encryption.recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncryption, err)
+	}
+
+	return writer, nil
+}
+
+// nopWriteCloser is a writer of an unencrypted archive, which has nothing to finish.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// openFile opens the archive file at archivePath, decrypting what is read from it when it is named as encrypted.
+func (h *Handler) openFile(archivePath string) (io.ReadCloser, error) {
+	if IsEncrypted(archivePath) && !h.encryption.CanDecrypt() {
+		return nil, fmt.Errorf("%w: %s: no identity or passphrase to decrypt with", ErrEncryption, archivePath)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrArchiveRead, archivePath, err)
+	}
+
+	if !IsEncrypted(archivePath) {
+		return file, nil
+	}
+
+	reader, err := age.Decrypt(file, h.encryption.identities...)
+	if err != nil {
+		file.Close()
+
+		return nil, fmt.Errorf("%w: %s: %w", ErrEncryption, archivePath, err)
+	}
+
+	return decryptingReader{Reader: reader, Closer: file}, nil
+}
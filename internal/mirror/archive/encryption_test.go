@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"filippo.io/age"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+// newIdentityFile writes a new X25519 identity to a file, returning the file and the recipient of the identity.
+func newIdentityFile(t *testing.T) (string, string) {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	identityFile := filepath.Join(t.TempDir(), "backup.key")
+	require.NoError(t, os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0o600))
+
+	return identityFile, identity.Recipient().String()
+}
+
+// newEncryptedArchive archives a bare repository in the format, encrypted with the encryption of cfg.
+func newEncryptedArchive(t *testing.T, cfg gpsconfig.EncryptionConfig, format string) string {
+	t.Helper()
+
+	encryption, err := NewEncryption(cfg)
+	require.NoError(t, err)
+
+	repoDir := filepath.Join(t.TempDir(), "tools")
+	newBareRepository(t, repoDir, "trunk")
+
+	archivePath := filepath.Join(t.TempDir(), "tools"+FormatArchiveTimestamp(time.Now())+Extension(format)+EncryptedExtension)
+	require.NoError(t, NewHandler().WithEncryption(encryption).CreateArchive(context.Background(), repoDir, archivePath, "tools"))
+
+	return archivePath
+}
+
+func TestReader_CloneEncrypted(t *testing.T) {
+	identityFile, recipient := newIdentityFile(t)
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("correct horse battery staple\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		format  string
+		encrypt gpsconfig.EncryptionConfig
+		decrypt gpsconfig.EncryptionConfig
+	}{
+		{
+			name:    "tar.gz to recipient",
+			format:  gpsconfig.ArchiveFormatTarGz,
+			encrypt: gpsconfig.EncryptionConfig{Recipients: []string{recipient}},
+			decrypt: gpsconfig.EncryptionConfig{IdentityFile: identityFile},
+		},
+		{
+			name:    "bundle to recipient",
+			format:  gpsconfig.ArchiveFormatBundle,
+			encrypt: gpsconfig.EncryptionConfig{Recipients: []string{recipient}},
+			decrypt: gpsconfig.EncryptionConfig{IdentityFile: identityFile},
+		},
+		{
+			name:    "tar.zst with passphrase",
+			format:  gpsconfig.ArchiveFormatTarZst,
+			encrypt: gpsconfig.EncryptionConfig{PassphraseFile: passphraseFile},
+			decrypt: gpsconfig.EncryptionConfig{PassphraseFile: passphraseFile},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			ctx := context.Background()
+			archivePath := newEncryptedArchive(t, tabletest.encrypt, tabletest.format)
+
+			name, _, ok := ParseTargetPath(archivePath)
+			require.True(t, ok)
+			require.Equal(t, "tools", name)
+
+			content, err := os.ReadFile(archivePath)
+			require.NoError(t, err)
+			require.True(t, bytes.HasPrefix(content, []byte("age-encryption.org/v1")))
+
+			encryption, err := NewEncryption(tabletest.decrypt)
+			require.NoError(t, err)
+
+			reader := NewReader().WithEncryption(encryption)
+
+			branch, err := reader.HeadBranch(ctx, archivePath)
+			require.NoError(t, err)
+			require.Equal(t, "trunk", branch)
+
+			repo, err := reader.Clone(ctx, model.CloneOption{Name: "tools", URL: archivePath, Mirror: true})
+			require.NoError(t, err)
+
+			_, err = repo.GoGitRepository().Reference(plumbing.NewBranchReferenceName("trunk"), false)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReader_DecryptFails(t *testing.T) {
+	_, recipient := newIdentityFile(t)
+	otherIdentityFile, _ := newIdentityFile(t)
+	archivePath := newEncryptedArchive(t, gpsconfig.EncryptionConfig{Recipients: []string{recipient}}, gpsconfig.ArchiveFormatTarGz)
+
+	tests := []struct {
+		name    string
+		decrypt gpsconfig.EncryptionConfig
+	}{
+		{name: "no identity"},
+		{name: "recipient only", decrypt: gpsconfig.EncryptionConfig{Recipients: []string{recipient}}},
+		{name: "other identity", decrypt: gpsconfig.EncryptionConfig{IdentityFile: otherIdentityFile}},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			encryption, err := NewEncryption(tabletest.decrypt)
+			require.NoError(t, err)
+
+			_, err = NewReader().WithEncryption(encryption).HeadBranch(context.Background(), archivePath)
+			require.ErrorIs(t, err, ErrEncryption)
+		})
+	}
+}
+
+func TestHandler_CreateArchiveWithoutRecipient(t *testing.T) {
+	identityFile, _ := newIdentityFile(t)
+
+	encryption, err := NewEncryption(gpsconfig.EncryptionConfig{IdentityFile: identityFile})
+	require.NoError(t, err)
+
+	repoDir := filepath.Join(t.TempDir(), "tools")
+	newBareRepository(t, repoDir, "main")
+
+	archivePath := filepath.Join(t.TempDir(), "tools"+FormatArchiveTimestamp(time.Now())+".tar.gz"+EncryptedExtension)
+	err = NewHandler().WithEncryption(encryption).CreateArchive(context.Background(), repoDir, archivePath, "tools")
+	require.ErrorIs(t, err, ErrEncryption)
+	require.NoFileExists(t, archivePath)
+}
+
+func TestNewEncryption(t *testing.T) {
+	_, recipient := newIdentityFile(t)
+	_, otherRecipient := newIdentityFile(t)
+	recipientsFile := filepath.Join(t.TempDir(), "recipients.txt")
+	require.NoError(t, os.WriteFile(recipientsFile, []byte("# backup keys\n"+otherRecipient+"\n"), 0o600))
+
+	tests := []struct {
+		name      string
+		cfg       gpsconfig.EncryptionConfig
+		want      *ManifestEncryption
+		wantNil   bool
+		wantError bool
+	}{
+		{
+			name:    "not configured",
+			wantNil: true,
+		},
+		{
+			name: "recipients and recipients file",
+			cfg:  gpsconfig.EncryptionConfig{Recipients: []string{recipient}, RecipientsFile: recipientsFile},
+			want: &ManifestEncryption{Method: EncryptionX25519, Fingerprints: []string{Fingerprint(recipient), Fingerprint(otherRecipient)}},
+		},
+		{
+			name:      "invalid recipient",
+			cfg:       gpsconfig.EncryptionConfig{Recipients: []string{"age1invalid"}},
+			wantError: true,
+		},
+		{
+			name:      "missing identity file",
+			cfg:       gpsconfig.EncryptionConfig{IdentityFile: filepath.Join(t.TempDir(), "missing.key")},
+			wantError: true,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			encryption, err := NewEncryption(tabletest.cfg)
+			if tabletest.wantError {
+				require.ErrorIs(t, err, ErrEncryption)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tabletest.wantNil {
+				require.Nil(t, encryption)
+
+				return
+			}
+
+			require.Equal(t, tabletest.want, encryption.ManifestEncryption())
+		})
+	}
+}
@@ -7,10 +7,19 @@ package archive
 import "errors"
 
 var (
-	ErrArchiveCompression = errors.New("failed to compress archive")
-	ErrArchiveCreation    = errors.New("failed to create archive file")
-	ErrDirectoryCreation  = errors.New("failed to create target directory")
-	ErrNoFilesToArchive   = errors.New("no files found to archive")
-	ErrRepoInitialization = errors.New("failed to initialize repository")
-	ErrPushRepository     = errors.New("failed to push to repository")
+	ErrArchiveCompression    = errors.New("failed to compress archive")
+	ErrArchiveCreation       = errors.New("failed to create archive file")
+	ErrArchiveRead           = errors.New("failed to read archive file")
+	ErrBundle                = errors.New("failed to process git bundle")
+	ErrChecksumMismatch      = errors.New("archive checksum does not match its manifest")
+	ErrCopyShallowRepository = errors.New("failed to copy shallow repository")
+	ErrCorruptRepository     = errors.New("archived repository is corrupt")
+	ErrDirectoryCreation     = errors.New("failed to create target directory")
+	ErrEncryption            = errors.New("failed to process archive encryption")
+	ErrManifest              = errors.New("failed to process archive manifest")
+	ErrNoFilesToArchive      = errors.New("no files found to archive")
+	ErrRepoInitialization    = errors.New("failed to initialize repository")
+	ErrPushRepository        = errors.New("failed to push to repository")
+	ErrUnsafeArchivePath     = errors.New("archive entry outside target directory")
+	ErrNoRepository          = errors.New("no repository found in archive")
 )
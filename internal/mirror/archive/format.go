@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/mholt/archives"
+	"github.com/ulikunitz/xz"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// formatExtensions maps the archive formats to the file extensions of their archives.
+var formatExtensions = map[string]string{
+	gpsconfig.ArchiveFormatTarGz:  ".tar.gz",
+	gpsconfig.ArchiveFormatTarZst: ".tar.zst",
+	gpsconfig.ArchiveFormatTarXz:  ".tar.xz",
+	gpsconfig.ArchiveFormatBundle: ".bundle",
+}
+
+// xzDictCaps are the dictionary sizes of the xz presets 1 to 9, which the compression level selects.
+var xzDictCaps = []int{1 << 20, 2 << 20, 4 << 20, 4 << 20, 8 << 20, 8 << 20, 16 << 20, 32 << 20, 64 << 20}
+
+// Extension returns the file extension of the archives of a format, tar.gz when no format is set.
+func Extension(format string) string {
+	if extension, ok := formatExtensions[format]; ok {
+		return extension
+	}
+
+	return formatExtensions[gpsconfig.ArchiveFormatTarGz]
+}
+
+// formatOf returns the format of an archive from its file extension, encrypted or not.
+func formatOf(path string) (string, bool) {
+	path = strings.TrimSuffix(path, EncryptedExtension)
+
+	for format, extension := range formatExtensions {
+		if strings.HasSuffix(path, extension) {
+			return format, true
+		}
+	}
+
+	return "", false
+}
+
+// trimExtension removes the archive extension, and the extension of encryption, from path.
+func trimExtension(path string) string {
+	path = strings.TrimSuffix(path, EncryptedExtension)
+
+	if format, ok := formatOf(path); ok {
+		return strings.TrimSuffix(path, formatExtensions[format])
+	}
+
+	return path
+}
+
+// archiveFormat returns the tar archive compressed as the format, at the compression level when it is not 0.
+func archiveFormat(format string, level int) archives.CompressedArchive {
+	var compression archives.Compression
+
+	switch format {
+	case gpsconfig.ArchiveFormatTarZst:
+		zstdCompression := archives.Zstd{}
+		if level != 0 {
+			zstdCompression.EncoderOptions = []zstd.EOption{zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level))}
+		}
+
+		compression = zstdCompression
+	case gpsconfig.ArchiveFormatTarXz:
+		compression = xzCompression{level: level}
+	default:
+		compression = archives.Gz{CompressionLevel: level}
+	}
+
+	return archives.CompressedArchive{
+		Compression: compression,
+		Archival:    archives.Tar{},
+		Extraction:  archives.Tar{},
+	}
+}
+
+// xzCompression is xz compression at a preset level, which archives.Xz does not offer.
+type xzCompression struct {
+	archives.Xz
+
+	level int
+}
+
+func (x xzCompression) OpenWriter(w io.Writer) (io.WriteCloser, error) {
+	if x.level == 0 {
+		return x.Xz.OpenWriter(w) //nolint:wrapcheck
+	}
+
+	return xz.WriterConfig{DictCap: xzDictCaps[x.level-1]}.NewWriter(w) //nolint:wrapcheck
+}
@@ -7,8 +7,10 @@ package archive
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/mirror/gitlib"
@@ -32,8 +34,19 @@ func (h *GitHandler) InitializeRepository(ctx context.Context, path string, repo
 		return fmt.Errorf("%w: %w", ErrRepoInitialization, err)
 	}
 
-	pushOpt := model.NewPushOption(path, false, true, gpsconfig.AuthConfig{})
-	if err := h.client.Push(ctx, repo, pushOpt); err != nil {
+	shallow, err := repo.GoGitRepository().Storer.Shallow()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRepoInitialization, err)
+	}
+
+	pushOpt := model.NewPushOption(path, nil, false, true, gpsconfig.AuthConfig{})
+
+	// go-git cannot push from a shallow clone, so its objects and refs are copied instead
+	if len(shallow) > 0 {
+		if err := copyShallowRepository(repo.GoGitRepository(), initializedRepo, pushOpt.RefSpecs, shallow); err != nil {
+			return fmt.Errorf("%w: %w", ErrCopyShallowRepository, err)
+		}
+	} else if err := h.client.Push(ctx, repo, pushOpt); err != nil {
 		return fmt.Errorf("%w: %w", ErrPushRepository, err)
 	}
 
@@ -48,6 +61,61 @@ func (h *GitHandler) Push(ctx context.Context, repo interfaces.GitRepository, op
 	return h.client.Push(ctx, repo, opt) //nolint
 }
 
+// copyShallowRepository copies the objects of a shallow source repository and the refs the refspecs push
+// to the target repository, which becomes shallow at the same commits.
+func copyShallowRepository(source, target *git.Repository, refSpecs []string, shallow []plumbing.Hash) error {
+	objects, err := source.Storer.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	err = objects.ForEach(func(object plumbing.EncodedObject) error {
+		_, err := target.Storer.SetEncodedObject(object)
+
+		return err //nolint
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy objects: %w", err)
+	}
+
+	refs, err := source.References()
+	if err != nil {
+		return fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	refNames := []string{}
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference {
+			refNames = append(refNames, ref.Name().String())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	for _, refSpec := range gpsconfig.ExpandRefSpecs(refSpecs, refNames) {
+		src, dst, _ := strings.Cut(refSpec, ":")
+
+		ref, err := source.Reference(plumbing.ReferenceName(src), false)
+		if err != nil {
+			return fmt.Errorf("failed to read ref %s: %w", src, err)
+		}
+
+		if err := target.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(dst), ref.Hash())); err != nil {
+			return fmt.Errorf("failed to write ref %s: %w", dst, err)
+		}
+	}
+
+	if err := target.Storer.SetShallow(shallow); err != nil {
+		return fmt.Errorf("failed to write shallow commits: %w", err)
+	}
+
+	return nil
+}
+
 // configureRepository handles the internal repository configuration.
 func (h *GitHandler) configureRepository(ctx context.Context, path string, isBare bool, repo interfaces.GitRepository, initializedRepo *git.Repository) error {
 	if err := h.client.Ops.SetRemoteAndBranch(ctx, path, repo); err != nil {
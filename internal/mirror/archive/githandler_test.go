@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/require"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+func TestCopyShallowRepository(t *testing.T) {
+	require := require.New(t)
+
+	upstream := t.TempDir()
+
+	repo, err := git.PlainInitWithOptions(upstream, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(err)
+
+	var head plumbing.Hash
+
+	for _, content := range []string{"first", "second"} {
+		require.NoError(os.WriteFile(filepath.Join(upstream, "file.txt"), []byte(content), 0o600))
+
+		_, err = worktree.Add("file.txt")
+		require.NoError(err)
+
+		head, err = worktree.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(err)
+	}
+
+	_, err = repo.CreateTag("v1.0", head, nil)
+	require.NoError(err)
+
+	source, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: upstream, Mirror: true, Depth: 1})
+	require.NoError(err)
+
+	shallow, err := source.Storer.Shallow()
+	require.NoError(err)
+	require.Equal([]plumbing.Hash{head}, shallow)
+
+	target, err := git.PlainInit(t.TempDir(), true)
+	require.NoError(err)
+	require.NoError(copyShallowRepository(source, target, gpsconfig.DefaultRefSpecs(), shallow))
+
+	for _, refName := range []plumbing.ReferenceName{"refs/heads/main", "refs/tags/v1.0"} {
+		ref, err := target.Reference(refName, false)
+		require.NoError(err)
+		require.Equal(head, ref.Hash())
+	}
+
+	commit, err := target.CommitObject(head)
+	require.NoError(err)
+
+	_, err = commit.Tree()
+	require.NoError(err)
+
+	targetShallow, err := target.Storer.Shallow()
+	require.NoError(err)
+	require.Equal(shallow, targetShallow)
+}
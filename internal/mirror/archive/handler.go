@@ -6,27 +6,96 @@ package archive
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/mholt/archives"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 )
 
-type Handler struct{}
+type Handler struct {
+	compressionLevel int
+	encryption       *Encryption
+}
 
 func NewHandler() *Handler {
 	return &Handler{}
 }
 
+// WithCompressionLevel sets the compression level of the archives the handler creates, 0 for the default of the format.
+func (h *Handler) WithCompressionLevel(level int) *Handler {
+	h.compressionLevel = level
+
+	return h
+}
+
+// WithEncryption sets the encryption of the archives the handler creates and reads, nil for none.
+func (h *Handler) WithEncryption(encryption *Encryption) *Handler {
+	h.encryption = encryption
+
+	return h
+}
+
+// CreateArchive archives the bare repository at sourceDir as name to the file at targetPath, in the format
+// its extension names. A partly written archive is removed.
 func (h *Handler) CreateArchive(ctx context.Context, sourceDir, targetPath, name string) error {
-	files, err := h.mapFilesToArchive(ctx, sourceDir, name)
+	file, err := createFile(targetPath)
 	if err != nil {
 		return err
 	}
 
-	return h.compress(ctx, targetPath, files)
+	err = h.WriteArchive(ctx, sourceDir, file, targetPath, name)
+
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("%w: %s: %w", ErrArchiveCreation, targetPath, closeErr)
+	}
+
+	if err != nil {
+		return errors.Join(err, os.Remove(targetPath))
+	}
+
+	return nil
+}
+
+// WriteArchive writes the archive of the bare repository at sourceDir as name to output, in the format
+// the extension of targetPath names. A git bundle holds the repository itself, a tar archive the repository
+// directory. An archive named with the .age extension is encrypted.
+func (h *Handler) WriteArchive(ctx context.Context, sourceDir string, output io.Writer, targetPath, name string) error {
+	format, _ := formatOf(targetPath)
+
+	var files []archives.FileInfo
+
+	if format != gpsconfig.ArchiveFormatBundle {
+		var err error
+		if files, err = h.mapFilesToArchive(ctx, sourceDir, name); err != nil {
+			return err
+		}
+	}
+
+	writer, err := h.encrypt(targetPath, output)
+	if err != nil {
+		return err
+	}
+
+	if format == gpsconfig.ArchiveFormatBundle {
+		err = writeBundle(sourceDir, writer)
+	} else {
+		err = h.compress(ctx, writer, archiveFormat(format, h.compressionLevel), files)
+	}
+
+	if closeErr := writer.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("%w: %s: %w", ErrEncryption, targetPath, closeErr)
+	}
+
+	return err
 }
 
 // ArchiveTargetPath generates the full path for the target archive file.
@@ -41,44 +110,145 @@ func nowString() string {
 		now.Hour(), now.Minute(), now.Second(), now.UnixMilli())
 }
 
-func TargetPath(name, targetDir string) string {
-	tarArchive := fmt.Sprintf("%s%s.tar.gz", name, nowString())
+// TargetPath returns the path of a new archive of the repository name in targetDir, with the extension of the format.
+func TargetPath(name, targetDir, format string) string {
+	archive := fmt.Sprintf("%s%s%s", name, nowString(), Extension(format))
 
-	return filepath.Join(targetDir, tarArchive)
+	return filepath.Join(targetDir, archive)
 }
 
-func (h *Handler) mapFilesToArchive(ctx context.Context, sourceDir, targetName string) ([]archives.FileInfo, error) {
-	files, err := archives.FilesFromDisk(ctx, nil, map[string]string{
-		sourceDir: targetName,
+// targetNamePattern matches the file names created by TargetPath, in any format.
+var targetNamePattern = regexp.MustCompile(`^(.+)_\d{8}_\d{6}_(\d+)\.(tar\.gz|tar\.zst|tar\.xz|bundle)(\.age)?$`)
+
+// ParseTargetPath splits an archive path created by TargetPath into the repository name and the creation time.
+// It reports false for files not named by TargetPath.
+func ParseTargetPath(path string) (string, time.Time, bool) {
+	matches := targetNamePattern.FindStringSubmatch(filepath.Base(path))
+	if matches == nil {
+		return "", time.Time{}, false
+	}
+
+	unixMilli, err := strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return matches[1], time.UnixMilli(unixMilli), true
+}
+
+// ReadFile returns the content of a single file in the archive, such as <name>/HEAD.
+func (h *Handler) ReadFile(ctx context.Context, archivePath, nameInArchive string) ([]byte, error) {
+	file, err := h.openFile(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var content []byte
+
+	format, _ := formatOf(archivePath)
+
+	err = archiveFormat(format, 0).Extract(ctx, file, func(_ context.Context, info archives.FileInfo) error {
+		if info.NameInArchive != nameInArchive {
+			return nil
+		}
+
+		reader, err := info.Open()
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		defer reader.Close()
+
+		if content, err = io.ReadAll(reader); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return fs.SkipAll
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to map files at %s to tar archive: %w", sourceDir, err)
+		return nil, fmt.Errorf("%w: %s: %w", ErrArchiveRead, archivePath, err)
 	}
 
-	if len(files) <= 1 {
-		return nil, fmt.Errorf("%w: %s", ErrNoFilesToArchive, sourceDir)
+	if content == nil {
+		return nil, fmt.Errorf("%w: %s: %s not found", ErrArchiveRead, archivePath, nameInArchive)
 	}
 
-	return files, nil
+	return content, nil
 }
 
-func (h *Handler) compress(ctx context.Context, targetPath string, files []archives.FileInfo) error {
-	file, err := os.Create(targetPath)
+// Extract unpacks the archive into the target directory.
+// Entries that would end up outside the target directory are rejected.
+func (h *Handler) Extract(ctx context.Context, archivePath, targetDir string) error {
+	file, err := h.openFile(archivePath)
 	if err != nil {
-		return fmt.Errorf("%w: %s: %w", ErrArchiveCreation, targetPath, err)
+		return err
 	}
 	defer file.Close()
 
-	if err := os.Chmod(targetPath, 0o644); err != nil {
-		return fmt.Errorf("failed to set permissions on %s: %w", targetPath, err)
+	format, _ := formatOf(archivePath)
+
+	err = archiveFormat(format, 0).Extract(ctx, file, func(_ context.Context, info archives.FileInfo) error {
+		if !filepath.IsLocal(info.NameInArchive) {
+			return fmt.Errorf("%w: %s", ErrUnsafeArchivePath, info.NameInArchive)
+		}
+
+		target := filepath.Join(targetDir, info.NameInArchive)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0o755) //nolint:wrapcheck
+		case info.Mode().IsRegular():
+			return extractFile(info, target)
+		default:
+			// a bare repository holds no links or devices
+			return nil
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrArchiveRead, archivePath, err)
 	}
 
-	format := archives.CompressedArchive{
-		Compression: archives.Gz{},
-		Archival:    archives.Tar{},
+	return nil
+}
+
+func extractFile(info archives.FileInfo, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	reader, err := info.Open()
+	if err != nil {
+		return err //nolint:wrapcheck
 	}
+	defer reader.Close()
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	_, err = io.Copy(file, reader)
+
+	return errors.Join(err, file.Close())
+}
+
+func (h *Handler) mapFilesToArchive(ctx context.Context, sourceDir, targetName string) ([]archives.FileInfo, error) {
+	files, err := archives.FilesFromDisk(ctx, nil, map[string]string{
+		sourceDir: targetName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to map files at %s to tar archive: %w", sourceDir, err)
+	}
+
+	if len(files) <= 1 {
+		return nil, fmt.Errorf("%w: %s", ErrNoFilesToArchive, sourceDir)
+	}
+
+	return files, nil
+}
 
-	if err := format.Archive(ctx, file, files); err != nil {
+func (h *Handler) compress(ctx context.Context, writer io.Writer, format archives.CompressedArchive, files []archives.FileInfo) error {
+	if err := format.Archive(ctx, writer, files); err != nil {
 		return fmt.Errorf("%w: %w", ErrArchiveCompression, err)
 	}
 
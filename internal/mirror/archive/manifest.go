@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// manifestSuffix is appended to the path of an archive to name its manifest.
+const manifestSuffix = ".manifest.json"
+
+// Manifest records what an archive holds, written next to it when it is created,
+// so a backup can be proven complete and uncorrupted later.
+type Manifest struct {
+	Repository    string              `json:"repository"`           // The name of the archived repository
+	SourceURL     string              `json:"source_url"`           // The URL the repository was mirrored from
+	Archive       string              `json:"archive"`              // The file name of the archive
+	Format        string              `json:"format"`               // The archive format
+	Encryption    *ManifestEncryption `json:"encryption,omitempty"` // How the archive is encrypted, nil when it is not
+	SHA256        string              `json:"sha256"`               // The SHA-256 checksum of the archive file
+	DefaultBranch string              `json:"default_branch"`       // The branch HEAD points at
+	Refs          map[string]string   `json:"refs"`                 // Ref name to the hash it points at
+	ObjectCount   int                 `json:"object_count"`         // The number of git objects in the repository
+	ToolVersion   string              `json:"tool_version"`         // The version of gitprovidersync writing the archive
+	CreatedAt     time.Time           `json:"created_at"`           // When the archive was written
+}
+
+// ManifestEncryption records how an archive is encrypted, without any key material.
+type ManifestEncryption struct {
+	Method       string   `json:"method"`                 // EncryptionX25519 or EncryptionScrypt
+	Fingerprints []string `json:"fingerprints,omitempty"` // The fingerprints of the X25519 recipients
+}
+
+// ManifestPath returns the path of the manifest of the archive at archivePath.
+func ManifestPath(archivePath string) string {
+	return archivePath + manifestSuffix
+}
+
+// NewManifest creates the manifest of the archive at archivePath, holding the bare repository at repoDir.
+func NewManifest(repoDir, archivePath, name, sourceURL, toolVersion string) (Manifest, error) {
+	checksum, err := fileSHA256(archivePath)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	return NewManifestWithChecksum(repoDir, archivePath, checksum, name, sourceURL, toolVersion)
+}
+
+// NewManifestWithChecksum creates the manifest of an archive holding the bare repository at repoDir,
+// with the hex encoded SHA-256 checksum of an archive that is not a local file, such as an uploaded one.
+func NewManifestWithChecksum(repoDir, archivePath, checksum, name, sourceURL, toolVersion string) (Manifest, error) {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	refs, err := repositoryRefs(repo)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	objectCount, err := countObjects(repo)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	format, _ := formatOf(archivePath)
+	manifest := Manifest{
+		Repository:  name,
+		SourceURL:   sourceURL,
+		Archive:     filepath.Base(archivePath),
+		Format:      format,
+		SHA256:      checksum,
+		Refs:        refs,
+		ObjectCount: objectCount,
+		ToolVersion: toolVersion,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if head, err := repo.Reference(plumbing.HEAD, false); err == nil && head.Type() == plumbing.SymbolicReference {
+		manifest.DefaultBranch = head.Target().Short()
+	}
+
+	return manifest, nil
+}
+
+// EncodeManifest returns the manifest as the indented JSON it is stored as.
+func EncodeManifest(manifest Manifest) ([]byte, error) {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// WriteManifest writes the manifest of the archive at archivePath next to it.
+func WriteManifest(archivePath string, manifest Manifest) error {
+	data, err := EncodeManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(ManifestPath(archivePath), data, 0o644); err != nil { //nolint:gosec // the manifest is as readable as its archive
+		return fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	return nil
+}
+
+// ReadManifest reads the manifest of the archive at archivePath.
+func ReadManifest(archivePath string) (Manifest, error) {
+	data, err := os.ReadFile(ManifestPath(archivePath))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %s: %w", ErrManifest, ManifestPath(archivePath), err)
+	}
+
+	return manifest, nil
+}
+
+// repositoryRefs returns the hash refs of the repository, by name.
+func repositoryRefs(repo *git.Repository) (map[string]string, error) {
+	refIter, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	refs := map[string]string{}
+
+	err = refIter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference {
+			refs[ref.Name().String()] = ref.Hash().String()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	return refs, nil
+}
+
+// countObjects returns the number of objects stored in the repository.
+func countObjects(repo *git.Repository) (int, error) {
+	objects, err := repo.Storer.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	count := 0
+
+	err = objects.ForEach(func(_ plumbing.EncodedObject) error {
+		count++
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	return count, nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 checksum of the file at path.
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/gitlib"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const refPrefix = "ref: refs/heads/"
+
+// Reader reads repositories back from archives created by the archive mirror,
+// making an archive directory usable as a sync source to restore from.
+type Reader struct {
+	archiver *Handler
+	ops      *gitlib.Operation
+}
+
+func NewReader() *Reader {
+	return &Reader{archiver: NewHandler(), ops: gitlib.NewOperation()}
+}
+
+// WithEncryption sets the encryption to decrypt encrypted archives with, nil for none.
+func (r *Reader) WithEncryption(encryption *Encryption) *Reader {
+	r.archiver.WithEncryption(encryption)
+
+	return r
+}
+
+// Clone extracts the archive given as clone URL and mirror clones the bare repository inside it.
+// A git bundle is unbundled into a bare repository instead.
+func (r *Reader) Clone(ctx context.Context, opt model.CloneOption) (model.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Archive:Clone")
+	opt.DebugLog(ctx, logger).Msg("Archive:Clone")
+
+	// Extract below the run's temporary directory when there is one, else below the system default
+	parentDir, _ := model.GetTmpDirPath(ctx)
+
+	extractDir, err := os.MkdirTemp(parentDir, "archive.*")
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrDirectoryCreation, err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(extractDir); err != nil {
+			logger.Warn().Err(err).Str("extractDir", extractDir).Msg("failed to remove extracted archive")
+		}
+	}()
+
+	repoDir, err := r.extract(ctx, opt.URL, extractDir)
+	if err != nil {
+		return model.Repository{}, err
+	}
+
+	repo, err := r.ops.CloneLocal(ctx, repoDir)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("failed to clone archived repository: %w", err)
+	}
+
+	return model.NewRepository(repo) //nolint
+}
+
+// extract extracts the archive into extractDir and returns the directory of the bare repository.
+func (r *Reader) extract(ctx context.Context, archivePath, extractDir string) (string, error) {
+	if format, _ := formatOf(archivePath); format == gpsconfig.ArchiveFormatBundle {
+		repoDir := filepath.Join(extractDir, "repository")
+
+		return repoDir, r.archiver.unbundle(archivePath, repoDir)
+	}
+
+	if err := r.archiver.Extract(ctx, archivePath, extractDir); err != nil {
+		return "", err
+	}
+
+	repoDir, err := repositoryDir(extractDir)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", archivePath, err)
+	}
+
+	return repoDir, nil
+}
+
+// HeadBranch returns the branch the HEAD of the archived repository points at.
+func (r *Reader) HeadBranch(ctx context.Context, archivePath string) (string, error) {
+	name, _, ok := ParseTargetPath(archivePath)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoRepository, archivePath)
+	}
+
+	if format, _ := formatOf(archivePath); format == gpsconfig.ArchiveFormatBundle {
+		file, _, refs, err := r.archiver.openBundle(archivePath)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		return bundleHeadBranch(refs), nil
+	}
+
+	head, err := r.archiver.ReadFile(ctx, archivePath, name+"/HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(strings.TrimSpace(string(head)), refPrefix), nil
+}
+
+// repositoryDir returns the single top level directory of an extracted archive, which holds the bare repository.
+func repositoryDir(extractDir string) (string, error) {
+	entries, err := os.ReadDir(extractDir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNoRepository, err)
+	}
+
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return "", ErrNoRepository
+	}
+
+	return filepath.Join(extractDir, entries[0].Name()), nil
+}
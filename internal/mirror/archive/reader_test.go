@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// newBareRepository creates a bare repository like the archive mirror stores,
+// with one commit on the given branch and origin set to the upstream it was cloned from.
+func newBareRepository(t *testing.T, dir, branch string) string {
+	t.Helper()
+
+	upstream := filepath.Join(t.TempDir(), "upstream")
+
+	repo, err := git.PlainInitWithOptions(upstream, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName(branch)},
+	})
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(upstream, "file.txt"), []byte(branch), 0o600))
+
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	_, err = git.PlainClone(dir, true, &git.CloneOptions{URL: upstream, Mirror: true})
+	require.NoError(t, err)
+
+	return upstream
+}
+
+// newArchive archives a bare repository under the file name TargetPath would give it at the given time.
+func newArchive(t *testing.T, targetDir, name, branch string, createdAt time.Time) (string, string) {
+	t.Helper()
+
+	return newFormatArchive(t, NewHandler(), targetDir, name, branch, createdAt, gpsconfig.ArchiveFormatTarGz)
+}
+
+// newFormatArchive archives a bare repository in the given format with the handler.
+func newFormatArchive(t *testing.T, handler *Handler, targetDir, name, branch string, createdAt time.Time, format string) (string, string) {
+	t.Helper()
+
+	repoDir := filepath.Join(t.TempDir(), name)
+	upstream := newBareRepository(t, repoDir, branch)
+
+	archivePath := filepath.Join(targetDir, name+FormatArchiveTimestamp(createdAt)+Extension(format))
+	require.NoError(t, handler.CreateArchive(context.Background(), repoDir, archivePath, name))
+
+	return archivePath, upstream
+}
+
+func TestParseTargetPath(t *testing.T) {
+	createdAt := time.UnixMilli(1718000000123)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantName string
+		wantOK   bool
+	}{
+		{name: "target path", path: TargetPath("tools", "/backup", ""), wantName: "tools", wantOK: true},
+		{name: "name with underscores", path: "/backup/my_tools" + FormatArchiveTimestamp(createdAt) + ".tar.gz", wantName: "my_tools", wantOK: true},
+		{name: "bundle", path: TargetPath("tools", "/backup", gpsconfig.ArchiveFormatBundle), wantName: "tools", wantOK: true},
+		{name: "zstd", path: "/backup/tools" + FormatArchiveTimestamp(createdAt) + ".tar.zst", wantName: "tools", wantOK: true},
+		{name: "encrypted", path: "/backup/tools" + FormatArchiveTimestamp(createdAt) + ".tar.gz.age", wantName: "tools", wantOK: true},
+		{name: "not an archive", path: "/backup/tools.tar.gz"},
+		{name: "other extension", path: "/backup/tools" + FormatArchiveTimestamp(createdAt) + ".zip"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			name, _, ok := ParseTargetPath(tabletest.path)
+			require.Equal(t, tabletest.wantOK, ok)
+			require.Equal(t, tabletest.wantName, name)
+		})
+	}
+
+	_, parsedAt, ok := ParseTargetPath("tools" + FormatArchiveTimestamp(createdAt) + ".tar.gz")
+	require.True(t, ok)
+	require.True(t, createdAt.Equal(parsedAt))
+}
+
+func TestReader_Clone(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	archivePath, upstream := newArchive(t, t.TempDir(), "tools", "trunk", time.Now())
+
+	reader := NewReader()
+
+	branch, err := reader.HeadBranch(ctx, archivePath)
+	require.NoError(err)
+	require.Equal("trunk", branch)
+
+	repo, err := reader.Clone(ctx, model.CloneOption{Name: "tools", URL: archivePath, Mirror: true})
+	require.NoError(err)
+
+	_, err = repo.GoGitRepository().Reference(plumbing.NewBranchReferenceName("trunk"), false)
+	require.NoError(err)
+
+	origin, err := repo.GoGitRepository().Remote(gpsconfig.ORIGIN)
+	require.NoError(err)
+	require.Equal([]string{upstream}, origin.Config().URLs)
+}
+
+func TestReader_CloneFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		handler *Handler
+	}{
+		{name: "tar.gz at level 9", format: gpsconfig.ArchiveFormatTarGz, handler: NewHandler().WithCompressionLevel(9)},
+		{name: "tar.zst", format: gpsconfig.ArchiveFormatTarZst, handler: NewHandler()},
+		{name: "tar.zst at level 19", format: gpsconfig.ArchiveFormatTarZst, handler: NewHandler().WithCompressionLevel(19)},
+		{name: "tar.xz", format: gpsconfig.ArchiveFormatTarXz, handler: NewHandler()},
+		{name: "tar.xz at level 1", format: gpsconfig.ArchiveFormatTarXz, handler: NewHandler().WithCompressionLevel(1)},
+		{name: "bundle", format: gpsconfig.ArchiveFormatBundle, handler: NewHandler()},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			ctx := context.Background()
+			archivePath, _ := newFormatArchive(t, tabletest.handler, t.TempDir(), "tools", "trunk", time.Now(), tabletest.format)
+
+			reader := NewReader()
+
+			branch, err := reader.HeadBranch(ctx, archivePath)
+			require.NoError(t, err)
+			require.Equal(t, "trunk", branch)
+
+			repo, err := reader.Clone(ctx, model.CloneOption{Name: "tools", URL: archivePath, Mirror: true})
+			require.NoError(t, err)
+
+			ref, err := repo.GoGitRepository().Reference(plumbing.NewBranchReferenceName("trunk"), false)
+			require.NoError(t, err)
+
+			_, err = repo.GoGitRepository().CommitObject(ref.Hash())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBundleIsCloneable(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	archivePath, _ := newFormatArchive(t, NewHandler(), t.TempDir(), "tools", "trunk", time.Now(), gpsconfig.ArchiveFormatBundle)
+	cloneDir := filepath.Join(t.TempDir(), "tools")
+
+	output, err := exec.Command("git", "clone", archivePath, cloneDir).CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	content, err := os.ReadFile(filepath.Join(cloneDir, "file.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "trunk", string(content))
+}
+
+func TestReader_CloneMissingArchive(t *testing.T) {
+	_, err := NewReader().Clone(context.Background(), model.CloneOption{URL: filepath.Join(t.TempDir(), "missing.tar.gz")})
+	require.ErrorIs(t, err, ErrArchiveRead)
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// RetentionPolicy decides which archives of a repository are kept. An archive is kept when any rule keeps it,
+// the others are expired. The newest archive is always kept.
+type RetentionPolicy struct {
+	KeepLast    int           // The number of newest archives to keep
+	KeepDaily   int           // The number of most recent days to keep the newest archive of
+	KeepWeekly  int           // The number of most recent ISO weeks to keep the newest archive of
+	KeepMonthly int           // The number of most recent months to keep the newest archive of
+	MaxAge      time.Duration // The age below which all archives are kept, 0 for none
+}
+
+// Archive is an archive of a repository, named by TargetPath.
+type Archive struct {
+	Path      string
+	CreatedAt time.Time
+}
+
+// ListArchives returns the archives of the repository name in dir, in any format, newest first.
+// An empty name lists the archives of every repository.
+func ListArchives(dir, name string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrArchiveRead, dir, err)
+	}
+
+	archives := []Archive{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		archiveName, createdAt, ok := ParseTargetPath(entry.Name())
+		if ok && (name == "" || archiveName == name) {
+			archives = append(archives, Archive{Path: filepath.Join(dir, entry.Name()), CreatedAt: createdAt})
+		}
+	}
+
+	slices.SortFunc(archives, func(a, b Archive) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	return archives, nil
+}
+
+// Expired returns the archives the policy does not keep at now, oldest first.
+// The archives are ordered newest first, as ListArchives returns them.
+func (p RetentionPolicy) Expired(archives []Archive, now time.Time) []Archive {
+	if len(archives) == 0 {
+		return nil
+	}
+
+	kept := make([]bool, len(archives))
+	kept[0] = true
+
+	for index := range min(p.KeepLast, len(archives)) {
+		kept[index] = true
+	}
+
+	keepNewestPer(archives, kept, p.KeepDaily, func(t time.Time) string { return t.Format(time.DateOnly) })
+	keepNewestPer(archives, kept, p.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepNewestPer(archives, kept, p.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") })
+
+	expired := []Archive{}
+
+	for index, archive := range archives {
+		if kept[index] || (p.MaxAge > 0 && now.Sub(archive.CreatedAt) < p.MaxAge) {
+			continue
+		}
+
+		expired = append(expired, archive)
+	}
+
+	slices.Reverse(expired)
+
+	return expired
+}
+
+// keepNewestPer keeps the newest archive of each of the count most recent periods having an archive,
+// the period of an archive given by its key.
+func keepNewestPer(archives []Archive, kept []bool, count int, key func(time.Time) string) {
+	lastKey := ""
+
+	for index, archive := range archives {
+		if count <= 0 {
+			return
+		}
+
+		if periodKey := key(archive.CreatedAt); periodKey != lastKey {
+			kept[index] = true
+			lastKey = periodKey
+			count--
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListArchives(t *testing.T) {
+	dir := t.TempDir()
+	createdAt := time.UnixMilli(1718000000000)
+
+	for _, name := range []string{
+		"tools" + FormatArchiveTimestamp(createdAt) + ".tar.gz",
+		"tools" + FormatArchiveTimestamp(createdAt.Add(time.Hour)) + ".bundle",
+		"tools" + FormatArchiveTimestamp(createdAt.Add(-time.Hour)) + ".tar.zst",
+		"docs" + FormatArchiveTimestamp(createdAt) + ".tar.gz",
+		"tools.tar.gz",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	archives, err := ListArchives(dir, "tools")
+	require.NoError(t, err)
+	require.Len(t, archives, 3)
+	require.Equal(t, filepath.Join(dir, "tools"+FormatArchiveTimestamp(createdAt.Add(time.Hour))+".bundle"), archives[0].Path)
+	require.True(t, createdAt.Add(-time.Hour).Equal(archives[2].CreatedAt))
+}
+
+func TestRetentionPolicy_Expired(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour int) Archive {
+		createdAt := time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+
+		return Archive{Path: createdAt.Format(time.DateTime), CreatedAt: createdAt}
+	}
+
+	// Newest first: two archives today, then yesterday, earlier this week, last week, May and April
+	archives := []Archive{
+		at(time.June, 15, 10), at(time.June, 15, 8), at(time.June, 14, 9), at(time.June, 10, 9),
+		at(time.June, 2, 9), at(time.May, 20, 9), at(time.April, 1, 9),
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []Archive
+	}{
+		{
+			name: "newest is always kept",
+			want: []Archive{archives[6], archives[5], archives[4], archives[3], archives[2], archives[1]},
+		},
+		{
+			name:   "keep last",
+			policy: RetentionPolicy{KeepLast: 2},
+			want:   []Archive{archives[6], archives[5], archives[4], archives[3], archives[2]},
+		},
+		{
+			name:   "keep daily",
+			policy: RetentionPolicy{KeepDaily: 2},
+			want:   []Archive{archives[6], archives[5], archives[4], archives[3], archives[1]},
+		},
+		{
+			name:   "keep weekly",
+			policy: RetentionPolicy{KeepWeekly: 2},
+			want:   []Archive{archives[6], archives[5], archives[3], archives[2], archives[1]},
+		},
+		{
+			name:   "keep monthly",
+			policy: RetentionPolicy{KeepMonthly: 3},
+			want:   []Archive{archives[4], archives[3], archives[2], archives[1]},
+		},
+		{
+			name:   "max age",
+			policy: RetentionPolicy{MaxAge: 24 * time.Hour},
+			want:   []Archive{archives[6], archives[5], archives[4], archives[3], archives[2]},
+		},
+		{
+			name:   "rules combine",
+			policy: RetentionPolicy{KeepLast: 1, KeepMonthly: 2, MaxAge: 200 * time.Hour},
+			want:   []Archive{archives[6], archives[4]},
+		},
+		{
+			name:   "more kept than archives",
+			policy: RetentionPolicy{KeepLast: 10, KeepDaily: 10},
+			want:   []Archive{},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, tabletest.policy.Expired(archives, now))
+		})
+	}
+
+	require.Empty(t, RetentionPolicy{}.Expired(nil, now))
+}
@@ -12,13 +12,15 @@ import (
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/lfs"
 	"itiquette/git-provider-sync/internal/model"
 )
 
 type Service struct {
-	git      GitHandler
-	storage  StorageHandler
-	archiver Handlerer
+	git        GitHandler
+	storage    StorageHandler
+	archiver   Handlerer
+	encryption *Encryption
 }
 
 // Pull implements interfaces.MirrorWriter.
@@ -34,6 +36,13 @@ func NewService(git GitHandler, storage StorageHandler, archiver Handlerer) *Ser
 	}
 }
 
+// WithEncryption sets the encryption the archiver encrypts with, to record it in the manifests.
+func (serv *Service) WithEncryption(encryption *Encryption) *Service {
+	serv.encryption = encryption
+
+	return serv
+}
+
 func (serv *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Archive:Push")
@@ -48,11 +57,30 @@ func (serv *Service) Push(ctx context.Context, repo interfaces.GitRepository, op
 		return fmt.Errorf("failed to initialize target repository: %w", err)
 	}
 
+	if opt.LFSObjectsDir != "" {
+		if err := lfs.CopyObjects(ctx, opt.LFSObjectsDir, lfs.RepositoryStore(storagePath)); err != nil {
+			return fmt.Errorf("failed to store lfs objects: %w", err)
+		}
+	}
+
 	err = serv.archiver.CreateArchive(ctx, storagePath, opt.Target, repo.ProjectInfo().Name(ctx))
 	if err != nil {
 		return errors.New("failed to create archive ")
 	}
 
+	manifest, err := NewManifest(storagePath, opt.Target, repo.ProjectInfo().Name(ctx), repo.ProjectInfo().HTTPSURL, model.CLIOptions(ctx).Version)
+	if err != nil {
+		return err
+	}
+
+	if IsEncrypted(opt.Target) {
+		manifest.Encryption = serv.encryption.ManifestEncryption()
+	}
+
+	if err := WriteManifest(opt.Target, manifest); err != nil {
+		return err
+	}
+
 	err = os.RemoveAll(storagePath)
 	if err != nil {
 		return fmt.Errorf("failed to remove dir %s. err: %w ", storagePath, err)
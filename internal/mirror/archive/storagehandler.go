@@ -10,7 +10,6 @@ import (
 	"itiquette/git-provider-sync/internal/model"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 type StorageHandler struct{}
@@ -20,7 +19,7 @@ func NewStorageHandler() StorageHandler {
 }
 
 func (h StorageHandler) GetStoragePath(_ context.Context, opt model.PushOption) (string, error) {
-	sourceDir := strings.TrimSuffix(opt.Target, ".tar.gz")
+	sourceDir := trimExtension(opt.Target)
 	if err := os.MkdirAll(filepath.Dir(sourceDir), os.ModePerm); err != nil {
 		return "", fmt.Errorf("%w: %s: %w", ErrDirectoryCreation, filepath.Dir(sourceDir), err)
 	}
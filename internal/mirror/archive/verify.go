@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+)
+
+// VerifyResult describes a verified archive.
+type VerifyResult struct {
+	HasManifest bool // Whether the archive has a manifest, without one its checksum is not verified
+	Refs        int  // The number of refs in the archived repository
+	Objects     int  // The number of objects reachable from the refs
+}
+
+// Verify verifies the archive at archivePath. Its checksum is compared with its manifest, when it has one.
+// The archive is then extracted, and every object reachable from the refs of the repository inside must be
+// present and intact, as git fsck checks connectivity. The refs and object count must match the manifest.
+func (r *Reader) Verify(ctx context.Context, archivePath string) (VerifyResult, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Archive:Verify")
+
+	result := VerifyResult{}
+
+	manifest, err := ReadManifest(archivePath)
+
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return result, err
+	default:
+		result.HasManifest = true
+
+		if err := compareChecksum(archivePath, manifest); err != nil {
+			return result, err
+		}
+	}
+
+	// Extract below the run's temporary directory when there is one, else below the system default
+	parentDir, _ := model.GetTmpDirPath(ctx)
+
+	extractDir, err := os.MkdirTemp(parentDir, "verify.*")
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", ErrDirectoryCreation, err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(extractDir); err != nil {
+			logger.Warn().Err(err).Str("extractDir", extractDir).Msg("failed to remove extracted archive")
+		}
+	}()
+
+	repoDir, err := r.extract(ctx, archivePath, extractDir)
+	if err != nil {
+		return result, err
+	}
+
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", ErrNoRepository, err)
+	}
+
+	refs, err := repositoryRefs(repo)
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", ErrCorruptRepository, err)
+	}
+
+	if result.Objects, err = checkConnectivity(repo, refs); err != nil {
+		return result, err
+	}
+
+	result.Refs = len(refs)
+
+	if !result.HasManifest {
+		return result, nil
+	}
+
+	if !maps.Equal(refs, manifest.Refs) {
+		return result, fmt.Errorf("%w: the refs differ from the manifest", ErrCorruptRepository)
+	}
+
+	objectCount, err := countObjects(repo)
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", ErrCorruptRepository, err)
+	}
+
+	if objectCount != manifest.ObjectCount {
+		return result, fmt.Errorf("%w: %d objects, manifest records %d", ErrCorruptRepository, objectCount, manifest.ObjectCount)
+	}
+
+	return result, nil
+}
+
+// VerifyChecksum compares the checksum of the archive at archivePath with its manifest,
+// which proves an archive intact without decrypting or extracting it.
+func VerifyChecksum(archivePath string) error {
+	manifest, err := ReadManifest(archivePath)
+	if err != nil {
+		return err
+	}
+
+	return compareChecksum(archivePath, manifest)
+}
+
+// compareChecksum compares the checksum of the archive at archivePath with the manifest.
+func compareChecksum(archivePath string, manifest Manifest) error {
+	checksum, err := fileSHA256(archivePath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveRead, err)
+	}
+
+	if checksum != manifest.SHA256 {
+		return fmt.Errorf("%w: sha256 %s, manifest records %s", ErrChecksumMismatch, checksum, manifest.SHA256)
+	}
+
+	return nil
+}
+
+// checkConnectivity walks the objects reachable from the refs, checking that each is present, hashes to its name
+// and decodes. The parents of shallow commits and submodule commits are not part of the repository.
+// It returns the number of objects reached.
+func checkConnectivity(repo *git.Repository, refs map[string]string) (int, error) {
+	shallowCommits, err := repo.Storer.Shallow()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrCorruptRepository, err)
+	}
+
+	shallow := map[plumbing.Hash]bool{}
+	for _, hash := range shallowCommits {
+		shallow[hash] = true
+	}
+
+	pending := make([]plumbing.Hash, 0, len(refs))
+	for _, hash := range refs {
+		pending = append(pending, plumbing.NewHash(hash))
+	}
+
+	seen := map[plumbing.Hash]bool{}
+
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if seen[hash] {
+			continue
+		}
+
+		seen[hash] = true
+
+		reachable, err := checkObject(repo, hash, shallow[hash])
+		if err != nil {
+			return 0, fmt.Errorf("%w: object %s: %w", ErrCorruptRepository, hash, err)
+		}
+
+		pending = append(pending, reachable...)
+	}
+
+	return len(seen), nil
+}
+
+// checkObject checks the object named hash and returns the objects it refers to.
+func checkObject(repo *git.Repository, hash plumbing.Hash, shallow bool) ([]plumbing.Hash, error) {
+	encoded, err := repo.Storer.EncodedObject(plumbing.AnyObject, hash)
+	if err != nil {
+		return nil, fmt.Errorf("missing: %w", err)
+	}
+
+	reader, err := encoded.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("unreadable: %w", err)
+	}
+	defer reader.Close()
+
+	hasher := plumbing.NewHasher(encoded.Type(), encoded.Size())
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return nil, fmt.Errorf("unreadable: %w", err)
+	}
+
+	if hasher.Sum() != hash {
+		return nil, errors.New("content does not match its hash")
+	}
+
+	switch encoded.Type() {
+	case plumbing.CommitObject:
+		commit, err := object.DecodeCommit(repo.Storer, encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit: %w", err)
+		}
+
+		if shallow {
+			return []plumbing.Hash{commit.TreeHash}, nil
+		}
+
+		return append([]plumbing.Hash{commit.TreeHash}, commit.ParentHashes...), nil
+	case plumbing.TreeObject:
+		tree, err := object.DecodeTree(repo.Storer, encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tree: %w", err)
+		}
+
+		entries := make([]plumbing.Hash, 0, len(tree.Entries))
+
+		for _, entry := range tree.Entries {
+			if entry.Mode != filemode.Submodule {
+				entries = append(entries, entry.Hash)
+			}
+		}
+
+		return entries, nil
+	case plumbing.TagObject:
+		tag, err := object.DecodeTag(repo.Storer, encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag: %w", err)
+		}
+
+		return []plumbing.Hash{tag.Target}, nil
+	default:
+		return nil, nil
+	}
+}
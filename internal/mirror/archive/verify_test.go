@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+// newManifestArchive archives a bare repository in the format with its manifest, after changing
+// the repository with prepare when it is set.
+func newManifestArchive(t *testing.T, format string, prepare func(repo *git.Repository)) string {
+	t.Helper()
+
+	repoDir := filepath.Join(t.TempDir(), "tools")
+	upstream := newBareRepository(t, repoDir, "main")
+
+	if prepare != nil {
+		repo, err := git.PlainOpen(repoDir)
+		require.NoError(t, err)
+
+		prepare(repo)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "tools"+FormatArchiveTimestamp(time.Now())+Extension(format))
+	require.NoError(t, NewHandler().CreateArchive(context.Background(), repoDir, archivePath, "tools"))
+
+	manifest, err := NewManifest(repoDir, archivePath, "tools", upstream, "test")
+	require.NoError(t, err)
+	require.NoError(t, WriteManifest(archivePath, manifest))
+
+	return archivePath
+}
+
+func TestManifest(t *testing.T) {
+	archivePath := newManifestArchive(t, gpsconfig.ArchiveFormatTarZst, nil)
+
+	manifest, err := ReadManifest(archivePath)
+	require.NoError(t, err)
+	require.Equal(t, "tools", manifest.Repository)
+	require.Equal(t, filepath.Base(archivePath), manifest.Archive)
+	require.Equal(t, gpsconfig.ArchiveFormatTarZst, manifest.Format)
+	require.Equal(t, "main", manifest.DefaultBranch)
+	require.Contains(t, manifest.Refs, "refs/heads/main")
+	require.Equal(t, 3, manifest.ObjectCount)
+	require.Equal(t, "test", manifest.ToolVersion)
+	require.Len(t, manifest.SHA256, 64)
+}
+
+func TestReader_Verify(t *testing.T) {
+	ctx := model.WithCLIOpt(context.Background(), model.CLIOption{})
+	missingCommit := plumbing.NewHash("0123456789012345678901234567890123456789")
+
+	tests := []struct {
+		name            string
+		format          string
+		prepare         func(repo *git.Repository)
+		tamper          func(t *testing.T, archivePath string)
+		wantErr         error
+		wantHasManifest bool
+	}{
+		{
+			name:            "tar.gz",
+			format:          gpsconfig.ArchiveFormatTarGz,
+			wantHasManifest: true,
+		},
+		{
+			name:            "bundle",
+			format:          gpsconfig.ArchiveFormatBundle,
+			wantHasManifest: true,
+		},
+		{
+			name:   "without manifest",
+			format: gpsconfig.ArchiveFormatTarXz,
+			tamper: func(t *testing.T, archivePath string) {
+				t.Helper()
+				require.NoError(t, os.Remove(ManifestPath(archivePath)))
+			},
+		},
+		{
+			name:   "changed archive",
+			format: gpsconfig.ArchiveFormatTarGz,
+			tamper: func(t *testing.T, archivePath string) {
+				t.Helper()
+
+				file, err := os.OpenFile(archivePath, os.O_APPEND|os.O_WRONLY, 0)
+				require.NoError(t, err)
+
+				_, err = file.WriteString("changed")
+				require.NoError(t, err)
+				require.NoError(t, file.Close())
+			},
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name:   "missing object",
+			format: gpsconfig.ArchiveFormatTarGz,
+			prepare: func(repo *git.Repository) {
+				_ = repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/broken", missingCommit))
+			},
+			wantErr: ErrCorruptRepository,
+		},
+		{
+			name:   "refs differ from manifest",
+			format: gpsconfig.ArchiveFormatTarGz,
+			tamper: func(t *testing.T, archivePath string) {
+				t.Helper()
+
+				manifest, err := ReadManifest(archivePath)
+				require.NoError(t, err)
+
+				manifest.Refs["refs/heads/gone"] = missingCommit.String()
+				require.NoError(t, WriteManifest(archivePath, manifest))
+			},
+			wantErr: ErrCorruptRepository,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			archivePath := newManifestArchive(t, tabletest.format, tabletest.prepare)
+			if tabletest.tamper != nil {
+				tabletest.tamper(t, archivePath)
+			}
+
+			result, err := NewReader().Verify(ctx, archivePath)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(t, err, tabletest.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.wantHasManifest, result.HasManifest)
+			require.Equal(t, 1, result.Refs)
+			require.Equal(t, 3, result.Objects)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package cache manages the on-disk cache of bare mirror repositories
+// that are kept between sync runs and updated with incremental fetches.
+//
+// Every cache entry is a bare repository at
+// <cache_dir>/<domain>/<owner>/<name>.git, guarded by an advisory lock file
+// next to it, so concurrent runs never update the same entry at the same time.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// lockPollInterval is how often a held lock is retried while waiting for it.
+const lockPollInterval = 200 * time.Millisecond
+
+// EntryPath returns the path of the cached bare repository for a source repository.
+func EntryPath(cacheDir, domain, owner, name string) string {
+	return filepath.Join(cacheDir, domain, owner, name+".git")
+}
+
+// Exists reports whether a cache entry has been populated.
+func Exists(entryPath string) bool {
+	info, err := os.Stat(filepath.Join(entryPath, "HEAD"))
+
+	return err == nil && !info.IsDir()
+}
+
+// Lock acquires an exclusive lock on a cache entry, creating its parent directories if needed.
+// It waits until the lock is available or ctx is done.
+// The returned function releases the lock and must be called when done updating the entry.
+func Lock(ctx context.Context, entryPath string) (func() error, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering cache:Lock")
+	logger.Debug().Str("entryPath", entryPath).Msg("cache:Lock")
+
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0o750); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreateCacheDir, err)
+	}
+
+	lockPath := entryPath + ".lock"
+
+	waiting := false
+
+	for {
+		unlock, acquired, err := tryLock(lockPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrLockEntry, lockPath, err)
+		}
+
+		if acquired {
+			return unlock, nil
+		}
+
+		if !waiting {
+			logger.Info().Str("lock", lockPath).Msg("Waiting for cache entry lock held by another run")
+
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s: %w", ErrLockEntry, lockPath, ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryPath(t *testing.T) {
+	require.Equal(t, filepath.Join("/cache", "gitlab.com", "owner", "repo.git"), EntryPath("/cache", "gitlab.com", "owner", "repo"))
+}
+
+func TestExists(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, entryPath string)
+		want  bool
+	}{
+		{
+			name:  "missing entry",
+			setup: func(*testing.T, string) {},
+			want:  false,
+		},
+		{
+			name: "entry without HEAD",
+			setup: func(t *testing.T, entryPath string) {
+				t.Helper()
+				require.NoError(t, os.MkdirAll(entryPath, 0o750))
+			},
+			want: false,
+		},
+		{
+			name: "populated entry",
+			setup: func(t *testing.T, entryPath string) {
+				t.Helper()
+				require.NoError(t, os.MkdirAll(entryPath, 0o750))
+				require.NoError(t, os.WriteFile(filepath.Join(entryPath, "HEAD"), []byte("ref: refs/heads/main\n"), 0o600))
+			},
+			want: true,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			entryPath := EntryPath(t.TempDir(), "example.com", "owner", "repo")
+			tabletest.setup(t, entryPath)
+
+			require.Equal(t, tabletest.want, Exists(entryPath))
+		})
+	}
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	entryPath := EntryPath(t.TempDir(), "example.com", "owner", "repo")
+
+	unlock, err := Lock(ctx, entryPath)
+	require.NoError(t, err)
+	require.DirExists(t, filepath.Dir(entryPath))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	_, err = Lock(timeoutCtx, entryPath)
+	require.ErrorIs(t, err, ErrLockEntry)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, unlock())
+
+	unlock, err = Lock(ctx, entryPath)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
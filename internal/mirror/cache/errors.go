@@ -0,0 +1,13 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package cache
+
+import "errors"
+
+var (
+	ErrCreateCacheDir = errors.New("failed to create cache directory")
+	ErrLockEntry      = errors.New("failed to lock cache entry")
+	ErrUnlockEntry    = errors.New("failed to unlock cache entry")
+)
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+//go:build !unix
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// tryLock creates lockPath exclusively.
+// Unlike the flock based lock, a lock file left behind by a crashed run must be removed by hand.
+func tryLock(lockPath string) (func() error, bool, error) {
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, false, nil
+		}
+
+		return nil, false, err //nolint:wrapcheck
+	}
+
+	file.Close()
+
+	unlock := func() error {
+		if err := os.Remove(lockPath); err != nil {
+			return fmt.Errorf("%w: %w", ErrUnlockEntry, err)
+		}
+
+		return nil
+	}
+
+	return unlock, true, nil
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+//go:build unix
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// tryLock takes a non-blocking flock on lockPath.
+// The lock is released by the kernel if the process dies, so stale locks cannot occur.
+func tryLock(lockPath string) (func() error, bool, error) {
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, false, err //nolint:wrapcheck
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+
+		return nil, false, err //nolint:wrapcheck
+	}
+
+	unlock := func() error {
+		defer file.Close()
+
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+			return fmt.Errorf("%w: %w", ErrUnlockEntry, err)
+		}
+
+		return nil
+	}
+
+	return unlock, true, nil
+}
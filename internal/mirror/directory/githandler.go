@@ -31,7 +31,7 @@ func (h *GitHandler) InitializeRepository(ctx context.Context, targetDir string,
 		return fmt.Errorf("%w: %w", ErrRepoInitialization, err)
 	}
 
-	pushOpt := model.NewPushOption(targetDir, false, true, gpsconfig.AuthConfig{})
+	pushOpt := model.NewPushOption(targetDir, nil, false, true, gpsconfig.AuthConfig{})
 	if err := h.client.Push(ctx, repo, pushOpt); err != nil {
 		return fmt.Errorf("%w: %w", ErrPushRepository, err)
 	}
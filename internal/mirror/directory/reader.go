@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package directory
+
+import (
+	"context"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/gitlib"
+	"itiquette/git-provider-sync/internal/model"
+)
+
+// Reader reads repositories back from a directory mirror,
+// making the directory usable as a sync source to restore from.
+type Reader struct {
+	ops *gitlib.Operation
+}
+
+func NewReader() *Reader {
+	return &Reader{ops: gitlib.NewOperation()}
+}
+
+// Clone mirror clones the repository directory given as clone URL.
+func (r *Reader) Clone(ctx context.Context, opt model.CloneOption) (model.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Directory:Clone")
+	opt.DebugLog(ctx, logger).Msg("Directory:Clone")
+
+	repo, err := r.ops.CloneLocal(ctx, opt.URL)
+	if err != nil {
+		return model.Repository{}, err //nolint:wrapcheck
+	}
+
+	return model.NewRepository(repo) //nolint
+}
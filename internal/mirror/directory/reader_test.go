@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package directory
+
+import (
+	"path/filepath"
+	"testing"
+
+	"itiquette/git-provider-sync/internal/mirror/gitlib"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_Clone(t *testing.T) {
+	require := require.New(t)
+	repoName := "readertestrepo"
+
+	bareRepo, err := createTmpGitBareRepo(t.TempDir(), repoName)
+	require.NoError(err)
+
+	bareRepository, err := model.NewRepository(bareRepo)
+	require.NoError(err)
+
+	bareRepository.ProjectMetaInfo = &model.ProjectInfo{DefaultBranch: "main"}
+
+	// Mirror to a directory first, then read the mirror back
+	mirrorDir := filepath.Join(t.TempDir(), repoName)
+	handler := NewGitHandler(gitlib.NewService())
+	require.NoError(handler.InitializeRepository(testContext(), mirrorDir, bareRepository))
+
+	repo, err := NewReader().Clone(testContext(), model.CloneOption{Name: repoName, URL: mirrorDir, Mirror: true})
+	require.NoError(err)
+
+	_, err = repo.GoGitRepository().Reference(plumbing.NewBranchReferenceName("main"), false)
+	require.NoError(err)
+
+	origin, err := repo.GoGitRepository().Remote(gpsconfig.ORIGIN)
+	require.NoError(err)
+	require.Equal([]string{"https://origin.dot/" + repoName + ".git"}, origin.Config().URLs)
+}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/lfs"
 	"itiquette/git-provider-sync/internal/model"
+	"path/filepath"
 )
 
 type Service struct {
@@ -36,7 +38,15 @@ func (serv *Service) Push(ctx context.Context, repo interfaces.GitRepository, op
 
 	cliOpt := model.CLIOptions(ctx)
 	if cliOpt.ForcePush || !serv.storage.DirectoryExists(targetDir) {
-		return serv.gitHandler.InitializeRepository(ctx, targetDir, repo)
+		if err := serv.gitHandler.InitializeRepository(ctx, targetDir, repo); err != nil {
+			return err
+		}
+	}
+
+	if opt.LFSObjectsDir != "" {
+		if err := lfs.CopyObjects(ctx, opt.LFSObjectsDir, lfs.RepositoryStore(filepath.Join(targetDir, ".git"))); err != nil {
+			return fmt.Errorf("failed to store lfs objects: %w", err)
+		}
 	}
 
 	return nil
@@ -53,6 +63,8 @@ func (serv *Service) Pull(ctx context.Context, opt model.PullOption) error {
 	}
 
 	pullOpt := model.NewPullOption("", "", opt.SyncCfg, opt.SyncCfg.Auth, targetDir, targetDir)
+	pullOpt.Prune = opt.Prune
+	pullOpt.PruneDryRun = opt.PruneDryRun
 	if err := serv.gitHandler.PullToDir(ctx, pullOpt); err != nil {
 		return fmt.Errorf("%w: targetDir: %s: %w", ErrPullRepository, targetDir, err)
 	}
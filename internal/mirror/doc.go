@@ -10,5 +10,7 @@
 //
 // - compressed archives
 //
+// - archives in S3-compatible object storage
+//
 // - regular directory cloning.
 package mirror
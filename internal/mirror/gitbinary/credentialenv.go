@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitbinary
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/githubapp"
+)
+
+const (
+	// gitUsernameEnv and gitPasswordEnv carry the credentials to the credential helper,
+	// keeping them off command lines, remote URLs and disk.
+	gitUsernameEnv = "GPS_GIT_USERNAME"
+	gitPasswordEnv = "GPS_GIT_PASSWORD"
+
+	// sourceGitUsernameEnv and sourceGitPasswordEnv carry the source credentials
+	// a partial clone fetches its missing objects with while pushing.
+	sourceGitUsernameEnv = "GPS_SOURCE_GIT_USERNAME"
+	sourceGitPasswordEnv = "GPS_SOURCE_GIT_PASSWORD"
+
+	// credentialHelper answers git's credential requests from the environment and ignores store and erase requests.
+	credentialHelper       = `!f() { test "$1" = get && printf 'username=%s\npassword=%s\n' "$` + gitUsernameEnv + `" "$` + gitPasswordEnv + `"; }; f`
+	sourceCredentialHelper = `!f() { test "$1" = get && printf 'username=%s\npassword=%s\n' "$` + sourceGitUsernameEnv + `" "$` + sourceGitPasswordEnv + `"; }; f`
+
+	defaultGitUsername = "anyuser"
+)
+
+// gitEnv returns the environment git runs with to reach the remote at remoteURL:
+// the SSH settings of the auth configuration and, for HTTPS, a credential helper answering with its token.
+func gitEnv(ctx context.Context, authCfg gpsconfig.AuthConfig, remoteURL string) ([]string, error) {
+	env, err := sshEnv(ctx, authCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(authCfg.Protocol, gpsconfig.SSH) {
+		return env, nil
+	}
+
+	username, password, err := httpsCredentials(ctx, authCfg)
+	if err != nil || password == "" {
+		return env, err
+	}
+
+	return credentialEnv(env, remoteURL, username, password), nil
+}
+
+// promisorEnv adds the source credentials to the environment a partial clone is pushed with,
+// as git fetches the objects the clone left out from the source while pushing.
+// Git asks the credential helpers per host, so a source on the host of the target
+// is answered with the credentials of the target.
+func promisorEnv(ctx context.Context, env []string, authCfg gpsconfig.AuthConfig, sourceURL, targetURL string) ([]string, error) {
+	if strings.EqualFold(authCfg.Protocol, gpsconfig.SSH) || credentialKey(sourceURL) == credentialKey(targetURL) {
+		return env, nil
+	}
+
+	username, password, err := httpsCredentials(ctx, authCfg)
+	if err != nil || password == "" {
+		return env, err
+	}
+
+	env = appendGitConfig(env, credentialKey(sourceURL), "")
+	env = appendGitConfig(env, credentialKey(sourceURL), sourceCredentialHelper)
+
+	return append(env,
+		sourceGitUsernameEnv+"="+username,
+		sourceGitPasswordEnv+"="+password,
+	), nil
+}
+
+// httpsCredentials returns the username and token git authenticates with over HTTPS, the token is empty without one.
+func httpsCredentials(ctx context.Context, authCfg gpsconfig.AuthConfig) (string, string, error) {
+	authCfg, err := githubapp.ResolveAuth(ctx, authCfg)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	username := defaultGitUsername
+	if authCfg.Username != "" {
+		username = authCfg.Username
+	}
+
+	return username, authCfg.Token, nil
+}
+
+// credentialEnv adds an in-memory credential helper to env. It replaces the credential helpers
+// configured for the remote's host, so neither a stored credential is used nor the token stored.
+func credentialEnv(env []string, remoteURL, username, password string) []string {
+	key := credentialKey(remoteURL)
+
+	// An empty helper clears the helpers configured before it
+	env = appendGitConfig(env, key, "")
+	env = appendGitConfig(env, key, credentialHelper)
+
+	return append(env,
+		"GIT_TERMINAL_PROMPT=0",
+		gitUsernameEnv+"="+username,
+		gitPasswordEnv+"="+password,
+	)
+}
+
+// credentialKey returns the configuration key of the credential helpers for the host of remoteURL.
+func credentialKey(remoteURL string) string {
+	if parsedURL, err := url.Parse(remoteURL); err == nil && parsedURL.Host != "" && strings.HasPrefix(parsedURL.Scheme, "http") {
+		return "credential." + parsedURL.Scheme + "://" + parsedURL.Host + ".helper"
+	}
+
+	return "credential.helper"
+}
+
+// appendGitConfig adds a configuration entry to the GIT_CONFIG_COUNT entries of env.
+func appendGitConfig(env []string, key, value string) []string {
+	count := 0
+	countIndex := -1
+
+	for index, variable := range env {
+		if countValue, found := strings.CutPrefix(variable, "GIT_CONFIG_COUNT="); found {
+			count, _ = strconv.Atoi(countValue)
+			countIndex = index
+		}
+	}
+
+	countVariable := "GIT_CONFIG_COUNT=" + strconv.Itoa(count+1)
+	if countIndex >= 0 {
+		env[countIndex] = countVariable
+	} else {
+		env = append(env, countVariable)
+	}
+
+	return append(env,
+		"GIT_CONFIG_KEY_"+strconv.Itoa(count)+"="+key,
+		"GIT_CONFIG_VALUE_"+strconv.Itoa(count)+"="+value,
+	)
+}
+
+// withoutPassword removes the credentials embedded in an HTTP(S) URL, and keeps the user of an SSH URL.
+func withoutPassword(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.User == nil {
+		return rawURL
+	}
+
+	if _, hasPassword := parsedURL.User.Password(); !hasPassword {
+		return rawURL
+	}
+
+	parsedURL.User = nil
+
+	return parsedURL.String()
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package gitbinary
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+func TestGitEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		authCfg    gpsconfig.AuthConfig
+		remoteURL  string
+		wantHelper bool
+	}{
+		{
+			name:       "https token",
+			authCfg:    gpsconfig.AuthConfig{Protocol: gpsconfig.HTTPS, Token: "secret"},
+			remoteURL:  "https://gitlab.com/owner/repo.git",
+			wantHelper: true,
+		},
+		{
+			name:      "no token",
+			authCfg:   gpsconfig.AuthConfig{Protocol: gpsconfig.HTTPS},
+			remoteURL: "https://gitlab.com/owner/repo.git",
+		},
+		{
+			name:      "ssh",
+			authCfg:   gpsconfig.AuthConfig{Protocol: gpsconfig.SSH, Token: "secret"},
+			remoteURL: "git@gitlab.com:owner/repo.git",
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			env, err := gitEnv(testContext(t.TempDir()), tabletest.authCfg, tabletest.remoteURL)
+			require.NoError(t, err)
+			require.Equal(t, tabletest.wantHelper, strings.Contains(strings.Join(env, "\n"), credentialHelper))
+
+			for _, variable := range env {
+				require.NotContains(t, variable, "secret@")
+			}
+		})
+	}
+}
+
+func TestCredentialEnv(t *testing.T) {
+	require := require.New(t)
+
+	// The SSH URL rewrite entries are kept, the helper entries follow them
+	env := SetupSSHCommandEnv("ssh", "https://gitlab.com/", "git@gitlab.com:")
+	env = credentialEnv(env, "https://gitlab.com/owner/repo.git", "user", "it's secret")
+	require.Contains(env, "GIT_CONFIG_COUNT=3")
+	require.Contains(env, "GIT_CONFIG_KEY_0=url.git@gitlab.com:.insteadOf")
+	require.Contains(env, "GIT_CONFIG_KEY_2=credential.https://gitlab.com.helper")
+
+	gitBinary, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git binary not found")
+	}
+
+	// git asks the helper, which answers with the credentials handed to it in the environment
+	cmd := exec.Command(gitBinary, "credential", "fill")
+	cmd.Env = append(os.Environ(), append(env, "HOME="+t.TempDir(), "GIT_CONFIG_NOSYSTEM=1")...)
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=gitlab.com\n\n")
+
+	output, err := cmd.Output()
+	require.NoError(err)
+	require.Contains(string(output), "username=user\n")
+	require.Contains(string(output), "password=it's secret\n")
+}
+
+func TestPromisorEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		authCfg    gpsconfig.AuthConfig
+		sourceURL  string
+		targetURL  string
+		wantHelper bool
+	}{
+		{
+			name:       "source on another host",
+			authCfg:    gpsconfig.AuthConfig{Protocol: gpsconfig.HTTPS, Token: "secret"},
+			sourceURL:  "https://gitlab.com/owner/repo.git",
+			targetURL:  "https://github.com/owner/repo.git",
+			wantHelper: true,
+		},
+		{
+			name:      "source on the target host",
+			authCfg:   gpsconfig.AuthConfig{Protocol: gpsconfig.HTTPS, Token: "secret"},
+			sourceURL: "https://gitlab.com/owner/repo.git",
+			targetURL: "https://gitlab.com/mirror/repo.git",
+		},
+		{
+			name:      "no token",
+			authCfg:   gpsconfig.AuthConfig{Protocol: gpsconfig.HTTPS},
+			sourceURL: "https://gitlab.com/owner/repo.git",
+			targetURL: "https://github.com/owner/repo.git",
+		},
+		{
+			name:      "ssh",
+			authCfg:   gpsconfig.AuthConfig{Protocol: gpsconfig.SSH, Token: "secret"},
+			sourceURL: "git@gitlab.com:owner/repo.git",
+			targetURL: "https://github.com/owner/repo.git",
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			env, err := promisorEnv(testContext(t.TempDir()), []string{}, tabletest.authCfg, tabletest.sourceURL, tabletest.targetURL)
+			require.NoError(t, err)
+			require.Equal(t, tabletest.wantHelper, slices.Contains(env, "GIT_CONFIG_VALUE_1="+sourceCredentialHelper))
+		})
+	}
+}
@@ -8,6 +8,7 @@ import "errors"
 
 var (
 	ErrBranchCheckout      = errors.New("failed to checkout branch")
+	ErrCacheEntry          = errors.New("failed to update cache entry")
 	ErrCloneRepository     = errors.New("failed to clone repository")
 	ErrPullRepository      = errors.New("failed to pull repository")
 	ErrNewRepositoryModel  = errors.New("failed to create new model.repository")
@@ -27,5 +28,6 @@ var (
 	ErrPermissionDenied    = errors.New("failed with permission denied (publickey). Provide correct key in your ssh-agent")
 	ErrSetRepositoryConfig = errors.New("failed to set repository config")
 	ErrGetRemoteBranches   = errors.New("failed to get remote branches")
+	ErrListRefs            = errors.New("failed to list remote refs")
 	ErrTmpDirPath          = errors.New("failed to get tmpdirpath")
 )
@@ -52,8 +52,10 @@ func (e *executorService) RunGitCommand(ctx context.Context, env []string, worki
 	return nil
 }
 
-func (e *executorService) RunGitCommandWithOutput(ctx context.Context, workingDir string, args ...string) ([]byte, error) {
+func (e *executorService) RunGitCommandWithOutput(ctx context.Context, env []string, workingDir string, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, e.gitBinaryPath, args...) //nolint:gosec
+	cmd.Env = append(os.Environ(), env...)
+
 	if len(workingDir) != 0 {
 		cmd.Dir = workingDir
 	}
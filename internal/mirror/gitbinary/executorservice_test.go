@@ -198,7 +198,7 @@ func TestExecutorService_RunGitCommandWithOutput(t *testing.T) {
 			}
 
 			executor := NewExecutorService(tabltest.binary)
-			output, err := executor.RunGitCommandWithOutput(ctx, tabltest.workingDir, tabltest.args...)
+			output, err := executor.RunGitCommandWithOutput(ctx, nil, tabltest.workingDir, tabltest.args...)
 
 			if tabltest.wantErr {
 				require.Error(t, err)
@@ -252,7 +252,7 @@ func TestExecutorService_ResourceCleanup(t *testing.T) {
 	for _, tabletest := range tests {
 		t.Run(tabletest.name, func(t *testing.T) {
 			executor, ctx := tabletest.setup(t)
-			_, _ = executor.RunGitCommandWithOutput(ctx, "", "")
+			_, _ = executor.RunGitCommandWithOutput(ctx, nil, "", "")
 
 			if tabletest.validate != nil {
 				tabletest.validate(t, ctx)
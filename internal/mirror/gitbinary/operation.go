@@ -13,7 +13,7 @@ import (
 
 type ExecutorService interface {
 	RunGitCommand(ctx context.Context, env []string, workingDir string, args ...string) error
-	RunGitCommandWithOutput(ctx context.Context, workingDir string, args ...string) ([]byte, error)
+	RunGitCommandWithOutput(ctx context.Context, env []string, workingDir string, args ...string) ([]byte, error)
 }
 
 type operation struct {
@@ -26,7 +26,9 @@ func NewOperation(executor ExecutorService) *operation {
 	}
 }
 
-func (b *operation) Fetch(ctx context.Context, targetPath string) error {
+// Fetch fetches and pulls all branches of the repository at targetPath, running git with env,
+// and creates local tracking branches for them.
+func (b *operation) Fetch(ctx context.Context, env []string, targetPath string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Fetch")
 	logger.Debug().Str("targetPath", targetPath).Msg("Fetch")
@@ -37,7 +39,7 @@ func (b *operation) Fetch(ctx context.Context, targetPath string) error {
 	}
 
 	for _, cmd := range commands {
-		if err := b.executor.RunGitCommand(ctx, nil, targetPath, cmd...); err != nil {
+		if err := b.executor.RunGitCommand(ctx, env, targetPath, cmd...); err != nil {
 			return err //nolint
 		}
 	}
@@ -50,7 +52,7 @@ func (b *operation) CreateTrackingBranches(ctx context.Context, targetPath strin
 	logger.Trace().Msg("Entering CreateTrackingBranches")
 	logger.Debug().Str("targetPath", targetPath).Msg("CreateTrackingBranches")
 
-	output, err := b.executor.RunGitCommandWithOutput(ctx, targetPath, "branch", "-r")
+	output, err := b.executor.RunGitCommandWithOutput(ctx, nil, targetPath, "branch", "-r")
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrGetRemoteBranches, err)
 	}
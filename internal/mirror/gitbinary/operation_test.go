@@ -39,8 +39,8 @@ func (m *mockExecutor) RunGitCommand(ctx context.Context, env []string, workingD
 	return m.Called(callArgs...).Error(0)
 }
 
-func (m *mockExecutor) RunGitCommandWithOutput(ctx context.Context, workingDir string, args ...string) ([]byte, error) {
-	callArgs := append([]interface{}{ctx, workingDir}, toInterfaces(args)...)
+func (m *mockExecutor) RunGitCommandWithOutput(ctx context.Context, env []string, workingDir string, args ...string) ([]byte, error) {
+	callArgs := append([]interface{}{ctx, env, workingDir}, toInterfaces(args)...)
 	call := m.Called(callArgs...)
 
 	return call.Get(0).([]byte), call.Error(1) //nolint
@@ -79,7 +79,7 @@ func TestOperation_Fetch(t *testing.T) {
 				mockE.On("RunGitCommand", mock.Anything, []string(nil), testPath, "pull", "--all").
 					Return(nil)
 				// Get branches
-				mockE.On("RunGitCommandWithOutput", mock.Anything, testPath, "branch", "-r").
+				mockE.On("RunGitCommandWithOutput", mock.Anything, []string(nil), testPath, "branch", "-r").
 					Return([]byte("origin/main\norigin/develop"), nil)
 				// Create tracking branches
 				mockE.On("RunGitCommand", mock.Anything, []string(nil), testPath, "branch", "--track", "main", "origin/main").
@@ -129,7 +129,7 @@ func TestOperation_Fetch(t *testing.T) {
 			}
 
 			op := NewOperation(mockE)
-			err := op.Fetch(context.Background(), tabletest.targetPath)
+			err := op.Fetch(context.Background(), nil, tabletest.targetPath)
 
 			if tabletest.expectError != nil {
 				require.Error(t, err)
@@ -163,7 +163,7 @@ func TestOperation_CreateTrackingBranches(t *testing.T) {
 			name:       "create tracking branches for multiple remotes",
 			targetPath: testPath,
 			setupMock: func(m *mockExecutor) {
-				m.On("RunGitCommandWithOutput", mock.Anything, testPath, "branch", "-r").
+				m.On("RunGitCommandWithOutput", mock.Anything, []string(nil), testPath, "branch", "-r").
 					Return([]byte("origin/main\norigin/develop\nupstream/main"), nil)
 				m.On("RunGitCommand", mock.Anything, []string(nil), testPath, "branch", "--track", mock.Anything, mock.Anything).
 					Return(nil)
@@ -180,7 +180,7 @@ func TestOperation_CreateTrackingBranches(t *testing.T) {
 			name:       "handle detached HEAD reference",
 			targetPath: testPath,
 			setupMock: func(m *mockExecutor) {
-				m.On("RunGitCommandWithOutput", mock.Anything, testPath, "branch", "-r").
+				m.On("RunGitCommandWithOutput", mock.Anything, []string(nil), testPath, "branch", "-r").
 					Return([]byte("origin/HEAD -> origin/main\norigin/develop"), nil)
 				m.On("RunGitCommand", mock.Anything, []string(nil), testPath, "branch", "--track", "develop", "origin/develop").
 					Return(nil)
@@ -193,7 +193,7 @@ func TestOperation_CreateTrackingBranches(t *testing.T) {
 			name:       "handle no remote branches",
 			targetPath: testPath,
 			setupMock: func(m *mockExecutor) {
-				m.On("RunGitCommandWithOutput", mock.Anything, testPath, "branch", "-r").
+				m.On("RunGitCommandWithOutput", mock.Anything, []string(nil), testPath, "branch", "-r").
 					Return([]byte(""), nil)
 				m.On("RunGitCommand", mock.Anything, []string(nil), testPath, "branch", "--track", mock.Anything, mock.Anything).
 					Return(nil)
@@ -204,7 +204,7 @@ func TestOperation_CreateTrackingBranches(t *testing.T) {
 			name:       "handle remote unreachable",
 			targetPath: testPath,
 			setupMock: func(m *mockExecutor) {
-				m.On("RunGitCommandWithOutput", mock.Anything, testPath, "branch", "-r").
+				m.On("RunGitCommandWithOutput", mock.Anything, []string(nil), testPath, "branch", "-r").
 					Return([]byte{}, errRemoteUnreach)
 			},
 			expectError: ErrGetRemoteBranches,
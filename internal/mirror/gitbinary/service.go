@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/cache"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 	"itiquette/git-provider-sync/internal/provider/stringconvert"
@@ -49,7 +50,10 @@ func (g *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 	logger.Trace().Msg("Entering Clone")
 	opt.DebugLog(ctx, logger).Msg("Clone")
 
-	env := SetupSSHCommandEnv(opt.AuthCfg.SSHCommand, opt.AuthCfg.SSHURLRewriteFrom, opt.AuthCfg.SSHURLRewriteTo)
+	env, err := gitEnv(ctx, opt.AuthCfg, opt.URL)
+	if err != nil {
+		return model.Repository{}, err
+	}
 
 	tmpDirPath, err := model.GetTmpDirPath(ctx)
 	if err != nil {
@@ -59,9 +63,28 @@ func (g *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 	destinationDir := filepath.Join(tmpDirPath, opt.Name)
 	parentDir := filepath.Dir(destinationDir)
 
-	cloneURL := g.prepareCloneURL(ctx, opt)
+	cloneURL := opt.URL
+	cloneSource := cloneURL
+
+	if opt.SourceCfg.CacheDir != "" {
+		entryPath, unlock, err := g.updateCacheEntry(ctx, env, opt, cloneURL)
+		if err != nil {
+			return model.Repository{}, err
+		}
+
+		// The entry stays locked until it is cloned, so another run's fetch can not prune it meanwhile
+		defer func() {
+			if err := unlock(); err != nil {
+				logger.Warn().Err(err).Str("entryPath", entryPath).Msg("failed to unlock cache entry")
+			}
+		}()
+
+		cloneSource = entryPath
+	}
 
-	if err := g.executorService.RunGitCommand(ctx, env, parentDir, "clone", cloneURL, destinationDir); err != nil {
+	args := append(append([]string{"clone"}, cloneModeArgs(opt.SourceCfg.CloneMode)...), cloneSource, destinationDir)
+
+	if err := g.executorService.RunGitCommand(ctx, env, parentDir, args...); err != nil {
 		if strings.Contains(err.Error(), "Permission denied (publickey)") {
 			return model.Repository{}, ErrPermissionDenied
 		}
@@ -69,24 +92,131 @@ func (g *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
 	}
 
-	if err := g.branchService.Fetch(ctx, destinationDir); err != nil {
+	if err := g.branchService.Fetch(ctx, env, destinationDir); err != nil {
 		logger.Warn().Err(err).Msg("fetch after clone failed")
 	}
 
+	// Refs beyond branches and tags that mirrors push, such as notes, are not part of a clone
+	if patterns := opt.SourceCfg.MirroredRefPatterns(); len(patterns) > 0 {
+		args := []string{"fetch", "--update-head-ok", "origin"}
+		for _, pattern := range patterns {
+			args = append(args, "+"+pattern+":"+pattern)
+		}
+
+		if err := g.executorService.RunGitCommand(ctx, env, destinationDir, args...); err != nil {
+			return model.Repository{}, fmt.Errorf("%w: %w", ErrFetchBranches, err)
+		}
+	}
+
 	return g.finalizeClone(ctx, destinationDir, cloneURL, opt.SourceCfg.ProviderType)
 }
 
-func (g *Service) prepareCloneURL(ctx context.Context, opt model.CloneOption) string {
+// cloneModeArgs returns the clone arguments of a source clone mode. A shallow clone has the tip commit
+// of every branch, a blobless clone leaves out the file contents and a treeless clone the directories as well.
+func cloneModeArgs(cloneMode string) []string {
+	switch cloneMode {
+	case gpsconfig.CloneModeShallow:
+		return []string{"--depth", "1", "--no-single-branch"}
+	case gpsconfig.CloneModeBlobless:
+		return []string{"--filter=blob:none"}
+	case gpsconfig.CloneModeTreeless:
+		return []string{"--filter=tree:0"}
+	default:
+		return []string{}
+	}
+}
+
+// updateCacheEntry locks the bare mirror repository kept in the cache directory and brings it up to date,
+// cloning it on first use and fetching incrementally on later runs. It returns the path of the cache entry,
+// and the function unlocking it, which the caller calls once it has cloned the entry.
+func (g *Service) updateCacheEntry(ctx context.Context, env []string, opt model.CloneOption, cloneURL string) (string, func() error, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering updateCacheEntry")
+
+	entryPath := cache.EntryPath(opt.SourceCfg.CacheDir, opt.SourceCfg.GetDomain(), opt.SourceCfg.Owner, opt.Name)
+
+	unlock, err := cache.Lock(ctx, entryPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrCacheEntry, err)
+	}
+
+	if err := g.fetchCacheEntry(ctx, env, entryPath, cloneURL); err != nil {
+		if unlockErr := unlock(); unlockErr != nil {
+			logger.Warn().Err(unlockErr).Str("entryPath", entryPath).Msg("failed to unlock cache entry")
+		}
+
+		return "", nil, err
+	}
+
+	return entryPath, unlock, nil
+}
+
+// fetchCacheEntry clones the cache entry at entryPath on first use, or fetches into it on later runs.
+// The caller holds the lock of the entry.
+func (g *Service) fetchCacheEntry(ctx context.Context, env []string, entryPath, cloneURL string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering fetchCacheEntry")
+
+	if !cache.Exists(entryPath) {
+		logger.Debug().Str("entryPath", entryPath).Msg("Populating cache entry")
+
+		if err := g.executorService.RunGitCommand(ctx, env, filepath.Dir(entryPath), "clone", "--mirror", cloneURL, entryPath); err != nil {
+			if strings.Contains(err.Error(), "Permission denied (publickey)") {
+				return ErrPermissionDenied
+			}
+
+			return fmt.Errorf("%w: %w", ErrCloneRepository, err)
+		}
+
+		return nil
+	}
+
+	logger.Debug().Str("entryPath", entryPath).Msg("Fetching into cache entry")
+
+	if err := g.executorService.RunGitCommand(ctx, env, entryPath, "fetch", "--prune", cloneURL, "+refs/*:refs/*"); err != nil {
+		if strings.Contains(err.Error(), "Permission denied (publickey)") {
+			return ErrPermissionDenied
+		}
+
+		return fmt.Errorf("%w: %w", ErrFetchBranches, err)
+	}
+
+	return nil
+}
+
+// ListRefs lists the refs of the source repository without cloning it, using git ls-remote.
+func (g *Service) ListRefs(ctx context.Context, opt model.CloneOption) (map[string]string, error) {
 	logger := log.Logger(ctx)
-	logger.Trace().Msg("Entering prepareCloneURL")
-	opt.DebugLog(ctx, logger).Msg("prepareCloneURL")
+	logger.Trace().Msg("Entering ListRefs")
+	opt.DebugLog(ctx, logger).Msg("ListRefs")
+
+	env, err := gitEnv(ctx, opt.AuthCfg, opt.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := g.executorService.RunGitCommandWithOutput(ctx, env, "", "ls-remote", "--refs", opt.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrListRefs, stringconvert.RemoveBasicAuthFromURL(ctx, opt.URL, true), err)
+	}
+
+	return parseLsRemote(string(output)), nil
+}
+
+// parseLsRemote parses git ls-remote output into commit hashes keyed by ref name.
+func parseLsRemote(output string) map[string]string {
+	tips := map[string]string{}
 
-	url := opt.URL
-	if !strings.EqualFold(opt.SourceCfg.Auth.Protocol, gpsconfig.SSH) {
-		url = stringconvert.AddBasicAuthToURL(ctx, opt.URL, "anyuser", opt.AuthCfg.Token)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		hash, ref, found := strings.Cut(line, "\t")
+		if !found || !strings.HasPrefix(ref, "refs/") || strings.HasSuffix(ref, "^{}") {
+			continue
+		}
+
+		tips[strings.TrimSpace(ref)] = strings.TrimSpace(hash)
 	}
 
-	return url
+	return tips
 }
 
 func (g *Service) finalizeClone(ctx context.Context, destinationDir, cloneURL, gitType string) (model.Repository, error) {
@@ -133,13 +263,16 @@ func (g *Service) Pull(ctx context.Context, opt model.PullOption) error {
 	logger.Trace().Msg("Entering Pull")
 	opt.DebugLog(logger).Str("pullDirPath", opt.Path).Msg("Pull")
 
-	env := SetupSSHCommandEnv(opt.AuthCfg.SSHCommand, opt.AuthCfg.SSHURLRewriteFrom, opt.AuthCfg.SSHURLRewriteTo)
+	env, err := gitEnv(ctx, opt.AuthCfg, opt.URL)
+	if err != nil {
+		return err
+	}
 
 	if err := g.executorService.RunGitCommand(ctx, env, opt.Path, "pull"); err != nil {
 		return fmt.Errorf("%w: %w", ErrPullRepository, err)
 	}
 
-	return g.branchService.Fetch(ctx, opt.Path)
+	return g.branchService.Fetch(ctx, env, opt.Path)
 }
 
 func (g *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption) error {
@@ -147,8 +280,26 @@ func (g *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt m
 	logger.Trace().Msg("Entering Push")
 	opt.DebugLog(ctx, logger).Msg("Push")
 
-	env := SetupSSHCommandEnv(opt.AuthCfg.SSHCommand, opt.AuthCfg.SSHURLRewriteFrom, opt.AuthCfg.SSHURLRewriteTo)
-	args := append([]string{"push", opt.Target}, opt.RefSpecs...)
+	// The credentials go to the credential helper instead of the command line
+	target := withoutPassword(opt.Target)
+
+	env, err := gitEnv(ctx, opt.AuthCfg, target)
+	if err != nil {
+		return err
+	}
+
+	if sourceURL, ok := promisorURL(repo); ok {
+		if env, err = promisorEnv(ctx, env, opt.SourceAuthCfg, sourceURL, target); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"push"}
+	if opt.Prune {
+		args = append(args, "--prune")
+	}
+
+	args = append(append(args, target), opt.RefSpecs...)
 
 	tmpDirPath, err := model.GetTmpDirPath(ctx)
 	if err != nil {
@@ -160,6 +311,21 @@ func (g *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt m
 	return g.executorService.RunGitCommand(ctx, env, destinationDir, args...) //nolint
 }
 
+// promisorURL returns the URL of the source a partial clone fetches its missing objects from.
+func promisorURL(repo interfaces.GitRepository) (string, bool) {
+	cfg, err := repo.GoGitRepository().Config()
+	if err != nil {
+		return "", false
+	}
+
+	remote, ok := cfg.Remotes[gpsconfig.ORIGIN]
+	if !ok || len(remote.URLs) == 0 || cfg.Raw.Section("remote").Subsection(gpsconfig.ORIGIN).Option("promisor") != "true" {
+		return "", false
+	}
+
+	return remote.URLs[0], true
+}
+
 func ValidateGitBinary() (string, error) {
 	paths := []string{"git", "/usr/bin/git", "/usr/local/bin/git", "/opt/homebrew/bin/git"}
 	for _, path := range paths {
@@ -176,6 +342,10 @@ func SetupSSHCommandEnv(sshcommand, rewriteurlfrom, rewriteurlto string) []strin
 		return []string{}
 	}
 
+	if rewriteurlfrom == "" || rewriteurlto == "" {
+		return []string{"GIT_SSH_COMMAND=" + sshcommand}
+	}
+
 	return []string{
 		"GIT_SSH_COMMAND=" + sshcommand,
 		"GIT_CONFIG_COUNT=1",
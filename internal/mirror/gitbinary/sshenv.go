@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitbinary
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const (
+	sshAskPassName = ".gps-ssh-askpass"
+	// sshPassphraseEnv carries the passphrase to the askpass program, keeping it off command lines and disk.
+	sshPassphraseEnv = "GPS_SSH_PASSPHRASE"
+	sshAskPassScript = "#!/bin/sh\nprintf '%s\\n' \"$" + sshPassphraseEnv + "\"\n"
+)
+
+// sshEnv returns the environment git runs ssh with: the ssh command with the private key and known_hosts
+// options, the SSH URL rewrite, and an askpass program answering the passphrase prompt of the private key.
+func sshEnv(ctx context.Context, authCfg gpsconfig.AuthConfig) ([]string, error) {
+	env := SetupSSHCommandEnv(sshCommand(authCfg), authCfg.SSHURLRewriteFrom, authCfg.SSHURLRewriteTo)
+
+	if authCfg.SSHPrivateKeyPath == "" {
+		return env, nil
+	}
+
+	passphrase, err := authCfg.SSHPassphrase()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	if passphrase == "" {
+		return env, nil
+	}
+
+	askPassPath, err := writeSSHAskPass(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(env,
+		"SSH_ASKPASS="+askPassPath,
+		"SSH_ASKPASS_REQUIRE=force",
+		sshPassphraseEnv+"="+passphrase,
+	), nil
+}
+
+// sshCommand returns the configured ssh_command, extended with the private key and known_hosts options,
+// so the git binary authenticates and checks host keys like the go-git backend does.
+func sshCommand(authCfg gpsconfig.AuthConfig) string {
+	command := authCfg.SSHCommand
+	if authCfg.SSHPrivateKeyPath == "" && authCfg.KnownHostsPath == "" {
+		return command
+	}
+
+	if command == "" {
+		command = "ssh"
+	}
+
+	if authCfg.SSHPrivateKeyPath != "" {
+		command += " -i " + shellQuote(authCfg.SSHPrivateKeyPath) + " -o IdentitiesOnly=yes"
+	}
+
+	if authCfg.KnownHostsPath != "" {
+		command += " -o UserKnownHostsFile=" + shellQuote(authCfg.KnownHostsPath) + " -o StrictHostKeyChecking=yes"
+	}
+
+	return command
+}
+
+// writeSSHAskPass writes the askpass program to the run's temporary directory once, returning its path.
+// Concurrent workers share it, so an existing program is never rewritten in place while ssh may be running it:
+// it is kept when intact, and otherwise replaced by renaming a new one over it.
+func writeSSHAskPass(ctx context.Context) (string, error) {
+	tmpDirPath, err := model.GetTmpDirPath(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTmpDirPath, err)
+	}
+
+	askPassPath := filepath.Join(tmpDirPath, sshAskPassName)
+
+	if content, err := os.ReadFile(askPassPath); err == nil && string(content) == sshAskPassScript {
+		return askPassPath, nil
+	}
+
+	file, err := os.CreateTemp(tmpDirPath, sshAskPassName+".*")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(sshAskPassScript)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(file.Name(), 0o700) //nolint:gosec
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), askPassPath)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	return askPassPath, nil
+}
+
+// shellQuote quotes a value for the shell git runs GIT_SSH_COMMAND with.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package gitbinary
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+func TestSSHCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		authCfg gpsconfig.AuthConfig
+		want    string
+	}{
+		{
+			name: "no ssh options",
+		},
+		{
+			name:    "ssh command only",
+			authCfg: gpsconfig.AuthConfig{SSHCommand: "ssh -p 2222"},
+			want:    "ssh -p 2222",
+		},
+		{
+			name:    "private key",
+			authCfg: gpsconfig.AuthConfig{SSHPrivateKeyPath: "/keys/id_ed25519"},
+			want:    "ssh -i '/keys/id_ed25519' -o IdentitiesOnly=yes",
+		},
+		{
+			name: "private key and known hosts extend ssh command",
+			authCfg: gpsconfig.AuthConfig{
+				SSHCommand:        "ssh -p 2222",
+				SSHPrivateKeyPath: "/keys/my key",
+				KnownHostsPath:    "/keys/known_hosts",
+			},
+			want: "ssh -p 2222 -i '/keys/my key' -o IdentitiesOnly=yes -o UserKnownHostsFile='/keys/known_hosts' -o StrictHostKeyChecking=yes",
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, sshCommand(tabletest.authCfg))
+		})
+	}
+}
+
+func TestSSHEnv(t *testing.T) {
+	require := require.New(t)
+
+	ctx := testContext(t.TempDir())
+	t.Setenv("TEST_SSH_PASSPHRASE", "it's secret")
+
+	env, err := sshEnv(ctx, gpsconfig.AuthConfig{SSHPrivateKeyPath: "/keys/id_ed25519", SSHPassphraseEnv: "TEST_SSH_PASSPHRASE"})
+	require.NoError(err)
+	require.Contains(env, "GIT_SSH_COMMAND=ssh -i '/keys/id_ed25519' -o IdentitiesOnly=yes")
+	require.Contains(env, "SSH_ASKPASS_REQUIRE=force")
+
+	var askPassPath string
+
+	for _, variable := range env {
+		if path, found := strings.CutPrefix(variable, "SSH_ASKPASS="); found {
+			askPassPath = path
+		}
+	}
+
+	// The askpass program answers with the passphrase handed to it in the environment
+	cmd := exec.Command(askPassPath, "Enter passphrase for key '/keys/id_ed25519':")
+	cmd.Env = env
+
+	output, err := cmd.Output()
+	require.NoError(err)
+	require.Equal("it's secret\n", string(output))
+
+	_, err = sshEnv(ctx, gpsconfig.AuthConfig{SSHPrivateKeyPath: "/keys/id_ed25519", SSHPassphraseEnv: "TEST_SSH_PASSPHRASE_UNSET"})
+	require.ErrorIs(err, gpsconfig.ErrSSHPassphrase)
+}
+
+func TestWriteSSHAskPass(t *testing.T) {
+	require := require.New(t)
+
+	ctx := testContext(t.TempDir())
+
+	askPassPath, err := writeSSHAskPass(ctx)
+	require.NoError(err)
+
+	written := time.Now().Add(-time.Hour)
+	require.NoError(os.Chtimes(askPassPath, written, written))
+
+	before, err := os.Stat(askPassPath)
+	require.NoError(err)
+
+	// Workers share the program, so it is written once and not rewritten while ssh may be running it
+	var wg sync.WaitGroup
+
+	paths := make([]string, 8)
+	errs := make([]error, 8)
+
+	for index := range paths {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			paths[index], errs[index] = writeSSHAskPass(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	for index := range paths {
+		require.NoError(errs[index])
+		require.Equal(askPassPath, paths[index])
+	}
+
+	after, err := os.Stat(askPassPath)
+	require.NoError(err)
+	require.True(os.SameFile(before, after))
+	require.Equal(before.ModTime(), after.ModTime())
+
+	entries, err := os.ReadDir(filepath.Dir(askPassPath))
+	require.NoError(err)
+	require.Len(entries, 1)
+}
@@ -12,9 +12,11 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
 
 	"itiquette/git-provider-sync/internal/log"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/githubapp"
 )
 
 type AuthService interface {
@@ -34,10 +36,60 @@ func (p *authService) GetAuthMethod(ctx context.Context, authCfg gpsconfig.AuthC
 
 	switch strings.ToLower(authCfg.Protocol) {
 	case gpsconfig.SSH:
-		return ssh.NewSSHAgentAuth("git") //nolint
+		return sshAuthMethod(authCfg)
 	case gpsconfig.TLS, "":
-		return &http.BasicAuth{Username: "anyUser", Password: authCfg.Token}, nil
+		authCfg, err := githubapp.ResolveAuth(ctx, authCfg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAuthMethod, err)
+		}
+
+		username := "anyUser"
+		if authCfg.Username != "" {
+			username = authCfg.Username
+		}
+
+		return &http.BasicAuth{Username: username, Password: authCfg.Token}, nil
 	default:
 		return nil, fmt.Errorf("%w", ErrInvalidAuth)
 	}
 }
+
+// sshAuthMethod authenticates with the configured private key file, or else with the keys of the SSH agent.
+// With a known_hosts file the host keys are checked strictly against it, unknown hosts are rejected.
+func sshAuthMethod(authCfg gpsconfig.AuthConfig) (transport.AuthMethod, error) {
+	var hostKeyCallback gossh.HostKeyCallback
+
+	if authCfg.KnownHostsPath != "" {
+		callback, err := ssh.NewKnownHostsCallback(authCfg.KnownHostsPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: known hosts: %w", ErrAuthMethod, err)
+		}
+
+		hostKeyCallback = callback
+	}
+
+	if authCfg.SSHPrivateKeyPath == "" {
+		agentAuth, err := ssh.NewSSHAgentAuth("git")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAuthMethod, err)
+		}
+
+		agentAuth.HostKeyCallback = hostKeyCallback
+
+		return agentAuth, nil
+	}
+
+	passphrase, err := authCfg.SSHPassphrase()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	keyAuth, err := ssh.NewPublicKeysFromFile("git", authCfg.SSHPrivateKeyPath, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	keyAuth.HostKeyCallback = hostKeyCallback
+
+	return keyAuth, nil
+}
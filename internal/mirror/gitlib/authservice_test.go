@@ -5,13 +5,21 @@ package gitlib
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
 	"fmt"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
 func TestGetAuthMethod(t *testing.T) {
@@ -96,3 +104,105 @@ func TestNewAuthService(t *testing.T) {
 	require.NotNil(t, svc)
 	require.IsType(t, &authService{}, svc)
 }
+
+// writeKeyFiles writes an ed25519 private key, encrypted if passphrase is set,
+// and a known_hosts file holding the public key of a host.
+func writeKeyFiles(t *testing.T, passphrase string, hostKey gossh.PublicKey) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := gossh.MarshalPrivateKey(privateKey, "")
+	if passphrase != "" {
+		block, err = gossh.MarshalPrivateKeyWithPassphrase(privateKey, "", []byte(passphrase))
+	}
+
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+	require.NoError(t, os.WriteFile(knownHostsPath, []byte(knownhosts.Line([]string{"git.example.com"}, hostKey)+"\n"), 0o600))
+
+	return keyPath, knownHostsPath
+}
+
+func TestGetAuthMethod_SSHPrivateKey(t *testing.T) {
+	require := require.New(t)
+
+	hostPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+
+	hostKey, err := gossh.NewPublicKey(hostPublicKey)
+	require.NoError(err)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+
+	otherKey, err := gossh.NewPublicKey(otherPublicKey)
+	require.NoError(err)
+
+	keyPath, knownHostsPath := writeKeyFiles(t, "secret", hostKey)
+
+	t.Setenv("TEST_SSH_PASSPHRASE", "secret")
+
+	auth, err := NewAuthService().GetAuthMethod(context.Background(), gpsconfig.AuthConfig{
+		Protocol:          gpsconfig.SSH,
+		SSHPrivateKeyPath: keyPath,
+		SSHPassphraseEnv:  "TEST_SSH_PASSPHRASE",
+		KnownHostsPath:    knownHostsPath,
+	})
+	require.NoError(err)
+
+	keys, ok := auth.(*ssh.PublicKeys)
+	require.True(ok)
+	require.Equal("git", keys.User)
+
+	// Host keys are checked strictly against the known_hosts file
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	require.NoError(keys.HostKeyCallback("git.example.com:22", addr, hostKey))
+	require.Error(keys.HostKeyCallback("git.example.com:22", addr, otherKey))
+	require.Error(keys.HostKeyCallback("unknown.example.com:22", addr, hostKey))
+}
+
+func TestGetAuthMethod_SSHPrivateKeyErrors(t *testing.T) {
+	hostPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	hostKey, err := gossh.NewPublicKey(hostPublicKey)
+	require.NoError(t, err)
+
+	keyPath, knownHostsPath := writeKeyFiles(t, "secret", hostKey)
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("wrong\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		authCfg gpsconfig.AuthConfig
+	}{
+		{
+			name:    "missing passphrase",
+			authCfg: gpsconfig.AuthConfig{Protocol: gpsconfig.SSH, SSHPrivateKeyPath: keyPath},
+		},
+		{
+			name:    "wrong passphrase",
+			authCfg: gpsconfig.AuthConfig{Protocol: gpsconfig.SSH, SSHPrivateKeyPath: keyPath, SSHPassphraseFile: passphraseFile},
+		},
+		{
+			name:    "missing known hosts file",
+			authCfg: gpsconfig.AuthConfig{Protocol: gpsconfig.SSH, SSHPrivateKeyPath: keyPath, KnownHostsPath: knownHostsPath + ".missing"},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			auth, err := NewAuthService().GetAuthMethod(context.Background(), tabletest.authCfg)
+			require.ErrorIs(t, err, ErrAuthMethod)
+			require.Nil(t, auth)
+		})
+	}
+}
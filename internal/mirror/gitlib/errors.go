@@ -9,8 +9,11 @@ import "errors"
 var (
 	ErrAuthMethod       = errors.New("failed to get auth method")
 	ErrBranchCheckout   = errors.New("failed to checkout branch")
+	ErrCacheEntry       = errors.New("failed to update cache entry")
 	ErrCloneRepository  = errors.New("failed to clone repository")
 	ErrFetchBranches    = errors.New("failed to fetch branches")
+	ErrPruneRefs        = errors.New("failed to prune refs")
+	ErrListRefs         = errors.New("failed to list remote refs")
 	ErrWorktree         = errors.New("failed to get worktree")
 	ErrHeadSet          = errors.New("failed to set HEAD reference")
 	ErrInvalidAuth      = errors.New("invalid authentication configuration")
@@ -26,7 +26,7 @@ func (h *MetadataHandler) UpdateSyncMetadata(ctx context.Context, key, targetDir
 	logger.Trace().Msg("Entering GitLib:updateSyncRunMetainfo")
 	logger.Debug().Str("key", key).Str("targetDir", stringconvert.RemoveBasicAuthFromURL(ctx, targetDir, false)).Msg("GitLib:updateSyncRunMetainfo")
 
-	if syncRunMeta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(model.SyncRunMetainfo); ok {
-		(*syncRunMeta.Fail)[key] = append((*syncRunMeta.Fail)[key], targetDir)
+	if syncRunMeta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(*model.SyncRunMetainfo); ok {
+		syncRunMeta.AddFailure(key, targetDir)
 	}
 }
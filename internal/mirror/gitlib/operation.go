@@ -11,6 +11,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
@@ -93,6 +94,66 @@ func (h *Operation) FetchBranches(ctx context.Context, name string, repo *git.Re
 	return nil
 }
 
+// PruneRefs deletes the branches and tags of the repository that its origin no longer has,
+// except the checked out branch. In a dry run they are only listed. It returns the pruned refs.
+func (h *Operation) PruneRefs(ctx context.Context, repo *git.Repository, auth transport.AuthMethod, dryRun bool) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering pruneRefs")
+
+	remote, err := repo.Remote(gpsconfig.ORIGIN)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPruneRefs, err)
+	}
+
+	remoteRefs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPruneRefs, err)
+	}
+
+	remoteRefNames := make([]string, 0, len(remoteRefs))
+	for _, ref := range remoteRefs {
+		remoteRefNames = append(remoteRefNames, ref.Name().String())
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPruneRefs, err)
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPruneRefs, err)
+	}
+
+	var localRefNames []string
+
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && ref.Name() != head.Name() {
+			localRefNames = append(localRefNames, ref.Name().String())
+		}
+
+		return nil
+	})
+
+	stale := gpsconfig.StaleRefs(gpsconfig.DefaultRefSpecs(), remoteRefNames, localRefNames)
+
+	for _, refName := range stale {
+		if dryRun {
+			logger.Info().Str("ref", refName).Msg("Prune dry run, would delete ref")
+
+			continue
+		}
+
+		logger.Info().Str("ref", refName).Msg("Pruning ref")
+
+		if err := repo.Storer.RemoveReference(plumbing.ReferenceName(refName)); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrPruneRefs, refName, err)
+		}
+	}
+
+	return stale, nil
+}
+
 func (h *Operation) SetRemoteAndBranch(ctx context.Context, targetDirPath string, repository interfaces.GitRepository) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering setRemoteAndBranch")
@@ -166,3 +227,45 @@ func (h *Operation) SetDefaultBranch(ctx context.Context, branch string, repo *g
 
 	return nil
 }
+
+// CloneLocal mirror clones a local repository, such as a directory or an extracted archive, into memory.
+// The origin remote is pointed back at the upstream URL recorded in the local repository,
+// so a restored repository still knows where it was mirrored from.
+func (h *Operation) CloneLocal(ctx context.Context, path string) (*git.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering cloneLocal")
+	logger.Debug().Str("path", path).Msg("cloneLocal")
+
+	localRepo, err := h.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:    path,
+		Mirror: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrCloneRepository, path, err)
+	}
+
+	upstream, err := localRepo.Remote(gpsconfig.ORIGIN)
+	if err != nil {
+		logger.Debug().Str("path", path).Msg("No upstream origin recorded in local repository")
+
+		return repo, nil
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoteCreation, err)
+	}
+
+	cfg.Remotes[gpsconfig.ORIGIN].URLs = upstream.Config().URLs
+
+	if err := repo.SetConfig(cfg); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoteCreation, err)
+	}
+
+	return repo, nil
+}
@@ -326,3 +326,75 @@ func TestSetDefaultBranch(t *testing.T) {
 		})
 	}
 }
+
+func TestCloneLocal(t *testing.T) {
+	tmpDir := t.TempDir()
+	oper := NewOperation()
+
+	// Create a local repository recording its upstream as origin
+	_, err := createTmpGitBareRepo(tmpDir, "local-repo")
+	require.NoError(t, err)
+
+	localPath := filepath.Join(tmpDir, "temp-local-repo")
+
+	repo, err := oper.CloneLocal(context.Background(), localPath)
+	require.NoError(t, err)
+
+	// Verify the branches were cloned
+	_, err = repo.Reference(plumbing.NewBranchReferenceName("main"), false)
+	require.NoError(t, err)
+
+	// Verify origin points at the recorded upstream, not the local path
+	remote, err := repo.Remote(gpsconfig.ORIGIN)
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://origin.dot/local-repo.git"}, remote.Config().URLs)
+
+	_, err = oper.CloneLocal(context.Background(), filepath.Join(tmpDir, "missing"))
+	require.ErrorIs(t, err, ErrOpenRepository)
+}
+
+func TestPruneRefs(t *testing.T) {
+	require := require.New(t)
+	tmpDir := t.TempDir()
+	oper := NewOperation()
+
+	upstream, err := createTmpGitBareRepo(tmpDir, "upstream")
+	require.NoError(err)
+
+	head, err := upstream.Head()
+	require.NoError(err)
+
+	for _, refName := range []string{"refs/heads/feature", "refs/tags/v1.0"} {
+		require.NoError(upstream.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(refName), head.Hash())))
+	}
+
+	repo, err := git.PlainClone(filepath.Join(tmpDir, "mirror"), false, &git.CloneOptions{URL: filepath.Join(tmpDir, "temp-upstream")})
+	require.NoError(err)
+
+	for _, refName := range []string{"refs/heads/feature", "refs/tags/v1.0"} {
+		require.NoError(repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(refName), head.Hash())))
+	}
+
+	// The feature branch and the tag are deleted at the origin
+	require.NoError(upstream.Storer.RemoveReference("refs/heads/feature"))
+	require.NoError(upstream.Storer.RemoveReference("refs/tags/v1.0"))
+
+	// A dry run only lists them
+	stale, err := oper.PruneRefs(context.Background(), repo, nil, true)
+	require.NoError(err)
+	require.Equal([]string{"refs/heads/feature", "refs/tags/v1.0"}, stale)
+
+	_, err = repo.Reference("refs/heads/feature", false)
+	require.NoError(err)
+
+	stale, err = oper.PruneRefs(context.Background(), repo, nil, false)
+	require.NoError(err)
+	require.Len(stale, 2)
+
+	_, err = repo.Reference("refs/heads/feature", false)
+	require.ErrorIs(err, plumbing.ErrReferenceNotFound)
+
+	// The checked out branch is kept
+	_, err = repo.Reference("refs/heads/main", false)
+	require.NoError(err)
+}
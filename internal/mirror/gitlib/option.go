@@ -7,16 +7,30 @@ import (
 	"github.com/go-git/go-git/v5"
 	gogitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 )
 
-func (serv *Service) buildCloneOptions(url string, mirror bool, auth transport.AuthMethod) *git.CloneOptions {
+func (serv *Service) buildCloneOptions(url string, mirror bool, depth int, auth transport.AuthMethod) *git.CloneOptions {
 	return &git.CloneOptions{
 		Auth:   auth,
+		Depth:  depth,
 		Mirror: mirror,
 		URL:    url,
 	}
 }
 
+func (serv *Service) buildFetchOptions(url string, auth transport.AuthMethod) *git.FetchOptions {
+	return &git.FetchOptions{
+		Auth:       auth,
+		Force:      true,
+		Prune:      true,
+		RefSpecs:   []gogitconfig.RefSpec{"+refs/*:refs/*"},
+		RemoteName: gpsconfig.ORIGIN,
+		RemoteURL:  url,
+	}
+}
+
 func (serv *Service) buildPullOptions(remote string, url string, auth transport.AuthMethod) *git.PullOptions {
 	return &git.PullOptions{
 		Auth:       auth,
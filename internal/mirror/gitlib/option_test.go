@@ -20,6 +20,7 @@ func TestService_buildCloneOptions(t *testing.T) {
 		name   string
 		url    string
 		mirror bool
+		depth  int
 		auth   transport.AuthMethod
 		want   *git.CloneOptions
 	}{
@@ -51,6 +52,19 @@ func TestService_buildCloneOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "shallow clone",
+			url:    "https://github.com/test/repo.git",
+			mirror: true,
+			depth:  1,
+			auth:   nil,
+			want: &git.CloneOptions{
+				URL:    "https://github.com/test/repo.git",
+				Mirror: true,
+				Depth:  1,
+				Auth:   nil,
+			},
+		},
 		{
 			name:   "empty url",
 			url:    "",
@@ -68,9 +82,10 @@ func TestService_buildCloneOptions(t *testing.T) {
 
 	for _, tabletest := range tests {
 		t.Run(tabletest.name, func(t *testing.T) {
-			got := s.buildCloneOptions(tabletest.url, tabletest.mirror, tabletest.auth)
+			got := s.buildCloneOptions(tabletest.url, tabletest.mirror, tabletest.depth, tabletest.auth)
 			require.Equal(t, tabletest.want.URL, got.URL)
 			require.Equal(t, tabletest.want.Mirror, got.Mirror)
+			require.Equal(t, tabletest.want.Depth, got.Depth)
 			require.Equal(t, tabletest.want.Auth, got.Auth)
 		})
 	}
@@ -8,14 +8,19 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/memory"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/cache"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 	"itiquette/git-provider-sync/internal/provider/stringconvert"
@@ -49,12 +54,21 @@ func (serv *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Re
 		return model.Repository{}, fmt.Errorf("%w: %w", ErrAuthMethod, err)
 	}
 
+	if opt.SourceCfg.CacheDir != "" && !opt.NonBareRepo {
+		return serv.cloneCached(ctx, opt, auth)
+	}
+
 	var fileSys billy.Filesystem
 	if opt.NonBareRepo {
 		fileSys = memfs.New()
 	}
 
-	cloneOpt := serv.buildCloneOptions(opt.URL, opt.Mirror, auth)
+	depth := 0
+	if opt.SourceCfg.CloneMode == gpsconfig.CloneModeShallow {
+		depth = 1
+	}
+
+	cloneOpt := serv.buildCloneOptions(opt.URL, opt.Mirror, depth, auth)
 
 	repo, err := git.Clone(memory.NewStorage(), fileSys, cloneOpt)
 	if err != nil {
@@ -64,6 +78,122 @@ func (serv *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Re
 	return model.NewRepository(repo) //nolint
 }
 
+// cloneCached clones the bare mirror repository kept in the cache directory into the run's temporary directory,
+// after cloning it on first use or fetching incrementally on later runs.
+// The cache entry is locked while it is updated and cloned, and never handed out itself,
+// so a run writing to its repository, or another run fetching, does not change the other's.
+func (serv *Service) cloneCached(ctx context.Context, opt model.CloneOption, auth transport.AuthMethod) (model.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitService:cloneCached")
+
+	entryPath := cache.EntryPath(opt.SourceCfg.CacheDir, opt.SourceCfg.GetDomain(), opt.SourceCfg.Owner, opt.Name)
+
+	unlock, err := cache.Lock(ctx, entryPath)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCacheEntry, err)
+	}
+
+	defer func() {
+		if err := unlock(); err != nil {
+			logger.Warn().Err(err).Str("entryPath", entryPath).Msg("failed to unlock cache entry")
+		}
+	}()
+
+	if err := serv.updateCacheEntry(ctx, entryPath, opt.URL, auth); err != nil {
+		return model.Repository{}, err
+	}
+
+	tmpDirPath, err := model.GetTmpDirPath(ctx)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	repo, err := git.PlainCloneContext(ctx, filepath.Join(tmpDirPath, opt.Name), true, serv.buildCloneOptions(entryPath, true, 0, nil))
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	// The clone is of the source, not of the cache entry it was copied from
+	cfg, err := repo.Config()
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	cfg.Remotes[gpsconfig.ORIGIN].URLs = []string{opt.URL}
+
+	if err := repo.SetConfig(cfg); err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	return model.NewRepository(repo) //nolint
+}
+
+// updateCacheEntry brings the bare mirror repository at entryPath up to date with the source at url,
+// cloning it on first use and fetching incrementally on later runs. The caller holds the lock of the entry.
+func (serv *Service) updateCacheEntry(ctx context.Context, entryPath, url string, auth transport.AuthMethod) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitService:updateCacheEntry")
+
+	if !cache.Exists(entryPath) {
+		logger.Debug().Str("entryPath", entryPath).Msg("Populating cache entry")
+
+		if _, err := git.PlainCloneContext(ctx, entryPath, true, serv.buildCloneOptions(url, true, 0, auth)); err != nil {
+			return fmt.Errorf("%w: %w", ErrCloneRepository, err)
+		}
+
+		return nil
+	}
+
+	repo, err := git.PlainOpen(entryPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrOpenRepository, err)
+	}
+
+	logger.Debug().Str("entryPath", entryPath).Msg("Fetching into cache entry")
+
+	if err := repo.FetchContext(ctx, serv.buildFetchOptions(url, auth)); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("%w: %w", ErrFetchBranches, err)
+	}
+
+	return nil
+}
+
+// ListRefs lists the refs of the source repository without cloning it.
+func (serv *Service) ListRefs(ctx context.Context, opt model.CloneOption) (map[string]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitService:ListRefs")
+	opt.DebugLog(ctx, logger).Msg("GitService:ListRefs")
+
+	auth, err := serv.authService.GetAuthMethod(ctx, opt.AuthCfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &gogitconfig.RemoteConfig{
+		Name: gpsconfig.ORIGIN,
+		URLs: []string{opt.URL},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return map[string]string{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListRefs, err)
+	}
+
+	tips := make(map[string]string, len(refs))
+
+	for _, ref := range refs {
+		if ref.Type() == plumbing.HashReference && strings.HasPrefix(ref.Name().String(), "refs/") {
+			tips[ref.Name().String()] = ref.Hash().String()
+		}
+	}
+
+	return tips, nil
+}
+
 func (serv *Service) Pull(ctx context.Context, opt model.PullOption) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitService:Pull")
@@ -84,7 +214,17 @@ func (serv *Service) Pull(ctx context.Context, opt model.PullOption) error {
 		return err
 	}
 
-	return serv.Ops.FetchBranches(ctx, filepath.Dir(opt.TargetDir), repo, auth)
+	if err := serv.Ops.FetchBranches(ctx, filepath.Dir(opt.TargetDir), repo, auth); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if opt.Prune || opt.PruneDryRun {
+		if _, err := serv.Ops.PruneRefs(ctx, repo, auth, !opt.Prune || opt.PruneDryRun); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	return nil
 }
 
 func (serv *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption) error {
@@ -102,7 +242,7 @@ func (serv *Service) Push(ctx context.Context, repo interfaces.GitRepository, op
 	if err := repo.GoGitRepository().Push(&pushOpts); err != nil {
 		if errors.Is(err, git.NoErrAlreadyUpToDate) {
 			logger.Debug().Str("targetDir", stringconvert.RemoveBasicAuthFromURL(ctx, opt.Target, false)).Msg("repository already up-to-date")
-			serv.metadata.UpdateSyncMetadata(ctx, "uptodate", opt.Target)
+			serv.metadata.UpdateSyncMetadata(ctx, "uptodate", stringconvert.RemoveBasicAuthFromURL(ctx, opt.Target, true))
 
 			return nil
 		}
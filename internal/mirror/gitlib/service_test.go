@@ -7,8 +7,14 @@ import (
 	"context"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/stretchr/testify/require"
 )
@@ -96,6 +102,128 @@ func TestService_Clone(t *testing.T) {
 	}
 }
 
+func TestService_CloneCached(t *testing.T) {
+	sourceDir := t.TempDir()
+
+	sourceRepo, err := git.PlainInit(sourceDir, false)
+	require.NoError(t, err)
+
+	commit := func(msg string) plumbing.Hash {
+		t.Helper()
+
+		worktree, err := sourceRepo.Worktree()
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "file.txt"), []byte(msg), 0o600))
+
+		_, err = worktree.Add("file.txt")
+		require.NoError(t, err)
+
+		hash, err := worktree.Commit(msg, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	cacheDir := t.TempDir()
+	opt := model.CloneOption{
+		Name:      "repo",
+		URL:       sourceDir,
+		Mirror:    true,
+		SourceCfg: gpsconfig.SyncConfig{BaseConfig: gpsconfig.BaseConfig{Domain: "example.com", Owner: "owner"}, CacheDir: cacheDir},
+	}
+
+	svc := &Service{
+		authService: &mockAuthService{},
+		Ops:         *NewOperation(),
+		metadata:    &mockMetadataHandler{},
+	}
+
+	headOf := func(repo model.Repository) plumbing.Hash {
+		t.Helper()
+
+		head, err := repo.GoGitRepository().Head()
+		require.NoError(t, err)
+
+		return head.Hash()
+	}
+
+	// Each run clones into a temporary directory of its own
+	runCtx := func() context.Context {
+		t.Helper()
+
+		return context.WithValue(context.Background(), model.TmpDirKey{}, t.TempDir())
+	}
+
+	first := commit("first")
+
+	ctx := runCtx()
+	repo, err := svc.Clone(ctx, opt)
+	require.NoError(t, err)
+	require.Equal(t, first, headOf(repo))
+	require.FileExists(t, filepath.Join(cacheDir, "example.com", "owner", "repo.git", "HEAD"))
+
+	second := commit("second")
+
+	ctx = runCtx()
+	repo, err = svc.Clone(ctx, opt)
+	require.NoError(t, err)
+	require.Equal(t, second, headOf(repo))
+
+	tmpDirPath, err := model.GetTmpDirPath(ctx)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(tmpDirPath, "repo", "HEAD"))
+
+	// The clone is a copy with the source as origin, changing it leaves the cache entry untouched
+	origin, err := repo.Remote(gpsconfig.ORIGIN)
+	require.NoError(t, err)
+	require.Equal(t, sourceDir, origin.URL)
+	require.NoError(t, repo.CreateRemote(gpsconfig.GPSUPSTREAM, sourceDir, false))
+
+	entry, err := git.PlainOpen(filepath.Join(cacheDir, "example.com", "owner", "repo.git"))
+	require.NoError(t, err)
+
+	_, err = entry.Remote(gpsconfig.GPSUPSTREAM)
+	require.ErrorIs(t, err, git.ErrRemoteNotFound)
+}
+
+func TestService_ListRefs(t *testing.T) {
+	sourceDir := t.TempDir()
+
+	sourceRepo, err := git.PlainInit(sourceDir, false)
+	require.NoError(t, err)
+
+	svc := &Service{
+		authService: &mockAuthService{},
+		Ops:         *NewOperation(),
+		metadata:    &mockMetadataHandler{},
+	}
+
+	refs, err := svc.ListRefs(context.Background(), model.CloneOption{URL: sourceDir})
+	require.NoError(t, err)
+	require.Empty(t, refs)
+
+	worktree, err := sourceRepo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "file.txt"), []byte("content"), 0o600))
+
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+
+	hash, err := worktree.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	head, err := sourceRepo.Head()
+	require.NoError(t, err)
+
+	refs, err = svc.ListRefs(context.Background(), model.CloneOption{URL: sourceDir})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{head.Name().String(): hash.String()}, refs)
+}
+
 // func TestService_Pull(t *testing.T) {
 // 	tests := []struct {
 // 		name      string
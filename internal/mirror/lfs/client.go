@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package lfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+const (
+	mediaType = "application/vnd.git-lfs+json"
+
+	// batchSize is the number of objects per batch request, the limit most LFS servers accept.
+	batchSize = 100
+
+	basicTransfer = "basic"
+
+	operationDownload = "download"
+	operationUpload   = "upload"
+)
+
+// Endpoint derives the LFS endpoint of a repository from its clone URL, <https url>/info/lfs.
+// SSH clone URLs, including scp-like ones, are mapped to the HTTPS URL of the same path,
+// as the LFS servers of the providers answer there.
+//
+// Example:
+//
+//	Endpoint("git@github.com:owner/repo.git")
+//	// https://github.com/owner/repo.git/info/lfs
+func Endpoint(cloneURL string) (string, error) {
+	rawURL := cloneURL
+	if !strings.Contains(rawURL, "://") {
+		userHost, path, found := strings.Cut(rawURL, ":")
+		if !found {
+			return "", fmt.Errorf("%w: %s", ErrInvalidEndpoint, cloneURL)
+		}
+
+		rawURL = "ssh://" + userHost + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidEndpoint, cloneURL)
+	}
+
+	switch parsedURL.Scheme {
+	case "http", "https":
+	case "ssh", "git+ssh", "ssh+git":
+		parsedURL.Scheme = "https"
+		parsedURL.Host = parsedURL.Hostname()
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidEndpoint, cloneURL)
+	}
+
+	parsedURL.User = nil
+	parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
+
+	if !strings.HasSuffix(parsedURL.Path, ".git") {
+		parsedURL.Path += ".git"
+	}
+
+	return parsedURL.String() + "/info/lfs", nil
+}
+
+// Client talks to an LFS server with the batch API and the basic transfer adapter.
+type Client struct {
+	httpClient *http.Client
+	endpoint   string
+	username   string
+	token      string
+}
+
+func NewClient(httpClient *http.Client, endpoint, username, token string) *Client {
+	return &Client{httpClient: httpClient, endpoint: endpoint, username: username, token: token}
+}
+
+type batchRequest struct {
+	Operation string    `json:"operation"`
+	Transfers []string  `json:"transfers"`
+	Objects   []Pointer `json:"objects"`
+	HashAlgo  string    `json:"hash_algo"`
+}
+
+type batchResponse struct {
+	Transfer string        `json:"transfer"`
+	Objects  []batchObject `json:"objects"`
+	Message  string        `json:"message"`
+}
+
+type batchObject struct {
+	Pointer
+
+	Actions map[string]action `json:"actions"`
+	Error   *objectError      `json:"error"`
+}
+
+type action struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header"`
+}
+
+type objectError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// batch asks the server how to transfer the objects, in requests of at most batchSize objects.
+func (c *Client) batch(ctx context.Context, operation string, pointers []Pointer) ([]batchObject, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:batch")
+
+	objects := make([]batchObject, 0, len(pointers))
+
+	for start := 0; start < len(pointers); start += batchSize {
+		chunk := pointers[start:min(start+batchSize, len(pointers))]
+
+		response, err := c.batchChunk(ctx, operation, chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, response.Objects...)
+	}
+
+	return objects, nil
+}
+
+func (c *Client) batchChunk(ctx context.Context, operation string, pointers []Pointer) (batchResponse, error) {
+	body, err := json.Marshal(batchRequest{
+		Operation: operation,
+		Transfers: []string{basicTransfer},
+		Objects:   pointers,
+		HashAlgo:  "sha256",
+	})
+	if err != nil {
+		return batchResponse{}, fmt.Errorf("%w: %w", ErrBatchRequest, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return batchResponse{}, fmt.Errorf("%w: %w", ErrBatchRequest, err)
+	}
+
+	req.Header.Set("Accept", mediaType)
+	req.Header.Set("Content-Type", mediaType)
+
+	if c.token != "" {
+		req.SetBasicAuth(c.username, c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return batchResponse{}, fmt.Errorf("%w: %w", ErrBatchRequest, err)
+	}
+	defer resp.Body.Close()
+
+	var response batchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil && resp.StatusCode == http.StatusOK {
+		return batchResponse{}, fmt.Errorf("%w: %w", ErrBatchRequest, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return batchResponse{}, fmt.Errorf("%w: %s: %s %s", ErrBatchRequest, c.endpoint, resp.Status, response.Message)
+	}
+
+	if response.Transfer != "" && response.Transfer != basicTransfer {
+		return batchResponse{}, fmt.Errorf("%w: %s", ErrUnsupportedTransfer, response.Transfer)
+	}
+
+	return response, nil
+}
+
+// download writes the object behind a download action to the store.
+func (c *Client) download(ctx context.Context, act action, pointer Pointer, store Store) error {
+	resp, err := c.do(ctx, http.MethodGet, act, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return store.Write(pointer, resp.Body)
+}
+
+// upload sends the object from the store to an upload action.
+func (c *Client) upload(ctx context.Context, act action, pointer Pointer, store Store) error {
+	file, err := store.Open(pointer)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	resp, err := c.do(ctx, http.MethodPut, act, file, pointer.Size)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close() //nolint:wrapcheck
+}
+
+// verify confirms an upload, for servers that ask for it.
+func (c *Client) verify(ctx context.Context, act action, pointer Pointer) error {
+	body, err := json.Marshal(pointer)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTransfer, err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, act, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close() //nolint:wrapcheck
+}
+
+// do runs a transfer action. Only the headers the server gave for the action are sent,
+// the action may point at another host, such as an object storage.
+func (c *Client) do(ctx context.Context, method string, act action, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, act.Href, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTransfer, err)
+	}
+
+	for key, value := range act.Header {
+		req.Header.Set(key, value)
+	}
+
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", mediaType)
+		req.Header.Set("Accept", mediaType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTransfer, err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("%w: %s %s: %s", ErrTransfer, method, redact(act.Href), resp.Status)
+	}
+
+	return resp, nil
+}
+
+// redact drops the query of an action URL, which often carries a signature.
+func redact(href string) string {
+	before, _, _ := strings.Cut(href, "?")
+
+	return before
+}
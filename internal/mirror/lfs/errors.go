@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package lfs
+
+import "errors"
+
+var (
+	ErrBatchRequest        = errors.New("lfs batch request failed")
+	ErrInvalidEndpoint     = errors.New("no lfs endpoint for url")
+	ErrObjectCorrupt       = errors.New("lfs object does not match its pointer")
+	ErrObjectStore         = errors.New("failed to access lfs object store")
+	ErrScanPointers        = errors.New("failed to scan lfs pointers")
+	ErrTransfer            = errors.New("lfs object transfer failed")
+	ErrUnsupportedTransfer = errors.New("lfs server offers no basic transfer")
+)
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package lfs mirrors Git LFS objects, which live beside the git repository and are not
+// transferred by clone or push. It finds the LFS pointers committed in a repository,
+// downloads the objects they point at from the source LFS server with the batch API,
+// keeps them in a local object store, and uploads them to the target LFS server.
+package lfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	pointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+	// maxPointerSize is the largest blob read as a pointer, pointer files are well below it.
+	maxPointerSize = 1024
+)
+
+var oidPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Pointer identifies an LFS object by its SHA-256 object ID and size.
+type Pointer struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// ParsePointer parses the content of an LFS pointer file.
+// It reports false for any content that is not a valid pointer.
+func ParsePointer(content []byte) (Pointer, bool) {
+	if len(content) > maxPointerSize || !bytes.HasPrefix(content, []byte(pointerVersion+"\n")) {
+		return Pointer{}, false
+	}
+
+	var pointer Pointer
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+
+		switch key {
+		case "oid":
+			pointer.OID = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return Pointer{}, false
+			}
+
+			pointer.Size = size
+		}
+	}
+
+	if !oidPattern.MatchString(pointer.OID) {
+		return Pointer{}, false
+	}
+
+	return pointer, true
+}
+
+// ScanPointers returns the LFS pointers of all blobs in the repository, for all refs and their history.
+// Each object is listed once, sorted by object ID.
+func ScanPointers(ctx context.Context, repo *git.Repository) ([]Pointer, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:ScanPointers")
+
+	blobs, err := repo.BlobObjects()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScanPointers, err)
+	}
+
+	found := map[string]Pointer{}
+
+	err = blobs.ForEach(func(blob *object.Blob) error {
+		if blob.Size > maxPointerSize {
+			return nil
+		}
+
+		content, err := readBlob(blob)
+		if err != nil {
+			return err
+		}
+
+		if pointer, ok := ParsePointer(content); ok {
+			found[pointer.OID] = pointer
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScanPointers, err)
+	}
+
+	pointers := make([]Pointer, 0, len(found))
+	for _, pointer := range found {
+		pointers = append(pointers, pointer)
+	}
+
+	slices.SortFunc(pointers, func(a, b Pointer) int {
+		return strings.Compare(a.OID, b.OID)
+	})
+
+	logger.Debug().Int("pointers", len(pointers)).Msg("lfs:ScanPointers")
+
+	return pointers, nil
+}
+
+func readBlob(blob *object.Blob) ([]byte, error) {
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	content, err := io.ReadAll(reader)
+
+	return content, errors.Join(err, reader.Close())
+}
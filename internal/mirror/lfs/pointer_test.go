@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package lfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// newObject returns the pointer and pointer file content of an LFS object.
+func newObject(content string) (Pointer, string) {
+	sum := sha256.Sum256([]byte(content))
+	pointer := Pointer{OID: hex.EncodeToString(sum[:]), Size: int64(len(content))}
+
+	return pointer, fmt.Sprintf("%s\noid sha256:%s\nsize %d\n", pointerVersion, pointer.OID, pointer.Size)
+}
+
+func TestParsePointer(t *testing.T) {
+	pointer, pointerFile := newObject("large asset")
+
+	tests := []struct {
+		name    string
+		content string
+		want    Pointer
+		wantOK  bool
+	}{
+		{name: "pointer file", content: pointerFile, want: pointer, wantOK: true},
+		{name: "pointer with extension keys", content: pointerFile + "ext-0-foo sha256:" + pointer.OID + "\n", want: pointer, wantOK: true},
+		{name: "regular file", content: "hello world\n"},
+		{name: "invalid oid", content: pointerVersion + "\noid sha256:abc\nsize 11\n"},
+		{name: "invalid size", content: pointerVersion + "\noid sha256:" + pointer.OID + "\nsize -1\n"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			got, ok := ParsePointer([]byte(tabletest.content))
+			require.Equal(t, tabletest.wantOK, ok)
+			require.Equal(t, tabletest.want, got)
+		})
+	}
+}
+
+func TestScanPointers(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(err)
+
+	first, firstFile := newObject("first asset")
+	second, secondFile := newObject("second asset")
+
+	// commit the first pointer and replace it, the old version must still be found
+	files := []map[string]string{
+		{"asset.bin": firstFile, "README.md": "readme"},
+		{"asset.bin": secondFile, "copy.bin": secondFile},
+	}
+
+	for _, commitFiles := range files {
+		for name, content := range commitFiles {
+			require.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+
+			_, err = worktree.Add(name)
+			require.NoError(err)
+		}
+
+		_, err = worktree.Commit("commit", &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(err)
+	}
+
+	pointers, err := ScanPointers(context.Background(), repo)
+	require.NoError(err)
+	require.ElementsMatch([]Pointer{first, second}, pointers)
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package lfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// Store is a directory of LFS objects, laid out like git-lfs does in <git dir>/lfs/objects,
+// so a store inside a repository is picked up by git lfs itself.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) Store {
+	return Store{dir: dir}
+}
+
+// RepositoryStore returns the store inside a git directory, <gitDir>/lfs/objects.
+// For a bare repository the git directory is the repository itself, else its .git directory.
+func RepositoryStore(gitDir string) Store {
+	return NewStore(filepath.Join(gitDir, "lfs", "objects"))
+}
+
+func (s Store) Dir() string {
+	return s.dir
+}
+
+// Path returns the file path of an object, <dir>/<oid[0:2]>/<oid[2:4]>/<oid>.
+func (s Store) Path(oid string) string {
+	return filepath.Join(s.dir, oid[0:2], oid[2:4], oid)
+}
+
+// Has reports whether the object is stored with the size of its pointer.
+func (s Store) Has(pointer Pointer) bool {
+	info, err := os.Stat(s.Path(pointer.OID))
+
+	return err == nil && info.Size() == pointer.Size
+}
+
+func (s Store) Open(pointer Pointer) (*os.File, error) {
+	file, err := os.Open(s.Path(pointer.OID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrObjectStore, err)
+	}
+
+	return file, nil
+}
+
+// Write stores an object read from reader. The content is verified against the pointer,
+// and only moved in place when it matches, so a store never holds partial objects.
+func (s Store) Write(pointer Pointer, reader io.Reader) error {
+	path := s.Path(pointer.OID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrObjectStore, err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), pointer.OID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrObjectStore, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(tmpFile, hash), reader)
+	if err := errors.Join(err, tmpFile.Close()); err != nil {
+		return fmt.Errorf("%w: %w", ErrObjectStore, err)
+	}
+
+	if size != pointer.Size || hex.EncodeToString(hash.Sum(nil)) != pointer.OID {
+		return fmt.Errorf("%w: %s", ErrObjectCorrupt, pointer.OID)
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("%w: %w", ErrObjectStore, err)
+	}
+
+	return nil
+}
+
+// CopyTo copies the objects missing in the target store, returning the number of objects copied.
+func (s Store) CopyTo(target Store, pointers []Pointer) (int, error) {
+	copied := 0
+
+	for _, pointer := range pointers {
+		if target.Has(pointer) || !s.Has(pointer) {
+			continue
+		}
+
+		if err := s.copyObject(target, pointer); err != nil {
+			return copied, err
+		}
+
+		copied++
+	}
+
+	return copied, nil
+}
+
+func (s Store) copyObject(target Store, pointer Pointer) error {
+	file, err := s.Open(pointer)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return target.Write(pointer, file)
+}
+
+// CopyObjects copies all objects of the source store directory missing in the target store,
+// used to keep the LFS objects inside directory and archive backups.
+func CopyObjects(ctx context.Context, sourceDir string, target Store) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:CopyObjects")
+
+	source := NewStore(sourceDir)
+
+	pointers, err := source.Pointers()
+	if err != nil {
+		return err
+	}
+
+	copied, err := source.CopyTo(target, pointers)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug().Int("objects", len(pointers)).Int("copied", copied).Str("target", target.Dir()).Msg("lfs:CopyObjects")
+
+	return nil
+}
+
+// Pointers lists the objects in the store.
+func (s Store) Pointers() ([]Pointer, error) {
+	var pointers []Pointer
+
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == s.dir {
+			return fs.SkipAll
+		}
+
+		if err != nil || entry.IsDir() || !oidPattern.MatchString(entry.Name()) {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		pointers = append(pointers, Pointer{OID: entry.Name(), Size: info.Size()})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrObjectStore, err)
+	}
+
+	return pointers, nil
+}
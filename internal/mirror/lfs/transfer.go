@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package lfs
+
+import (
+	"context"
+	"fmt"
+
+	"itiquette/git-provider-sync/internal/log"
+
+	"github.com/rs/zerolog"
+)
+
+// progressInterval is how many transferred objects pass between progress log lines.
+const progressInterval = 25
+
+// Fetch downloads the objects missing in the store.
+// Objects the server does not have are logged and skipped, a broken pointer should not stop a mirror.
+func (c *Client) Fetch(ctx context.Context, pointers []Pointer, store Store) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:Fetch")
+
+	var missing []Pointer
+
+	for _, pointer := range pointers {
+		if !store.Has(pointer) {
+			missing = append(missing, pointer)
+		}
+	}
+
+	logger.Debug().Int("pointers", len(pointers)).Int("missing", len(missing)).Msg("lfs:Fetch")
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	objects, err := c.batch(ctx, operationDownload, missing)
+	if err != nil {
+		return err
+	}
+
+	progress := newProgress(logger, "Fetching LFS objects", len(objects))
+
+	for _, object := range objects {
+		if object.Error != nil {
+			logger.Warn().Str("oid", object.OID).Int("code", object.Error.Code).Str("message", object.Error.Message).Msg("LFS object not available at source, skipping")
+
+			continue
+		}
+
+		act, ok := object.Actions[operationDownload]
+		if !ok {
+			continue
+		}
+
+		if err := c.download(ctx, act, object.Pointer, store); err != nil {
+			return fmt.Errorf("%s: %w", object.OID, err)
+		}
+
+		progress.add(object.Size)
+	}
+
+	progress.done()
+
+	return nil
+}
+
+// Push uploads the objects in the store. The server only asks for objects it does not have yet.
+func (c *Client) Push(ctx context.Context, pointers []Pointer, store Store) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:Push")
+
+	var stored []Pointer
+
+	for _, pointer := range pointers {
+		if store.Has(pointer) {
+			stored = append(stored, pointer)
+		}
+	}
+
+	logger.Debug().Int("pointers", len(pointers)).Int("stored", len(stored)).Msg("lfs:Push")
+
+	if len(stored) == 0 {
+		return nil
+	}
+
+	objects, err := c.batch(ctx, operationUpload, stored)
+	if err != nil {
+		return err
+	}
+
+	progress := newProgress(logger, "Pushing LFS objects", len(objects))
+
+	for _, object := range objects {
+		if object.Error != nil {
+			return fmt.Errorf("%w: %s: %d %s", ErrTransfer, object.OID, object.Error.Code, object.Error.Message)
+		}
+
+		act, ok := object.Actions[operationUpload]
+		if !ok {
+			// the server already has the object
+			continue
+		}
+
+		if err := c.upload(ctx, act, object.Pointer, store); err != nil {
+			return fmt.Errorf("%s: %w", object.OID, err)
+		}
+
+		if verifyAction, ok := object.Actions["verify"]; ok {
+			if err := c.verify(ctx, verifyAction, object.Pointer); err != nil {
+				return fmt.Errorf("%s: %w", object.OID, err)
+			}
+		}
+
+		progress.add(object.Size)
+	}
+
+	progress.done()
+
+	return nil
+}
+
+// progress logs how many objects and bytes have been transferred.
+type progress struct {
+	logger  *zerolog.Logger
+	message string
+	total   int
+	count   int
+	bytes   int64
+}
+
+func newProgress(logger *zerolog.Logger, message string, total int) *progress {
+	return &progress{logger: logger, message: message, total: total}
+}
+
+func (p *progress) add(size int64) {
+	p.count++
+	p.bytes += size
+
+	if p.count%progressInterval == 0 {
+		p.logger.Info().Int("objects", p.count).Int("total", p.total).Int64("bytes", p.bytes).Msg(p.message)
+	}
+}
+
+func (p *progress) done() {
+	p.logger.Info().Int("objects", p.count).Int("total", p.total).Int64("bytes", p.bytes).Msg(p.message + " done")
+}
@@ -0,0 +1,478 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package objectstore stores the archives of s3 mirrors in a bucket of an S3-compatible object storage,
+// such as AWS S3, MinIO or Ceph. Archives are streamed to the bucket in parts, without a local copy,
+// and the storage verifies every part against its SHA-256 checksum.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const (
+	// DefaultRegion is the region of a bucket when none is configured, the region S3-compatible storages default to.
+	DefaultRegion = "us-east-1"
+
+	// defaultPartSize is the size of the parts of a multipart upload, above the 5 MiB minimum of S3.
+	// A body smaller than a part is uploaded in a single request.
+	defaultPartSize = 8 << 20
+
+	// maxParts is the highest number of parts S3 accepts in a multipart upload.
+	maxParts = 10000
+)
+
+// Client talks to a bucket of an S3-compatible object storage, with requests signed by Signature Version 4.
+// The bucket is addressed in the path of the requests, which every S3-compatible storage supports.
+type Client struct {
+	httpClient *http.Client
+	endpoint   url.URL
+	bucket     string
+	prefix     string
+	signer     signer
+	partSize   int
+}
+
+// NewClient creates the client of the bucket the configuration names, authenticating with the access key.
+// Without an endpoint the client talks to AWS S3 in the region.
+func NewClient(httpClient *http.Client, cfg gpsconfig.S3Config, accessKeyID, secretAccessKey string) (*Client, error) {
+	region := cfg.Region
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEndpoint, endpoint)
+	}
+
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, ErrNoCredentials
+	}
+
+	parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
+
+	return &Client{
+		httpClient: httpClient,
+		endpoint:   *parsedURL,
+		bucket:     cfg.Bucket,
+		prefix:     cfg.Prefix,
+		signer:     signer{accessKeyID: accessKeyID, secretAccessKey: secretAccessKey, region: region, service: "s3"},
+		partSize:   defaultPartSize,
+	}, nil
+}
+
+// Key returns the key of the object named name below prefix, the folder of the bucket a mirror writes to.
+//
+// Example:
+//
+//	Key("/backups/gitlab/", "app_20240102_030405_1704164645000.tar.gz")
+//	// backups/gitlab/app_20240102_030405_1704164645000.tar.gz
+func Key(prefix, name string) string {
+	return strings.TrimPrefix(path.Join(prefix, name), "/")
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completedPart struct {
+	PartNumber     int    `xml:"PartNumber"`
+	ETag           string `xml:"ETag"`
+	ChecksumSHA256 string `xml:"ChecksumSHA256"`
+}
+
+// completeMultipartUploadResult is the result of completing a multipart upload, or the error S3 reports
+// in the body of a successful response when the completion fails late.
+type completeMultipartUploadResult struct {
+	XMLName        xml.Name
+	ChecksumSHA256 string `xml:"ChecksumSHA256"`
+	Code           string `xml:"Code"`
+	Message        string `xml:"Message"`
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+type errorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// Upload streams body to the object at key, returning the hex encoded SHA-256 checksum of the whole object.
+// A body larger than a part is uploaded in parts, one part in memory at a time. Every part carries its SHA-256
+// checksum, which the storage verifies, and a failed multipart upload is aborted, leaving no parts behind.
+func (c *Client) Upload(ctx context.Context, key string, body io.Reader) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering ObjectStore:Upload")
+
+	hash := sha256.New()
+	reader := io.TeeReader(body, hash)
+	part := make([]byte, c.partSize)
+
+	size, err := io.ReadFull(reader, part)
+
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		if err := c.PutObject(ctx, key, part[:size], "application/octet-stream"); err != nil {
+			return "", err
+		}
+
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	case err != nil:
+		return "", fmt.Errorf("%w: %s: %w", ErrUpload, key, err)
+	}
+
+	uploadID, err := c.createMultipartUpload(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.uploadParts(ctx, key, uploadID, part, reader); err != nil {
+		// Abort even when the sync is cancelled, the parts of an upload take up storage until it is aborted
+		return "", errors.Join(err, c.abortMultipartUpload(context.WithoutCancel(ctx), key, uploadID))
+	}
+
+	logger.Debug().Str("key", key).Msg("Completed multipart upload")
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// PutObject stores body as the object at key in a single request. The storage verifies the body against
+// its SHA-256 checksum.
+func (c *Client) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	checksum := base64SHA256(body)
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("X-Amz-Checksum-Sha256", checksum)
+
+	resp, err := c.do(ctx, http.MethodPut, key, nil, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return compareChecksum(key, resp.Header.Get("X-Amz-Checksum-Sha256"), checksum)
+}
+
+// createMultipartUpload starts a multipart upload to key, with SHA-256 checksums, returning its upload ID.
+func (c *Client) createMultipartUpload(ctx context.Context, key string) (string, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("X-Amz-Checksum-Algorithm", "SHA256")
+
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, header, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result initiateMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrUpload, key, err)
+	}
+
+	if result.UploadID == "" {
+		return "", fmt.Errorf("%w: %s: no upload id", ErrUpload, key)
+	}
+
+	return result.UploadID, nil
+}
+
+// uploadParts uploads the first part and the rest of reader in parts to the multipart upload, and completes it.
+func (c *Client) uploadParts(ctx context.Context, key, uploadID string, part []byte, reader io.Reader) error {
+	parts := []completedPart{}
+	size := len(part)
+
+	for number := 1; size > 0; number++ {
+		if number > maxParts {
+			return fmt.Errorf("%w: %s: more than %d parts", ErrUpload, key, maxParts)
+		}
+
+		completed, err := c.uploadPart(ctx, key, uploadID, number, part[:size])
+		if err != nil {
+			return err
+		}
+
+		parts = append(parts, completed)
+
+		size, err = io.ReadFull(reader, part)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: %s: %w", ErrUpload, key, err)
+		}
+	}
+
+	return c.completeMultipartUpload(ctx, key, uploadID, parts)
+}
+
+// uploadPart uploads a part of a multipart upload, which the storage verifies against its SHA-256 checksum.
+func (c *Client) uploadPart(ctx context.Context, key, uploadID string, number int, body []byte) (completedPart, error) {
+	checksum := base64SHA256(body)
+
+	header := http.Header{}
+	header.Set("X-Amz-Checksum-Sha256", checksum)
+
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+
+	resp, err := c.do(ctx, http.MethodPut, key, query, header, body)
+	if err != nil {
+		return completedPart{}, err
+	}
+	defer resp.Body.Close()
+
+	if err := compareChecksum(key, resp.Header.Get("X-Amz-Checksum-Sha256"), checksum); err != nil {
+		return completedPart{}, err
+	}
+
+	return completedPart{PartNumber: number, ETag: resp.Header.Get("ETag"), ChecksumSHA256: checksum}, nil
+}
+
+// completeMultipartUpload assembles the parts into the object at key. The checksum the storage reports
+// for the object must be the checksum of the checksums of the parts.
+func (c *Client) completeMultipartUpload(ctx context.Context, key, uploadID string, parts []completedPart) error {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrUpload, key, err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result completeMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrUpload, key, err)
+	}
+
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("%w: %s: %s: %s", ErrUpload, key, result.Code, result.Message)
+	}
+
+	return compareChecksum(key, result.ChecksumSHA256, compositeChecksum(parts))
+}
+
+// abortMultipartUpload aborts the multipart upload, deleting the parts uploaded.
+func (c *Client) abortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close() //nolint:wrapcheck
+}
+
+// ListArchives returns the archives of the repository name below the prefix, in any format, newest first,
+// as archive.ListArchives lists the archives in a directory. The path of an archive is its key.
+func (c *Client) ListArchives(ctx context.Context, name string) ([]archive.Archive, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering ObjectStore:ListArchives")
+
+	keys, err := c.listKeys(ctx, Key(c.prefix, name))
+	if err != nil {
+		return nil, err
+	}
+
+	archives := []archive.Archive{}
+
+	for _, key := range keys {
+		archiveName, createdAt, ok := archive.ParseTargetPath(key)
+		if ok && archiveName == name {
+			archives = append(archives, archive.Archive{Path: key, CreatedAt: createdAt})
+		}
+	}
+
+	slices.SortFunc(archives, func(a, b archive.Archive) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	return archives, nil
+}
+
+// CheckArchive checks that the archive at key is stored with its manifest. The manifest is only stored
+// after the storage verified the checksums of the archive.
+func (c *Client) CheckArchive(ctx context.Context, key string) error {
+	for _, objectKey := range []string{key, archive.ManifestPath(key)} {
+		resp, err := c.do(ctx, http.MethodHead, objectKey, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		resp.Body.Close()
+	}
+
+	return nil
+}
+
+// DeleteArchive deletes the archive at key and its manifest.
+func (c *Client) DeleteArchive(ctx context.Context, key string) error {
+	for _, objectKey := range []string{key, archive.ManifestPath(key)} {
+		resp, err := c.do(ctx, http.MethodDelete, objectKey, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		resp.Body.Close()
+	}
+
+	return nil
+}
+
+// listKeys returns the keys of the objects starting with prefix, without descending into deeper folders.
+// The listing is followed over all its pages.
+func (c *Client) listKeys(ctx context.Context, prefix string) ([]string, error) {
+	keys := []string{}
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+
+	for {
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: list %s: %w", ErrRequest, prefix, err)
+		}
+
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+
+		if !result.IsTruncated {
+			return keys, nil
+		}
+
+		if result.NextContinuationToken == "" {
+			return nil, fmt.Errorf("%w: list %s: truncated without continuation token", ErrRequest, prefix)
+		}
+
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// do sends a signed request for the object at key, or for the bucket when key is empty, returning the response
+// of a successful request. A failed request is returned as an error carrying the S3 error code and message.
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	requestURL := c.endpoint
+	requestURL.Path += "/" + c.bucket
+
+	if key != "" {
+		requestURL.Path += "/" + key
+	}
+
+	requestURL.RawPath = encodePath(requestURL.Path)
+	requestURL.RawQuery = encodeQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+
+	req.URL = &requestURL
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := hexSHA256(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	c.signer.sign(req, payloadHash, time.Now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %s/%s: %w", ErrRequest, method, c.bucket, key, err)
+	}
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	// A HEAD response has no body to read the error from
+	var response errorResponse
+	_ = xml.NewDecoder(resp.Body).Decode(&response)
+
+	status := resp.Status
+	if response.Code != "" {
+		status += ": " + response.Code + ": " + response.Message
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w: %s %s/%s: %s", ErrRequest, ErrNotFound, method, c.bucket, key, status)
+	}
+
+	return nil, fmt.Errorf("%w: %s %s/%s: %s", ErrRequest, method, c.bucket, key, status)
+}
+
+// compareChecksum compares the checksum the storage reports for the object at key with the checksum uploaded.
+// Storages that do not report checksums are trusted to have verified them.
+func compareChecksum(key, reported, uploaded string) error {
+	if reported != "" && reported != uploaded {
+		return fmt.Errorf("%w: %s: sha256 %s, uploaded %s", ErrChecksumMismatch, key, reported, uploaded)
+	}
+
+	return nil
+}
+
+// compositeChecksum returns the checksum of a multipart object, the checksum of the checksums of its parts
+// followed by the number of parts.
+func compositeChecksum(parts []completedPart) string {
+	hash := sha256.New()
+
+	for _, part := range parts {
+		sum, _ := base64.StdEncoding.DecodeString(part.ChecksumSHA256)
+		hash.Write(sum)
+	}
+
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)) + "-" + strconv.Itoa(len(parts))
+}
+
+func base64SHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,351 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is an S3-compatible storage keeping the objects of the bucket backups in memory.
+// Like S3 it rejects requests whose payload does not match its checksums.
+type fakeS3 struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	objects       map[string][]byte
+	uploads       map[string]map[int][]byte
+	nextUploadID  int
+	aborted       int
+	pageSize      int
+	corruptPart   int  // The number of a part corrupted in transit, 0 for none
+	wrongChecksum bool // Whether completed uploads report a checksum other than the one of their parts
+}
+
+func newFakeS3(t *testing.T) *fakeS3 {
+	t.Helper()
+
+	fake := &fakeS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}, pageSize: 1000}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(fake.Close)
+
+	return fake
+}
+
+func newTestClient(t *testing.T, fake *fakeS3, prefix string) *Client {
+	t.Helper()
+
+	client, err := NewClient(fake.Client(), gpsconfig.S3Config{Endpoint: fake.URL, Bucket: "backups", Prefix: prefix}, "access", "secret")
+	require.NoError(t, err)
+
+	client.partSize = 1024
+
+	return client
+}
+
+func (f *fakeS3) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		writeError(w, http.StatusForbidden, "AccessDenied")
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+
+	if number, _ := strconv.Atoi(query.Get("partNumber")); number != 0 && number == f.corruptPart {
+		body[0] ^= 0xff
+	}
+
+	if r.Header.Get("X-Amz-Content-Sha256") != hexSHA256(body) {
+		writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+
+		return
+	}
+
+	if checksum := r.Header.Get("X-Amz-Checksum-Sha256"); checksum != "" && checksum != base64SHA256(body) {
+		writeError(w, http.StatusBadRequest, "BadDigest")
+
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != "backups" {
+		writeError(w, http.StatusNotFound, "NoSuchBucket")
+
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, query)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextUploadID++
+		uploadID := strconv.Itoa(f.nextUploadID)
+		f.uploads[uploadID] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[query.Get("uploadId")][number] = body
+		w.Header().Set("ETag", `"etag-`+query.Get("partNumber")+`"`)
+		w.Header().Set("X-Amz-Checksum-Sha256", base64SHA256(body))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		f.complete(w, key, query.Get("uploadId"), body)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		f.aborted++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		w.Header().Set("X-Amz-Checksum-Sha256", base64SHA256(body))
+	case r.Method == http.MethodHead:
+		if _, ok := f.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodGet:
+		if _, ok := f.objects[key]; !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+
+			return
+		}
+
+		_, _ = w.Write(f.objects[key])
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, query map[string][]string) {
+	prefix := query["prefix"][0]
+	keys := []string{}
+
+	for key := range f.objects {
+		// The delimiter groups keys in deeper folders into common prefixes, which are not listed here
+		if rest, ok := strings.CutPrefix(key, prefix); ok && !strings.Contains(rest, "/") {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	start := 0
+	if token := query["continuation-token"]; len(token) > 0 {
+		start, _ = strconv.Atoi(token[0])
+	}
+
+	end := min(start+f.pageSize, len(keys))
+
+	fmt.Fprint(w, "<ListBucketResult>")
+
+	for _, key := range keys[start:end] {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>", key)
+	}
+
+	if end < len(keys) {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", end)
+	}
+
+	fmt.Fprint(w, "</ListBucketResult>")
+}
+
+func (f *fakeS3) complete(w http.ResponseWriter, key, uploadID string, body []byte) {
+	var request completeMultipartUpload
+	if err := xml.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML")
+
+		return
+	}
+
+	object := []byte{}
+
+	for _, part := range request.Parts {
+		data, ok := f.uploads[uploadID][part.PartNumber]
+		if !ok || part.ChecksumSHA256 != base64SHA256(data) {
+			writeError(w, http.StatusBadRequest, "InvalidPart")
+
+			return
+		}
+
+		object = append(object, data...)
+	}
+
+	checksum := compositeChecksum(request.Parts)
+	if f.wrongChecksum {
+		checksum = base64SHA256(nil) + "-1"
+	}
+
+	f.objects[key] = object
+	delete(f.uploads, uploadID)
+
+	fmt.Fprintf(w, "<CompleteMultipartUploadResult><Key>%s</Key><ChecksumSHA256>%s</ChecksumSHA256></CompleteMultipartUploadResult>", key, checksum)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, http.StatusText(status))
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          gpsconfig.S3Config
+		accessKeyID  string
+		wantEndpoint string
+		wantRegion   string
+		wantErr      error
+	}{
+		{
+			name:         "aws in default region",
+			cfg:          gpsconfig.S3Config{Bucket: "backups"},
+			accessKeyID:  "access",
+			wantEndpoint: "https://s3.us-east-1.amazonaws.com",
+			wantRegion:   DefaultRegion,
+		},
+		{
+			name:         "minio endpoint",
+			cfg:          gpsconfig.S3Config{Bucket: "backups", Endpoint: "http://minio.local:9000/", Region: "eu-north-1"},
+			accessKeyID:  "access",
+			wantEndpoint: "http://minio.local:9000",
+			wantRegion:   "eu-north-1",
+		},
+		{
+			name:        "endpoint without scheme",
+			cfg:         gpsconfig.S3Config{Bucket: "backups", Endpoint: "minio.local:9000"},
+			accessKeyID: "access",
+			wantErr:     ErrInvalidEndpoint,
+		},
+		{
+			name:    "no credentials",
+			cfg:     gpsconfig.S3Config{Bucket: "backups"},
+			wantErr: ErrNoCredentials,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			client, err := NewClient(http.DefaultClient, tabletest.cfg, tabletest.accessKeyID, "secret")
+			if tabletest.wantErr != nil {
+				require.ErrorIs(t, err, tabletest.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.wantEndpoint, client.endpoint.String())
+			require.Equal(t, tabletest.wantRegion, client.signer.region)
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	require.Equal(t, "app.tar.gz", Key("", "app.tar.gz"))
+	require.Equal(t, "backups/gitlab/app.tar.gz", Key("/backups/gitlab/", "app.tar.gz"))
+}
+
+func TestClient_Upload(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int
+		corruptPart   int
+		wrongChecksum bool
+		wantErr       error
+	}{
+		{name: "single request", size: 1000},
+		{name: "empty object", size: 0},
+		{name: "exactly one part", size: 1024},
+		{name: "multipart", size: 2600},
+		{name: "part corrupted in transit", size: 2600, corruptPart: 2, wantErr: ErrRequest},
+		{name: "storage reports other checksum", size: 2600, wrongChecksum: true, wantErr: ErrChecksumMismatch},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			fake := newFakeS3(t)
+			fake.corruptPart = tabletest.corruptPart
+			fake.wrongChecksum = tabletest.wrongChecksum
+
+			content := bytes.Repeat([]byte("0123456789"), tabletest.size/10+1)[:tabletest.size]
+
+			checksum, err := newTestClient(t, fake, "").Upload(context.Background(), "app.tar.gz", bytes.NewReader(content))
+			if tabletest.wantErr != nil {
+				require.ErrorIs(t, err, tabletest.wantErr)
+				require.Equal(t, 1, fake.aborted)
+				require.Empty(t, fake.uploads)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			sum := sha256.Sum256(content)
+			require.Equal(t, hex.EncodeToString(sum[:]), checksum)
+			require.Equal(t, content, fake.objects["app.tar.gz"])
+			require.Empty(t, fake.uploads)
+		})
+	}
+}
+
+func TestClient_Archives(t *testing.T) {
+	fake := newFakeS3(t)
+	fake.pageSize = 2
+
+	now := time.Now()
+	newest := "gitlab/app" + archive.FormatArchiveTimestamp(now) + ".tar.gz"
+	older := "gitlab/app" + archive.FormatArchiveTimestamp(now.Add(-24*time.Hour)) + ".bundle.age"
+
+	for _, key := range []string{
+		newest,
+		archive.ManifestPath(newest),
+		older,
+		archive.ManifestPath(older),
+		"gitlab/app-two" + archive.FormatArchiveTimestamp(now) + ".tar.gz",
+		"gitlab/app/notes.txt",
+		"app" + archive.FormatArchiveTimestamp(now) + ".tar.gz",
+	} {
+		fake.objects[key] = []byte("content")
+	}
+
+	ctx := context.Background()
+	client := newTestClient(t, fake, "/gitlab/")
+
+	archives, err := client.ListArchives(ctx, "app")
+	require.NoError(t, err)
+	require.Len(t, archives, 2)
+	require.Equal(t, newest, archives[0].Path)
+	require.Equal(t, older, archives[1].Path)
+
+	require.NoError(t, client.CheckArchive(ctx, newest))
+
+	require.NoError(t, client.DeleteArchive(ctx, older))
+	require.NotContains(t, fake.objects, older)
+	require.NotContains(t, fake.objects, archive.ManifestPath(older))
+
+	delete(fake.objects, archive.ManifestPath(newest))
+	require.ErrorIs(t, client.CheckArchive(ctx, newest), ErrNotFound)
+}
@@ -0,0 +1,16 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package objectstore
+
+import "errors"
+
+var (
+	ErrChecksumMismatch = errors.New("object checksum does not match the upload")
+	ErrInvalidEndpoint  = errors.New("invalid object storage endpoint")
+	ErrNoCredentials    = errors.New("no object storage credentials")
+	ErrNotFound         = errors.New("object not found")
+	ErrRequest          = errors.New("object storage request failed")
+	ErrUpload           = errors.New("failed to upload object")
+)
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/mirror/lfs"
+	"itiquette/git-provider-sync/internal/model"
+)
+
+// Service writes the archives of an s3 mirror to its bucket. The repository is archived as an archive mirror
+// archives it, but streamed to the bucket instead of written to a file.
+type Service struct {
+	client     *Client
+	git        *archive.GitHandler
+	archiver   *archive.Handler
+	encryption *archive.Encryption
+}
+
+func NewService(client *Client, git *archive.GitHandler, archiver *archive.Handler) *Service {
+	return &Service{
+		client:   client,
+		git:      git,
+		archiver: archiver,
+	}
+}
+
+// WithEncryption sets the encryption the archiver encrypts with, to record it in the manifests.
+func (serv *Service) WithEncryption(encryption *archive.Encryption) *Service {
+	serv.encryption = encryption
+
+	return serv
+}
+
+// Pull implements interfaces.MirrorWriter.
+func (serv *Service) Pull(_ context.Context, _ model.PullOption) error {
+	return nil
+}
+
+// Push archives the repository to the object at the key of the push option, and stores its manifest next to it.
+// The manifest is stored last, so an archive without one is an incomplete upload.
+func (serv *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering ObjectStore:Push")
+	opt.DebugLog(ctx, logger).Msg("ObjectStore:Push")
+
+	name := repo.ProjectInfo().Name(ctx)
+
+	// Archive below the run's temporary directory when there is one, else below the system default
+	parentDir, _ := model.GetTmpDirPath(ctx)
+
+	storagePath, err := os.MkdirTemp(parentDir, "s3.*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", archive.ErrDirectoryCreation, err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(storagePath); err != nil {
+			logger.Warn().Err(err).Str("storagePath", storagePath).Msg("failed to remove archived repository")
+		}
+	}()
+
+	if err := serv.git.InitializeRepository(ctx, storagePath, repo); err != nil {
+		return fmt.Errorf("failed to initialize target repository: %w", err)
+	}
+
+	if opt.LFSObjectsDir != "" {
+		if err := lfs.CopyObjects(ctx, opt.LFSObjectsDir, lfs.RepositoryStore(storagePath)); err != nil {
+			return fmt.Errorf("failed to store lfs objects: %w", err)
+		}
+	}
+
+	checksum, err := serv.upload(ctx, storagePath, opt.Target, name)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := archive.NewManifestWithChecksum(storagePath, opt.Target, checksum, name, repo.ProjectInfo().HTTPSURL, model.CLIOptions(ctx).Version)
+	if err != nil {
+		return err
+	}
+
+	if archive.IsEncrypted(opt.Target) {
+		manifest.Encryption = serv.encryption.ManifestEncryption()
+	}
+
+	data, err := archive.EncodeManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := serv.client.PutObject(ctx, archive.ManifestPath(opt.Target), data, "application/json"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upload archives the bare repository at storagePath as name while uploading the archive to key,
+// returning the checksum of the archive.
+func (serv *Service) upload(ctx context.Context, storagePath, key, name string) (string, error) {
+	reader, writer := io.Pipe()
+	archived := make(chan error, 1)
+
+	go func() {
+		err := serv.archiver.WriteArchive(ctx, storagePath, writer, key, name)
+		writer.CloseWithError(err)
+		archived <- err
+	}()
+
+	checksum, err := serv.client.Upload(ctx, key, reader)
+
+	// A failed upload stops the archiver at its next write
+	reader.CloseWithError(io.ErrClosedPipe)
+
+	if archiveErr := <-archived; archiveErr != nil && err == nil {
+		err = archiveErr
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to upload archive %s: %w", key, err)
+	}
+
+	return checksum, nil
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package objectstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/mirror/gitlib"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// newBareRepository creates a bare repository with a commit on main.
+func newBareRepository(t *testing.T) string {
+	t.Helper()
+
+	worktreeDir := t.TempDir()
+
+	repo, err := git.PlainInit(worktreeDir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "file.txt"), []byte("content"), 0o600))
+
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	bareDir := filepath.Join(t.TempDir(), "app")
+	_, err = git.PlainClone(bareDir, true, &git.CloneOptions{URL: worktreeDir})
+	require.NoError(t, err)
+
+	return bareDir
+}
+
+func TestService_Upload(t *testing.T) {
+	repoDir := newBareRepository(t)
+
+	tests := []struct {
+		name        string
+		format      string
+		corruptPart int
+		wantErr     error
+	}{
+		{name: "tar.gz", format: gpsconfig.ArchiveFormatTarGz},
+		{name: "bundle", format: gpsconfig.ArchiveFormatBundle},
+		{name: "upload fails", format: gpsconfig.ArchiveFormatTarXz, corruptPart: 1, wantErr: ErrRequest},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			fake := newFakeS3(t)
+			fake.corruptPart = tabletest.corruptPart
+
+			// Parts this small upload every archive in several parts
+			client := newTestClient(t, fake, "gitlab")
+			client.partSize = 256
+
+			service := NewService(client, nil, archive.NewHandler())
+			key := Key("gitlab", archive.TargetPath("app", "", tabletest.format))
+
+			checksum, err := service.upload(context.Background(), repoDir, key, "app")
+			if tabletest.wantErr != nil {
+				require.ErrorIs(t, err, tabletest.wantErr)
+				require.NotContains(t, fake.objects, key)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			content := fake.objects[key]
+			sum := sha256.Sum256(content)
+			require.Equal(t, hex.EncodeToString(sum[:]), checksum)
+
+			// The uploaded archive restores like an archive written to a file
+			archivePath := filepath.Join(t.TempDir(), filepath.Base(key))
+			require.NoError(t, os.WriteFile(archivePath, content, 0o600))
+
+			branch, err := archive.NewReader().HeadBranch(context.Background(), archivePath)
+			require.NoError(t, err)
+			require.Equal(t, "master", branch)
+		})
+	}
+}
+
+func TestService_Push(t *testing.T) {
+	tests := []struct {
+		name        string
+		corruptPart int
+		wantErr     error
+	}{
+		{name: "pushed"},
+		{name: "upload fails", corruptPart: 1, wantErr: ErrRequest},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			goGitRepo, err := git.PlainOpen(newBareRepository(t))
+			require.NoError(t, err)
+
+			repo, err := model.NewRepository(goGitRepo)
+			require.NoError(t, err)
+
+			repo.ProjectMetaInfo = &model.ProjectInfo{OriginalName: "app", CleanName: "app", DefaultBranch: "master"}
+
+			fake := newFakeS3(t)
+			fake.corruptPart = tabletest.corruptPart
+
+			client := newTestClient(t, fake, "gitlab")
+			client.partSize = 256
+
+			tmpDir := t.TempDir()
+			ctx := context.WithValue(model.WithCLIOpt(context.Background(), model.CLIOption{}), model.TmpDirKey{}, tmpDir)
+
+			service := NewService(client, archive.NewGitHandler(gitlib.NewService()), archive.NewHandler())
+			key := Key("gitlab", archive.TargetPath("app", "", gpsconfig.ArchiveFormatTarGz))
+
+			err = service.Push(ctx, repo, model.PushOption{Target: key})
+			if tabletest.wantErr != nil {
+				require.ErrorIs(t, err, tabletest.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, fake.objects, archive.ManifestPath(key))
+			}
+
+			// The repository archived from is removed whether the push succeeds or not
+			entries, err := os.ReadDir(tmpDir)
+			require.NoError(t, err)
+			require.Empty(t, entries)
+		})
+	}
+}
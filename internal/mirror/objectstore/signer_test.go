@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package objectstore
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The requests and signatures of the AWS Signature Version 4 test suite.
+func TestSigner_Sign(t *testing.T) {
+	awsSigner := signer{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:          "us-east-1",
+		service:         "service",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		rawURL        string
+		wantSignature string
+	}{
+		{
+			name:          "get-vanilla",
+			rawURL:        "https://example.amazonaws.com/",
+			wantSignature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			rawURL:        "https://example.amazonaws.com/?" + encodeQuery(url.Values{"Param2": {"value2"}, "Param1": {"value1"}}),
+			wantSignature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tabletest.rawURL, nil)
+			require.NoError(t, err)
+
+			awsSigner.sign(req, hexSHA256(nil), now)
+
+			require.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+			require.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+				"SignedHeaders=host;x-amz-date, Signature="+tabletest.wantSignature, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	query := url.Values{"prefix": {"backups/app name"}, "list-type": {"2"}, "uploads": {""}, "token": {"a+b=c~"}}
+
+	require.Equal(t, "list-type=2&prefix=backups%2Fapp%20name&token=a%2Bb%3Dc~&uploads=", encodeQuery(query))
+	require.Equal(t, "/bucket/backups/app%20name_1.tar.gz", encodePath("/bucket/backups/app name_1.tar.gz"))
+}
@@ -18,13 +18,20 @@ type CLIOption struct {
 	AlphaNumHyphName    bool   // Whether to clean up repository names
 	ActiveFromLimit     string // Time limit for considering repositories as active
 	ConfigFileOnly      bool   // Whether to use only the configuration file
+	ContinueOnError     bool   // Whether to record failures and continue instead of aborting the run
 	ConfigFilePath      string // Path to the configuration file
 	DryRun              bool   // Whether to perform a dry run without making changes
 	ForcePush           bool   // Whether to force push changesj
+	Full                bool   // Whether to sync every repository, even those unchanged since the last run
 	IgnoreInvalidName   bool   // Whether to ignore invalid repository names
 	OutputFormat        string // Output format for log
+	Parallel            int    // Number of repositories to process concurrently, overrides config when > 0
+	Prune               bool   // Whether to delete refs at the mirrors that were deleted at the source
+	PruneDryRun         bool   // Whether to only list the refs pruning would delete
 	Quiet               bool   // Whether to suppress non-essential output
+	RetentionDryRun     bool   // Whether to only list the archives retention would delete
 	VerbosityWithCaller bool   // Whether to add caller information to log output
+	Version             string // The version of the tool, recorded in archive manifests
 }
 
 // CLIOptions retrieves the CLIOption from the given context.
@@ -65,9 +72,10 @@ func WithCLIOpt(ctx context.Context, opt CLIOption) context.Context {
 func (c CLIOption) String() string {
 	return fmt.Sprintf("CLIOption{ForcePush: %v, IgnoreInvalidName: %v, ASCIIName: %v, "+
 		"ActiveFromLimit: %s, DryRun: %v, ConfigFilePath: %s, ConfigFileOnly: %v, "+
-		"Quiet: %v, OutputFormat: %v}",
+		"Quiet: %v, OutputFormat: %v, Parallel: %d, ContinueOnError: %v, Full: %v, Prune: %v, PruneDryRun: %v, RetentionDryRun: %v}",
 		c.ForcePush, c.IgnoreInvalidName, c.AlphaNumHyphName, c.ActiveFromLimit,
-		c.DryRun, c.ConfigFilePath, c.ConfigFileOnly, c.Quiet, c.OutputFormat)
+		c.DryRun, c.ConfigFilePath, c.ConfigFileOnly, c.Quiet, c.OutputFormat, c.Parallel, c.ContinueOnError, c.Full,
+		c.Prune, c.PruneDryRun, c.RetentionDryRun)
 }
 
 // Example usage:
@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"context"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// Concurrency returns the number of repositories to process concurrently for a sync configuration.
+// The --parallel CLI option takes precedence over the concurrency setting of the sync configuration.
+// The result is never less than 1.
+func Concurrency(ctx context.Context, syncCfg config.SyncConfig) int {
+	if parallel := CLIOptions(ctx).Parallel; parallel > 0 {
+		return parallel
+	}
+
+	if syncCfg.Concurrency > 0 {
+		return syncCfg.Concurrency
+	}
+
+	return 1
+}
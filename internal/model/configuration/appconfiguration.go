@@ -5,11 +5,21 @@
 package model
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
+var (
+	ErrSSHPassphrase = errors.New("failed to read ssh passphrase")
+	ErrInvalidMaxAge = errors.New("invalid max_age, expected a positive duration such as 720h or 30d")
+)
+
 // AppConfiguration represents the entire application configuration.
 type AppConfiguration struct {
 	GitProviderSyncConfs map[string]Environment `koanf:"gitprovidersync"`
@@ -20,53 +30,108 @@ type Environment map[string]SyncConfig
 
 // BaseConfig holds common configuration fields for both source and mirror.
 type BaseConfig struct {
-	Auth         AuthConfig `koanf:"auth"`
-	Domain       string     `koanf:"domain"`
-	Owner        string     `koanf:"owner"`
-	OwnerType    string     `koanf:"owner_type"`
-	ProviderType string     `koanf:"provider_type"`
-	UseGitBinary bool       `koanf:"use_git_binary"`
+	Auth         AuthConfig       `koanf:"auth"`
+	Domain       string           `koanf:"domain"`
+	Encryption   EncryptionConfig `koanf:"encryption"`
+	Owner        string           `koanf:"owner"`
+	OwnerType    string           `koanf:"owner_type"`
+	ProviderType string           `koanf:"provider_type"`
+	URLTemplate  string           `koanf:"url_template"`
+	UseGitBinary bool             `koanf:"use_git_binary"`
 }
 
 // SyncConfig represents a source configuration with its mirrors and backups.
 type SyncConfig struct {
 	BaseConfig      `koanf:",squash"`
 	ActiveFromLimit string             `koanf:"active_from_limit"`
+	CacheDir        string             `koanf:"cache_dir"`
+	CloneMode       string             `koanf:"clone_mode"`
+	Concurrency     int                `koanf:"concurrency"`
+	FailFast        *bool              `koanf:"fail_fast"`
 	IncludeForks    bool               `koanf:"include_forks"`
+	Path            string             `koanf:"path"`
 	Repositories    RepositoriesOption `koanf:"repositories"`
+	Submodules      bool               `koanf:"submodules"`
+	URLs            []string           `koanf:"urls"`
 
 	Mirrors map[string]MirrorConfig `koanf:"mirrors"`
 }
 
 // AuthConfig combines HTTP and SSH configurations.
 type AuthConfig struct {
-	CertDirPath       string `koanf:"cert_dir_path"`
-	HTTPScheme        string `koanf:"http_scheme"`
-	RequestTimeout    int    `koanf:"request_timeout"`
-	Token             string `koanf:"token"`
-	Protocol          string `koanf:"protocol"`
-	ProxyURL          string `koanf:"proxy_url"`
-	SSHCommand        string `koanf:"ssh_command"`
-	SSHURLRewriteFrom string `koanf:"ssh_url_rewrite_from"`
-	SSHURLRewriteTo   string `koanf:"ssh_url_rewrite_to"`
+	CertDirPath             string `koanf:"cert_dir_path"`
+	GitHubAppID             int64  `koanf:"github_app_id"`
+	GitHubAppInstallationID int64  `koanf:"github_app_installation_id"`
+	GitHubAppPrivateKeyPath string `koanf:"github_app_private_key_path"`
+	HTTPScheme              string `koanf:"http_scheme"`
+	KnownHostsPath          string `koanf:"known_hosts_path"`
+	RequestTimeout          int    `koanf:"request_timeout"`
+	Token                   string `koanf:"token"`
+	TokenCommand            string `koanf:"token_command"`
+	TokenEnv                string `koanf:"token_env"`
+	TokenFile               string `koanf:"token_file"`
+	Protocol                string `koanf:"protocol"`
+	ProxyURL                string `koanf:"proxy_url"`
+	SSHCommand              string `koanf:"ssh_command"`
+	SSHPassphraseEnv        string `koanf:"ssh_passphrase_env"`
+	SSHPassphraseFile       string `koanf:"ssh_passphrase_file"`
+	SSHPrivateKeyPath       string `koanf:"ssh_private_key_path"`
+	SSHURLRewriteFrom       string `koanf:"ssh_url_rewrite_from"`
+	SSHURLRewriteTo         string `koanf:"ssh_url_rewrite_to"`
+	Username                string `koanf:"username"`
+}
+
+// EncryptionConfig configures the age encryption of the archives of an archive mirror or source.
+// A mirror encrypts to the X25519 recipients or with the passphrase, a source decrypts with the identities or the passphrase.
+type EncryptionConfig struct {
+	IdentityFile   string   `koanf:"identity_file"`
+	PassphraseFile string   `koanf:"passphrase_file"`
+	Recipients     []string `koanf:"recipients"`
+	RecipientsFile string   `koanf:"recipients_file"`
 }
 
 // MirrorConfig represents a mirror target configuration.
 type MirrorConfig struct {
 	BaseConfig `koanf:",squash"`
 	Path       string         `koanf:"path"`
+	S3         S3Config       `koanf:"s3"`
 	Settings   MirrorSettings `koanf:"settings"`
 }
 
+// S3Config configures the bucket of an S3-compatible object storage an s3 mirror writes its archives to.
+// The endpoint defaults to AWS S3 in the region, set it for MinIO, Ceph and other S3-compatible storages.
+type S3Config struct {
+	Bucket   string `koanf:"bucket"`
+	Endpoint string `koanf:"endpoint"`
+	Prefix   string `koanf:"prefix"`
+	Region   string `koanf:"region"`
+}
+
 // MirrorSettings represents mirror-specific settings.
 type MirrorSettings struct {
-	AlphaNumHyphName  bool   `koanf:"alphanumhyph_name"`
-	DescriptionPrefix string `koanf:"description_prefix"`
-	Disabled          bool   `koanf:"disabled"`
-	ForcePush         bool   `koanf:"force_push"`
-	GitHubUploadURL   string `koanf:"github_uploadurl"`
-	IgnoreInvalidName bool   `koanf:"ignore_invalid_name"`
-	Visibility        string `koanf:"visibility"`
+	AllowedSignersPath   string   `koanf:"allowed_signers_path"`
+	AlphaNumHyphName     bool     `koanf:"alphanumhyph_name"`
+	CompressionLevel     int      `koanf:"compression_level"`
+	DescriptionPrefix    string   `koanf:"description_prefix"`
+	Disabled             bool     `koanf:"disabled"`
+	ForcePush            bool     `koanf:"force_push"`
+	Format               string   `koanf:"format"`
+	GitHubUploadURL      string   `koanf:"github_uploadurl"`
+	IgnoreInvalidName    bool     `koanf:"ignore_invalid_name"`
+	KeepDaily            int      `koanf:"keep_daily"`
+	KeepLast             int      `koanf:"keep_last"`
+	KeepMonthly          int      `koanf:"keep_monthly"`
+	KeepWeekly           int      `koanf:"keep_weekly"`
+	LFS                  bool     `koanf:"lfs"`
+	MaxAge               string   `koanf:"max_age"`
+	Prune                bool     `koanf:"prune"`
+	Quarantine           bool     `koanf:"quarantine"`
+	RefSpecs             []string `koanf:"refspecs"`
+	RequireSigned        []string `koanf:"require_signed"`
+	RewriteSubmoduleURLs bool     `koanf:"rewrite_submodule_urls"`
+	SigningKeysPath      string   `koanf:"signing_keys_path"`
+	VerifyHistory        bool     `koanf:"verify_history"`
+	Visibility           string   `koanf:"visibility"`
 }
 
 // String methods for logging.
@@ -75,6 +140,35 @@ func (a AuthConfig) String() string {
 		a.Protocol, a.HTTPScheme, a.ProxyURL, a.CertDirPath, a.SSHCommand)
 }
 
+// IsGitHubApp reports whether the auth configuration authenticates as a GitHub App installation
+// instead of with a token.
+func (a AuthConfig) IsGitHubApp() bool {
+	return a.GitHubAppID != 0
+}
+
+// SSHPassphrase returns the passphrase of the SSH private key, read from the environment variable
+// or the file it is configured in. It is empty for a key without passphrase.
+func (a AuthConfig) SSHPassphrase() (string, error) {
+	switch {
+	case a.SSHPassphraseEnv != "":
+		passphrase, ok := os.LookupEnv(a.SSHPassphraseEnv)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s is not set", ErrSSHPassphrase, a.SSHPassphraseEnv)
+		}
+
+		return passphrase, nil
+	case a.SSHPassphraseFile != "":
+		content, err := os.ReadFile(a.SSHPassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrSSHPassphrase, err)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		return "", nil
+	}
+}
+
 func (s SyncConfig) String() string {
 	return fmt.Sprintf("SyncConfig: ProviderType: %s, Domain: %s, Owner: %s, OwnerType: %s",
 		s.ProviderType, s.Domain, s.Owner, s.OwnerType)
@@ -100,6 +194,11 @@ func (b *BaseConfig) FillDefaults() {
 	if b.Auth.RequestTimeout == 0 {
 		b.Auth.RequestTimeout = 30
 	}
+
+	// Bitbucket access tokens authenticate git over HTTPS with a fixed user name
+	if b.ProviderType == BITBUCKET && b.Auth.Username == "" {
+		b.Auth.Username = "x-token-auth"
+	}
 }
 
 func (b BaseConfig) GetDomain() string {
@@ -107,10 +206,16 @@ func (b BaseConfig) GetDomain() string {
 		switch b.ProviderType {
 		case "gitea":
 			return "gitea.com"
+		case "forgejo":
+			return "codeberg.org"
 		case "github":
 			return "github.com"
 		case "gitlab":
 			return "gitlab.com"
+		case "azuredevops":
+			return "dev.azure.com"
+		case "bitbucket":
+			return "bitbucket.org"
 		default:
 			return ""
 		}
@@ -122,6 +227,10 @@ func (b BaseConfig) GetDomain() string {
 func (s *SyncConfig) FillDefaults() {
 	s.BaseConfig.FillDefaults()
 
+	if s.Concurrency == 0 {
+		s.Concurrency = 1
+	}
+
 	// Loop over map with key for updating
 	for name, mirror := range s.Mirrors {
 		mirror.FillDefaults()
@@ -144,6 +253,52 @@ func (m *MirrorConfig) FillDefaults() {
 	m.Settings.Disabled = true
 }
 
+// IsFailFast reports whether a failing repository, mirror or source aborts the sync run.
+// It defaults to true when fail_fast is not configured.
+func (s SyncConfig) IsFailFast() bool {
+	return s.FailFast == nil || *s.FailFast
+}
+
+// IsPartialClone reports whether the source is cloned without all of its blobs or trees,
+// which git fetches on demand when they are needed.
+func (s SyncConfig) IsPartialClone() bool {
+	return s.CloneMode == CloneModeBlobless || s.CloneMode == CloneModeTreeless
+}
+
+// IsEnabled reports whether any encryption is configured.
+func (e EncryptionConfig) IsEnabled() bool {
+	return e.IdentityFile != "" || e.PassphraseFile != "" || len(e.Recipients) > 0 || e.RecipientsFile != ""
+}
+
+// HasRetention reports whether old archives of an archive mirror are deleted by a retention policy.
+func (s MirrorSettings) HasRetention() bool {
+	return s.KeepLast > 0 || s.KeepDaily > 0 || s.KeepWeekly > 0 || s.KeepMonthly > 0 || s.MaxAge != ""
+}
+
+// MaxAgeDuration returns max_age as a duration, 0 when it is not set.
+// Besides the Go duration units a number of days is accepted, such as 30d.
+func (s MirrorSettings) MaxAgeDuration() (time.Duration, error) {
+	if s.MaxAge == "" {
+		return 0, nil
+	}
+
+	if days, found := strings.CutSuffix(s.MaxAge, "d"); found {
+		count, err := strconv.Atoi(days)
+		if err != nil || count <= 0 {
+			return 0, fmt.Errorf("%w: max_age %s", ErrInvalidMaxAge, s.MaxAge)
+		}
+
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(s.MaxAge)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%w: max_age %s", ErrInvalidMaxAge, s.MaxAge)
+	}
+
+	return duration, nil
+}
+
 func (s SyncConfig) IsGroup() bool {
 	return s.OwnerType == "group"
 }
@@ -154,6 +309,9 @@ func (s SyncConfig) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 					Str("domain", s.GetDomain()).
 					Str("owner", s.Owner).
 					Str("ownerType", s.OwnerType).
+					Str("cacheDir", s.CacheDir).
+					Int("concurrency", s.Concurrency).
+					Bool("failFast", s.IsFailFast()).
 					Interface("repositories", s.Repositories).
 					Str("auth", s.Auth.String())
 
@@ -188,3 +346,8 @@ func (m MirrorConfig) IsArchive() bool {
 func (m MirrorConfig) IsDirectory() bool {
 	return m.ProviderType == "directory"
 }
+
+// WritesArchives reports whether the mirror writes archives, to a directory or to an object storage bucket.
+func (m MirrorConfig) WritesArchives() bool {
+	return m.ProviderType == ARCHIVE || m.ProviderType == S3
+}
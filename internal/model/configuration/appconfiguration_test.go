@@ -7,6 +7,7 @@ package model
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
@@ -39,6 +40,34 @@ func TestBaseConfig_GetDomain(t *testing.T) {
 			},
 			expected: "gitea.com",
 		},
+		{
+			name: "Forgejo default domain",
+			config: BaseConfig{
+				ProviderType: "forgejo",
+			},
+			expected: "codeberg.org",
+		},
+		{
+			name: "Gogs has no default domain",
+			config: BaseConfig{
+				ProviderType: "gogs",
+			},
+			expected: "",
+		},
+		{
+			name: "Azure DevOps default domain",
+			config: BaseConfig{
+				ProviderType: "azuredevops",
+			},
+			expected: "dev.azure.com",
+		},
+		{
+			name: "Bitbucket default domain",
+			config: BaseConfig{
+				ProviderType: "bitbucket",
+			},
+			expected: "bitbucket.org",
+		},
 		{
 			name: "Custom domain",
 			config: BaseConfig{
@@ -109,6 +138,23 @@ func TestBaseConfig_FillDefaults(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Bitbucket defaults to the access token user name",
+			config: BaseConfig{
+				ProviderType: "bitbucket",
+			},
+			expected: BaseConfig{
+				ProviderType: "bitbucket",
+				Domain:       "bitbucket.org",
+				OwnerType:    "group",
+				Auth: AuthConfig{
+					HTTPScheme:     HTTPS,
+					Protocol:       TLS,
+					RequestTimeout: 30,
+					Username:       "x-token-auth",
+				},
+			},
+		},
 	}
 
 	for _, tabletest := range tests {
@@ -313,6 +359,11 @@ func TestMirrorConfig_ProviderTypes(t *testing.T) {
 				ProviderType: "directory",
 			},
 		},
+		"s3": {
+			BaseConfig: BaseConfig{
+				ProviderType: "s3",
+			},
+		},
 		"git": {
 			BaseConfig: BaseConfig{
 				ProviderType: "github",
@@ -328,6 +379,12 @@ func TestMirrorConfig_ProviderTypes(t *testing.T) {
 
 	require.False(t, mirrors["git"].IsArchive())
 	require.False(t, mirrors["git"].IsDirectory())
+
+	require.True(t, mirrors["archive"].WritesArchives())
+	require.True(t, mirrors["s3"].WritesArchives())
+	require.False(t, mirrors["s3"].IsArchive())
+	require.False(t, mirrors["directory"].WritesArchives())
+	require.False(t, mirrors["git"].WritesArchives())
 }
 func TestSyncConfig_DebugLogNoTokens(t *testing.T) {
 	// Create a config with a token that should be hidden
@@ -376,3 +433,33 @@ func TestSyncConfig_DebugLogNoTokens(t *testing.T) {
 	require.Contains(t, logOutput, "mirror_mirror1")
 	require.Contains(t, logOutput, "gitlab.com")
 }
+
+func TestMirrorSettings_MaxAgeDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxAge  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "not set", maxAge: ""},
+		{name: "go duration", maxAge: "36h", want: 36 * time.Hour},
+		{name: "days", maxAge: "30d", want: 30 * 24 * time.Hour},
+		{name: "invalid days", maxAge: "xd", wantErr: true},
+		{name: "negative", maxAge: "-1h", wantErr: true},
+		{name: "invalid", maxAge: "month", wantErr: true},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			got, err := MirrorSettings{MaxAge: tabletest.maxAge}.MaxAgeDuration()
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, ErrInvalidMaxAge)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.want, got)
+		})
+	}
+}
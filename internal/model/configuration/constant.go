@@ -6,11 +6,18 @@ package model
 
 // Mirror target types.
 const (
-	GITHUB    string = "github"
-	GITLAB    string = "gitlab"
-	GITEA     string = "gitea"
-	ARCHIVE   string = "archive"
-	DIRECTORY string = "directory"
+	GITHUB          string = "github"
+	GITLAB          string = "gitlab"
+	GITEA           string = "gitea"
+	FORGEJO         string = "forgejo"
+	GOGS            string = "gogs"
+	AZUREDEVOPS     string = "azuredevops"
+	BITBUCKET       string = "bitbucket"
+	BITBUCKETSERVER string = "bitbucketserver"
+	GIT             string = "git"
+	ARCHIVE         string = "archive"
+	DIRECTORY       string = "directory"
+	S3              string = "s3"
 )
 
 // Git branch.
@@ -39,3 +46,19 @@ const (
 	USER  string = "user"
 	GROUP string = "group"
 )
+
+// Source clone modes.
+const (
+	CloneModeFull     string = "full"
+	CloneModeShallow  string = "shallow"
+	CloneModeBlobless string = "blobless"
+	CloneModeTreeless string = "treeless"
+)
+
+// Archive mirror formats.
+const (
+	ArchiveFormatTarGz  string = "tar.gz"
+	ArchiveFormatTarZst string = "tar.zst"
+	ArchiveFormatTarXz  string = "tar.xz"
+	ArchiveFormatBundle string = "bundle"
+)
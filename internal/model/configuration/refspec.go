@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidRefSpec = errors.New("invalid refspec")
+
+const (
+	refSpecExclude  = "^"
+	refSpecWildcard = "*"
+	refsPrefix      = "refs/"
+)
+
+// DefaultRefSpecs returns the refspecs pushed to a mirror without configured refspecs, all branches and tags.
+func DefaultRefSpecs() []string {
+	return []string{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}
+}
+
+// ValidateRefSpec validates a refspec of the mirror refspecs setting. It is either
+// <src>:<dst>, pushing the refs matching src to dst, <src>, short for <src>:<src>,
+// or ^<src>, excluding the refs matching src. Both sides are full ref names with at most one wildcard,
+// which matches any part of a name, slashes included. Force pushes are configured with force_push.
+func ValidateRefSpec(refSpec string) error {
+	src, dst, exclude, err := parseRefSpec(refSpec)
+	if err != nil {
+		return err
+	}
+
+	if exclude {
+		return validateRefPattern(refSpec, src)
+	}
+
+	if err := validateRefPattern(refSpec, src); err != nil {
+		return err
+	}
+
+	if err := validateRefPattern(refSpec, dst); err != nil {
+		return err
+	}
+
+	if strings.Count(src, refSpecWildcard) != strings.Count(dst, refSpecWildcard) {
+		return fmt.Errorf("%w: %s: either both or neither side must have a wildcard", ErrInvalidRefSpec, refSpec)
+	}
+
+	return nil
+}
+
+// ExpandRefSpecs resolves refspecs against ref names to a refspec per ref, <ref>:<destination>,
+// so both git backends push exactly the same refs. A ref is pushed by the first refspec matching it,
+// unless an exclude refspec matches it.
+//
+// Example:
+//
+//	ExpandRefSpecs([]string{"refs/heads/release/*", "^refs/heads/release/old-*"},
+//		[]string{"refs/heads/main", "refs/heads/release/1.0", "refs/heads/release/old-0.9"})
+//	// [refs/heads/release/1.0:refs/heads/release/1.0]
+func ExpandRefSpecs(refSpecs, refNames []string) []string {
+	sorted := append([]string(nil), refNames...)
+	sort.Strings(sorted)
+
+	expanded := []string{}
+
+	for _, refName := range sorted {
+		if dst, ok := matchRefSpecs(refSpecs, refName); ok {
+			expanded = append(expanded, refName+":"+dst)
+		}
+	}
+
+	return expanded
+}
+
+// StaleRefs returns the target refs a prune deletes: the refs under the destinations of the refspecs
+// that pushing refNames with the refspecs does not write, in order.
+//
+// Example:
+//
+//	StaleRefs(DefaultRefSpecs(), []string{"refs/heads/main"}, []string{"refs/heads/main", "refs/heads/gone", "refs/notes/commits"})
+//	// [refs/heads/gone]
+func StaleRefs(refSpecs, refNames, targetRefNames []string) []string {
+	pushed := map[string]bool{}
+
+	for _, refSpec := range ExpandRefSpecs(refSpecs, refNames) {
+		_, dst, _ := strings.Cut(refSpec, ":")
+		pushed[dst] = true
+	}
+
+	sorted := append([]string(nil), targetRefNames...)
+	sort.Strings(sorted)
+
+	stale := []string{}
+
+	for _, targetRefName := range sorted {
+		if !pushed[targetRefName] && matchesDestination(refSpecs, targetRefName) {
+			stale = append(stale, targetRefName)
+		}
+	}
+
+	return stale
+}
+
+// MirroredRefPatterns returns the ref patterns the mirrors of the sync push beyond branches and tags,
+// such as refs/notes/*, which a source clone has to fetch as well.
+func (s SyncConfig) MirroredRefPatterns() []string {
+	seen := map[string]bool{}
+	patterns := []string{}
+
+	for _, mirrorCfg := range s.Mirrors {
+		for _, refSpec := range mirrorCfg.Settings.RefSpecs {
+			src, _, exclude, err := parseRefSpec(refSpec)
+			if err != nil || exclude || seen[src] || strings.HasPrefix(src, "refs/heads/") || strings.HasPrefix(src, "refs/tags/") {
+				continue
+			}
+
+			seen[src] = true
+			patterns = append(patterns, src)
+		}
+	}
+
+	sort.Strings(patterns)
+
+	return patterns
+}
+
+// MatchesRefPatterns reports whether refName matches one of the ref patterns,
+// full ref names with at most one wildcard as in refspecs.
+func MatchesRefPatterns(patterns []string, refName string) bool {
+	for _, pattern := range patterns {
+		if _, ok := matchRefPattern(pattern, refName); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchRefSpecs(refSpecs []string, refName string) (string, bool) {
+	for _, refSpec := range refSpecs {
+		if src, _, exclude, err := parseRefSpec(refSpec); err == nil && exclude {
+			if _, ok := matchRefPattern(src, refName); ok {
+				return "", false
+			}
+		}
+	}
+
+	for _, refSpec := range refSpecs {
+		src, dst, exclude, err := parseRefSpec(refSpec)
+		if err != nil || exclude {
+			continue
+		}
+
+		if match, ok := matchRefPattern(src, refName); ok {
+			return strings.Replace(dst, refSpecWildcard, match, 1), true
+		}
+	}
+
+	return "", false
+}
+
+func matchesDestination(refSpecs []string, refName string) bool {
+	for _, refSpec := range refSpecs {
+		if _, dst, exclude, err := parseRefSpec(refSpec); err == nil && !exclude {
+			if _, ok := matchRefPattern(dst, refName); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchRefPattern matches a ref name against a pattern, returning the part matched by the wildcard.
+func matchRefPattern(pattern, refName string) (string, bool) {
+	prefix, suffix, wildcard := strings.Cut(pattern, refSpecWildcard)
+	if !wildcard {
+		return "", pattern == refName
+	}
+
+	if len(refName) <= len(prefix)+len(suffix) || !strings.HasPrefix(refName, prefix) || !strings.HasSuffix(refName, suffix) {
+		return "", false
+	}
+
+	return refName[len(prefix) : len(refName)-len(suffix)], true
+}
+
+func parseRefSpec(refSpec string) (string, string, bool, error) {
+	if refSpec == "" {
+		return "", "", false, fmt.Errorf("%w: empty refspec", ErrInvalidRefSpec)
+	}
+
+	if strings.HasPrefix(refSpec, "+") {
+		return "", "", false, fmt.Errorf("%w: %s: use force_push to force push", ErrInvalidRefSpec, refSpec)
+	}
+
+	if pattern, exclude := strings.CutPrefix(refSpec, refSpecExclude); exclude {
+		if strings.Contains(pattern, ":") {
+			return "", "", false, fmt.Errorf("%w: %s: an exclude refspec has no destination", ErrInvalidRefSpec, refSpec)
+		}
+
+		return pattern, "", true, nil
+	}
+
+	src, dst, found := strings.Cut(refSpec, ":")
+	if !found {
+		dst = src
+	}
+
+	return src, dst, false, nil
+}
+
+func validateRefPattern(refSpec, pattern string) error {
+	if !strings.HasPrefix(pattern, refsPrefix) || len(pattern) == len(refsPrefix) {
+		return fmt.Errorf("%w: %s: ref names must start with refs/", ErrInvalidRefSpec, refSpec)
+	}
+
+	if strings.Count(pattern, refSpecWildcard) > 1 {
+		return fmt.Errorf("%w: %s: at most one wildcard per side", ErrInvalidRefSpec, refSpec)
+	}
+
+	if strings.Contains(pattern, "..") || strings.Contains(pattern, "//") || strings.HasSuffix(pattern, "/") ||
+		strings.ContainsAny(pattern, " \t~^:?[\\") {
+		return fmt.Errorf("%w: %s: not a valid ref name", ErrInvalidRefSpec, refSpec)
+	}
+
+	return nil
+}
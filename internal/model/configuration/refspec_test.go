@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRefSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		refSpec string
+		wantErr bool
+	}{
+		{name: "branches", refSpec: "refs/heads/*:refs/heads/*"},
+		{name: "source only", refSpec: "refs/notes/*"},
+		{name: "wildcard inside a name", refSpec: "refs/tags/v*:refs/tags/v*"},
+		{name: "namespace", refSpec: "refs/merge-requests/*:refs/mirror/merge-requests/*"},
+		{name: "exact ref", refSpec: "refs/heads/main:refs/heads/trunk"},
+		{name: "exclude", refSpec: "^refs/heads/wip/*"},
+		{name: "empty", refSpec: "", wantErr: true},
+		{name: "force", refSpec: "+refs/heads/*:refs/heads/*", wantErr: true},
+		{name: "short name", refSpec: "main:main", wantErr: true},
+		{name: "wildcard on one side", refSpec: "refs/heads/*:refs/heads/main", wantErr: true},
+		{name: "two wildcards", refSpec: "refs/*/*:refs/*/*", wantErr: true},
+		{name: "exclude with destination", refSpec: "^refs/heads/wip/*:refs/heads/wip/*", wantErr: true},
+		{name: "invalid ref name", refSpec: "refs/heads/a..b", wantErr: true},
+		{name: "delete", refSpec: ":refs/heads/main", wantErr: true},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			err := ValidateRefSpec(tabletest.refSpec)
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, ErrInvalidRefSpec)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExpandRefSpecs(t *testing.T) {
+	refNames := []string{
+		"refs/heads/main",
+		"refs/heads/release/1.0",
+		"refs/heads/release/old-0.9",
+		"refs/tags/v1.0",
+		"refs/tags/nightly",
+		"refs/notes/commits",
+		"refs/merge-requests/1/head",
+	}
+
+	tests := []struct {
+		name     string
+		refSpecs []string
+		want     []string
+	}{
+		{
+			name:     "release branches and version tags",
+			refSpecs: []string{"refs/heads/release/*", "refs/tags/v*:refs/tags/v*", "^refs/heads/release/old-*"},
+			want:     []string{"refs/heads/release/1.0:refs/heads/release/1.0", "refs/tags/v1.0:refs/tags/v1.0"},
+		},
+		{
+			name:     "notes and merge requests into a namespace",
+			refSpecs: []string{"refs/notes/*", "refs/merge-requests/*:refs/mirror/merge-requests/*"},
+			want:     []string{"refs/merge-requests/1/head:refs/mirror/merge-requests/1/head", "refs/notes/commits:refs/notes/commits"},
+		},
+		{
+			name:     "first matching refspec wins",
+			refSpecs: []string{"refs/heads/main:refs/heads/trunk", "refs/heads/*:refs/heads/*", "^refs/heads/release/*"},
+			want:     []string{"refs/heads/main:refs/heads/trunk"},
+		},
+		{
+			name:     "nothing matches",
+			refSpecs: []string{"refs/heads/feature/*"},
+			want:     []string{},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, ExpandRefSpecs(tabletest.refSpecs, refNames))
+		})
+	}
+}
+
+func TestMirroredRefPatterns(t *testing.T) {
+	syncCfg := SyncConfig{Mirrors: map[string]MirrorConfig{
+		"a": {Settings: MirrorSettings{RefSpecs: []string{"refs/heads/*", "refs/notes/*", "^refs/notes/old"}}},
+		"b": {Settings: MirrorSettings{RefSpecs: []string{"refs/merge-requests/*:refs/mirror/merge-requests/*", "refs/notes/*"}}},
+		"c": {},
+	}}
+
+	require.Equal(t, []string{"refs/merge-requests/*", "refs/notes/*"}, syncCfg.MirroredRefPatterns())
+}
+
+func TestMatchesRefPatterns(t *testing.T) {
+	patterns := []string{"refs/heads/main", "refs/heads/release/*"}
+
+	require.True(t, MatchesRefPatterns(patterns, "refs/heads/main"))
+	require.True(t, MatchesRefPatterns(patterns, "refs/heads/release/1.0"))
+	require.False(t, MatchesRefPatterns(patterns, "refs/heads/feature"))
+	require.False(t, MatchesRefPatterns(nil, "refs/heads/main"))
+}
+
+func TestStaleRefs(t *testing.T) {
+	refNames := []string{"refs/heads/main", "refs/heads/release/1.0", "refs/tags/v1.0"}
+	targetRefNames := []string{
+		"refs/heads/main",
+		"refs/heads/gone",
+		"refs/heads/release/0.9",
+		"refs/tags/v0.9",
+		"refs/notes/commits",
+		"refs/merge-requests/1/head",
+	}
+
+	tests := []struct {
+		name     string
+		refSpecs []string
+		want     []string
+	}{
+		{
+			name:     "all branches and tags",
+			refSpecs: DefaultRefSpecs(),
+			want:     []string{"refs/heads/gone", "refs/heads/release/0.9", "refs/tags/v0.9"},
+		},
+		{
+			name:     "only refs under the destinations",
+			refSpecs: []string{"refs/heads/release/*"},
+			want:     []string{"refs/heads/release/0.9"},
+		},
+		{
+			name:     "excluded refs are pruned",
+			refSpecs: []string{"refs/heads/*", "^refs/heads/release/*"},
+			want:     []string{"refs/heads/gone", "refs/heads/release/0.9"},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, StaleRefs(tabletest.refSpecs, refNames, targetRefNames))
+		})
+	}
+}
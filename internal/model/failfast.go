@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"context"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// ContinueOnError reports whether failures should be recorded and the sync run continued
+// instead of aborting at the first failure.
+// It is enabled by the --continue-on-error CLI option or by setting fail_fast to false in the sync configuration.
+func ContinueOnError(ctx context.Context, syncCfg config.SyncConfig) bool {
+	return CLIOptions(ctx).ContinueOnError || !syncCfg.IsFailFast()
+}
+
+// Prune reports whether refs deleted at the source are deleted at the mirror too.
+// It is enabled by the --prune CLI option or by the prune setting of the mirror.
+func Prune(ctx context.Context, mirrorCfg config.MirrorConfig) bool {
+	return CLIOptions(ctx).Prune || mirrorCfg.Settings.Prune
+}
@@ -27,6 +27,18 @@ type GitProviderClientOption struct {
 	Repositories model.RepositoriesOption
 
 	UploadURL string
+
+	// URLs are the clone URLs of a plain git source.
+	URLs []string
+
+	// URLTemplate expands to the clone URL of a plain git repository, see plaingit.ExpandURLTemplate.
+	URLTemplate string
+
+	// Path is the directory an archive or directory source reads its repositories from.
+	Path string
+
+	// Encryption decrypts the encrypted archives of an archive source.
+	Encryption model.EncryptionConfig
 }
 
 // String provides a safe string representation without exposing sensitive data.
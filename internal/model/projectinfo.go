@@ -44,6 +44,10 @@ type ProjectInfo struct {
 	ProjectID string
 
 	ASCIIName bool
+
+	// RefTips holds the source ref tips listed before cloning, keyed by ref name.
+	// It is nil when the refs were not listed.
+	RefTips map[string]string
 }
 
 func (rm *ProjectInfo) SetASCIIName(name bool) {
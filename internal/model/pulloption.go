@@ -20,6 +20,9 @@ type PullOption struct {
 	AuthCfg   model.AuthConfig
 	Path      string // For Dir and Arch
 	TargetDir string
+
+	Prune       bool // Whether to delete the branches and tags the remote no longer has
+	PruneDryRun bool // Whether to only list the branches and tags pruning would delete
 }
 
 func (po PullOption) String() string {
@@ -35,7 +38,9 @@ func (po PullOption) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 				Str("name", po.Name).
 				Str("url", po.URL).
 				Str("git_option", po.SyncCfg.String()).
-				Str("ssh_client", po.AuthCfg.String())
+				Str("ssh_client", po.AuthCfg.String()).
+				Bool("prune", po.Prune).
+				Bool("prune_dry_run", po.PruneDryRun)
 }
 
 // NewPullOption creates a new PullOption.
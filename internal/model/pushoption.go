@@ -23,6 +23,9 @@ type PushOption struct {
 	Prune    bool     // Whether to prune remote branches that no longer exist locally
 	RefSpecs []string // The reference specifications to push
 	Target   string   // The URL of the target repository
+
+	LFSObjectsDir string           // The LFS objects to store in a directory or archive target, empty when LFS is not mirrored
+	SourceAuthCfg model.AuthConfig // The source credentials a partial clone fetches its missing objects with while pushing
 }
 
 func (po PushOption) String() string {
@@ -41,6 +44,7 @@ func (po PushOption) DebugLog(ctx context.Context, logger *zerolog.Logger) *zero
 				Strs("refspecs", po.RefSpecs).
 				Bool("prune", po.Prune).
 				Bool("force", po.Force).
+				Str("lfs_objects_dir", po.LFSObjectsDir).
 				Str("auth_confg", po.AuthCfg.String())
 }
 
@@ -50,13 +54,19 @@ func (po PushOption) DebugLog(ctx context.Context, logger *zerolog.Logger) *zero
 //
 // Parameters:
 //   - target: The URL of the target repository.
+//   - refSpecs: The refspecs to push, all branches and tags when empty.
 //   - prune: Whether to prune remote branches.
 //   - force: Whether to force push.
 //
 // Returns:
 //   - A new PushOption struct configured with the provided options.
-func NewPushOption(target string, prune, force bool, authCfg model.AuthConfig) PushOption {
-	refSpecs := []string{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"} //TODO: add bug report
+func NewPushOption(target string, refSpecs []string, prune, force bool, authCfg model.AuthConfig) PushOption {
+	if len(refSpecs) == 0 {
+		refSpecs = model.DefaultRefSpecs()
+	} else {
+		refSpecs = append([]string(nil), refSpecs...)
+	}
+
 	if force {
 		for i, spec := range refSpecs {
 			if !strings.HasPrefix(spec, "^") {
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Submodule is a submodule declared in the .gitmodules of a repository's default branch.
+type Submodule struct {
+	Name string // The name of the submodule, its section in .gitmodules
+	Path string // The path of the submodule in the repository
+	URL  string // The URL as declared, possibly relative to the URL of the repository
+
+	Host           string // The host of the resolved URL
+	Owner          string // The owner of the submodule repository, the resolved URL path without the repository name
+	RepositoryName string // The name of the submodule repository
+}
+
+// NewSubmodule creates a Submodule, resolving a relative URL against the URL of the repository
+// declaring it, as git does.
+//
+// Example:
+//
+//	NewSubmodule("lib", "vendor/lib", "../lib.git", "https://github.com/owner/app.git")
+//	// Host: github.com, Owner: owner, RepositoryName: lib
+func NewSubmodule(name, submodulePath, rawURL, repositoryURL string) Submodule {
+	submodule := Submodule{Name: name, Path: submodulePath, URL: rawURL}
+
+	host, urlPath := splitGitURL(rawURL)
+	if strings.HasPrefix(rawURL, "./") || strings.HasPrefix(rawURL, "../") {
+		var repositoryPath string
+
+		host, repositoryPath = splitGitURL(repositoryURL)
+		urlPath = path.Join(repositoryPath, rawURL)
+	}
+
+	urlPath = strings.TrimSuffix(strings.Trim(urlPath, "/"), ".git")
+	if host == "" || urlPath == "" {
+		return submodule
+	}
+
+	submodule.Host = host
+	submodule.RepositoryName = path.Base(urlPath)
+
+	if owner := path.Dir(urlPath); owner != "." {
+		submodule.Owner = owner
+	}
+
+	return submodule
+}
+
+// BelongsTo reports whether the submodule repository is a repository of owner on host.
+func (s Submodule) BelongsTo(host, owner string) bool {
+	return s.RepositoryName != "" && strings.EqualFold(s.Host, host) && strings.EqualFold(s.Owner, owner)
+}
+
+// splitGitURL splits a git URL, either a URL with a scheme or the scp-like SSH form user@host:path,
+// into its host and path.
+func splitGitURL(rawURL string) (string, string) {
+	if !strings.Contains(rawURL, "://") {
+		if before, after, found := strings.Cut(rawURL, ":"); found && !strings.Contains(before, "/") {
+			_, host, _ := strings.Cut(before, "@")
+			if host == "" {
+				host = before
+			}
+
+			return host, after
+		}
+
+		return "", ""
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+
+	return parsedURL.Hostname(), parsedURL.Path
+}
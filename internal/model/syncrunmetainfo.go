@@ -9,8 +9,34 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 )
 
+// Failure categories used to classify errors recorded with AddError.
+const (
+	FailureClone         = "clone"
+	FailureInvalid       = "invalid"
+	FailureCreate        = "create"
+	FailurePush          = "push"
+	FailureProtect       = "protect"
+	FailureDefaultBranch = "default-branch"
+	FailureLFS           = "lfs"
+	FailureVerify        = "verify"
+	FailureRetention     = "retention"
+	FailureMirror        = "mirror"
+	FailureSource        = "source"
+)
+
+// SyncError describes a failure recorded during a synchronization run
+// that did not abort the run.
+type SyncError struct {
+	Category   string // One of the Failure* categories
+	Source     string // The source the failure occurred for
+	Target     string // The mirror target the failure occurred for, empty for source failures
+	Repository string // The repository name, empty for source and mirror failures
+	Err        error  // The underlying error
+}
+
 // SyncRunMetainfoKey is used as a key for context values.
 // It allows SyncRunMetainfo to be stored and retrieved from a context.Context.
 type SyncRunMetainfoKey struct{}
@@ -19,7 +45,12 @@ type SyncRunMetainfoKey struct{}
 // It captures essential information about the synchronization process,
 // including source and target identifiers, total items processed,
 // and any failures encountered during the process.
+//
+// A SyncRunMetainfo is shared by all workers of a sync run and must be
+// passed by pointer. Use the methods to read or update Synced and Fail.
 type SyncRunMetainfo struct {
+	mu sync.Mutex
+
 	// CtxID is a unique identifier for the synchronization context.
 	CtxID int
 
@@ -32,10 +63,19 @@ type SyncRunMetainfo struct {
 	// Total is the total number of items processed during the synchronization.
 	Total int
 
+	// Synced is the number of items successfully pushed to the target.
+	Synced int
+
 	// Fail is a map that stores any failures encountered during synchronization.
 	// The key is typically an identifier for the failure type or location,
 	// and the value is a slice of strings providing details about the failures.
 	Fail *map[string][]string
+
+	// errs holds the failures recorded with AddError.
+	errs []SyncError
+
+	// parent receives a copy of every error recorded with AddError.
+	parent *SyncRunMetainfo
 }
 
 // String provides a string representation of SyncRunMetainfo.
@@ -44,10 +84,13 @@ type SyncRunMetainfo struct {
 //
 // Returns:
 //   - A string representation of the SyncRunMetainfo instance.
-func (s SyncRunMetainfo) String() string {
+func (s *SyncRunMetainfo) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	var failInfo string
 
-	if len(*s.Fail) > 0 {
+	if s.Fail != nil && len(*s.Fail) > 0 {
 		var failures []string
 		for key, values := range *s.Fail {
 			failures = append(failures, fmt.Sprintf("%s: %s", key, strings.Join(values, ", ")))
@@ -58,8 +101,8 @@ func (s SyncRunMetainfo) String() string {
 		failInfo = "No failures"
 	}
 
-	return fmt.Sprintf("SyncRunMetainfo{CtxID: %d, Source: %s, Target: %s, Total: %d, %s}",
-		s.CtxID, s.Source, s.Target, s.Total, failInfo)
+	return fmt.Sprintf("SyncRunMetainfo{CtxID: %d, Source: %s, Target: %s, Total: %d, Synced: %d, %s}",
+		s.CtxID, s.Source, s.Target, s.Total, s.Synced, failInfo)
 }
 
 // NewSyncRunMetainfo creates a new SyncRunMetainfo instance.
@@ -93,8 +136,12 @@ func NewSyncRunMetainfo(ctxID int, source, target string, total int) *SyncRunMet
 //   - key: A string representing the type or location of the failure.
 //   - value: A string providing details about the failure.
 //
-// Note: This method modifies the Fail map of the SyncRunMetainfo instance.
+// Note: This method modifies the Fail map of the SyncRunMetainfo instance
+// and is safe for concurrent use.
 func (s *SyncRunMetainfo) AddFailure(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.Fail == nil {
 		s.Fail = &map[string][]string{}
 	}
@@ -102,11 +149,90 @@ func (s *SyncRunMetainfo) AddFailure(key, value string) {
 	(*s.Fail)[key] = append((*s.Fail)[key], value)
 }
 
-func ContainsFailure(ctx context.Context, name string) bool {
-	if meta, ok := ctx.Value(SyncRunMetainfoKey{}).(SyncRunMetainfo); ok {
-		failList := (*meta.Fail)["invalid"]
+// Failures returns a copy of the failure entries recorded for the given key.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) Failures(key string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Fail == nil {
+		return nil
+	}
+
+	return slices.Clone((*s.Fail)[key])
+}
+
+// HasFailures reports whether any failure has been recorded.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) HasFailures() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Fail != nil && len(*s.Fail) > 0
+}
 
-		return slices.Contains(failList, name)
+// IncrementSynced increases the number of successfully synced items by one.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) IncrementSynced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Synced++
+}
+
+// SyncedCount returns the number of successfully synced items.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) SyncedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Synced
+}
+
+// AddError records a failure that was isolated instead of aborting the run.
+// The error is also recorded in the parent SyncRunMetainfo, if any.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) AddError(syncErr SyncError) {
+	s.mu.Lock()
+	s.errs = append(s.errs, syncErr)
+	parent := s.parent
+	s.mu.Unlock()
+
+	if parent != nil {
+		parent.AddError(syncErr)
+	}
+}
+
+// Errors returns a copy of the failures recorded with AddError, in the order they were recorded.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) Errors() []SyncError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.errs)
+}
+
+// SetParent makes errors recorded in s also be recorded in parent.
+// It is used to aggregate the errors of every mirror run into the metainfo of the whole sync run.
+func (s *SyncRunMetainfo) SetParent(parent *SyncRunMetainfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.parent = parent
+}
+
+// RecordError records syncErr in the SyncRunMetainfo stored in ctx, if any.
+func RecordError(ctx context.Context, syncErr SyncError) {
+	if meta, ok := ctx.Value(SyncRunMetainfoKey{}).(*SyncRunMetainfo); ok {
+		meta.AddError(syncErr)
+	}
+}
+
+// ContainsFailure reports whether name has been recorded as an invalid repository
+// in the SyncRunMetainfo stored in ctx.
+func ContainsFailure(ctx context.Context, name string) bool {
+	if meta, ok := ctx.Value(SyncRunMetainfoKey{}).(*SyncRunMetainfo); ok {
+		return slices.Contains(meta.Failures(FailureInvalid), name)
 	}
 
 	return false
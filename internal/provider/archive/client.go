@@ -2,16 +2,30 @@
 //
 // SPDX-License-Identifier: EUPL-1.2
 
+// Package archive provides the archive provider. As a mirror target it only names the archives,
+// as a source it lists the newest archive of each repository in a directory, to restore from.
 package archive
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"itiquette/git-provider-sync/internal/log"
+	archivemirror "itiquette/git-provider-sync/internal/mirror/archive"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
 )
 
-type Client struct{}
+type Client struct {
+	path       string
+	encryption config.EncryptionConfig
+}
 
 func (Client) CreateProject(_ context.Context, _ model.CreateProjectOption) (string, error) {
 	return "", nil
@@ -33,8 +47,76 @@ func (Client) ProjectExists(_ context.Context, _, _ string) (bool, string, error
 	return false, "", nil
 }
 
-func (Client) GetProjectInfos(_ context.Context, _ model.ProviderOption, _ bool) ([]model.ProjectInfo, error) {
-	return nil, nil
+// GetProjectInfos lists the archives in the source path, keeping the newest archive of each repository.
+// The archive path is used as clone URL, and the default branch is read from the archived HEAD,
+// decrypting encrypted archives.
+func (client Client) GetProjectInfos(ctx context.Context, opt model.ProviderOption, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Archive:GetProjectInfos")
+	logger.Debug().Str("path", client.path).Bool("filtering", filtering).Msg("Archive:GetProjectInfos")
+
+	entries, err := os.ReadDir(client.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive directory %s: %w", client.path, err)
+	}
+
+	newest := map[string]string{}
+	createdAt := map[string]time.Time{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name, created, ok := archivemirror.ParseTargetPath(entry.Name())
+		if !ok {
+			logger.Debug().Str("file", entry.Name()).Msg("Skipping file not named as an archive")
+
+			continue
+		}
+
+		if created.After(createdAt[name]) {
+			newest[name] = filepath.Join(client.path, entry.Name())
+			createdAt[name] = created
+		}
+	}
+
+	encryption, err := archivemirror.NewEncryption(client.encryption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive encryption: %w", err)
+	}
+
+	reader := archivemirror.NewReader().WithEncryption(encryption)
+	projectinfos := make([]model.ProjectInfo, 0, len(newest))
+
+	for name, archivePath := range newest {
+		defaultBranch, err := reader.HeadBranch(ctx, archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read default branch of %s: %w", name, err)
+		}
+
+		lastActivityAt := createdAt[name]
+
+		projectinfos = append(projectinfos, model.ProjectInfo{
+			OriginalName:   name,
+			HTTPSURL:       archivePath,
+			SSHURL:         archivePath,
+			DefaultBranch:  defaultBranch,
+			Visibility:     "private",
+			LastActivityAt: &lastActivityAt,
+			ProjectID:      archivePath,
+		})
+	}
+
+	slices.SortFunc(projectinfos, func(a, b model.ProjectInfo) int {
+		return strings.Compare(a.OriginalName, b.OriginalName)
+	})
+
+	if filtering {
+		return targetfilter.FilterIncludedExcludedGen()(ctx, opt, projectinfos) //nolint
+	}
+
+	return projectinfos, nil
 }
 
 func (Client) Protect(_ context.Context, _, _, _ string) error {
@@ -44,3 +126,10 @@ func (Client) Protect(_ context.Context, _, _, _ string) error {
 func (Client) Unprotect(_ context.Context, _, _ string) error {
 	return nil
 }
+
+func NewArchiveClient(ctx context.Context, opt model.GitProviderClientOption) Client {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Archive:NewArchiveClient")
+
+	return Client{path: opt.Path, encryption: opt.Encryption}
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	archivemirror "itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/model"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+// newArchive archives an empty bare repository with HEAD on the given branch,
+// under the file name the archive mirror would give it at the given time.
+func newArchive(t *testing.T, targetDir, name, branch string, createdAt time.Time) string {
+	t.Helper()
+
+	repoDir := filepath.Join(t.TempDir(), name)
+
+	_, err := git.PlainInitWithOptions(repoDir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName(branch)},
+		Bare:        true,
+	})
+	require.NoError(t, err)
+
+	archivePath := filepath.Join(targetDir, name+archivemirror.FormatArchiveTimestamp(createdAt)+".tar.gz")
+	require.NoError(t, archivemirror.NewHandler().CreateArchive(context.Background(), repoDir, archivePath, name))
+
+	return archivePath
+}
+
+func TestClient_GetProjectInfos(t *testing.T) {
+	backupDir := t.TempDir()
+	now := time.Now()
+
+	newArchive(t, backupDir, "tools", "main", now.Add(-48*time.Hour))
+	newestTools := newArchive(t, backupDir, "tools", "trunk", now.Add(-time.Hour))
+	docs := newArchive(t, backupDir, "docs", "main", now.Add(-24*time.Hour))
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "notes.txt"), []byte("not an archive"), 0o600))
+
+	tests := []struct {
+		name        string
+		providerOpt model.ProviderOption
+		filtering   bool
+		want        map[string]string
+	}{
+		{
+			name: "newest archive per repository",
+			want: map[string]string{"docs": docs, "tools": newestTools},
+		},
+		{
+			name:        "filtered by exclude",
+			providerOpt: model.ProviderOption{ExcludedRepositories: []string{"docs"}},
+			filtering:   true,
+			want:        map[string]string{"tools": newestTools},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			client := NewArchiveClient(context.Background(), model.GitProviderClientOption{Path: backupDir})
+
+			infos, err := client.GetProjectInfos(context.Background(), tabletest.providerOpt, tabletest.filtering)
+			require.NoError(err)
+
+			got := map[string]string{}
+			for _, info := range infos {
+				require.Equal(info.HTTPSURL, info.SSHURL)
+				require.NotNil(info.LastActivityAt)
+				got[info.OriginalName] = info.HTTPSURL
+			}
+
+			require.Equal(tabletest.want, got)
+		})
+	}
+}
+
+func TestClient_GetProjectInfosDefaultBranch(t *testing.T) {
+	backupDir := t.TempDir()
+	newArchive(t, backupDir, "tools", "trunk", time.Now())
+
+	client := NewArchiveClient(context.Background(), model.GitProviderClientOption{Path: backupDir})
+
+	infos, err := client.GetProjectInfos(context.Background(), model.ProviderOption{}, false)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, "trunk", infos[0].DefaultBranch)
+}
+
+func TestClient_GetProjectInfosMissingPath(t *testing.T) {
+	client := NewArchiveClient(context.Background(), model.GitProviderClientOption{Path: filepath.Join(t.TempDir(), "missing")})
+
+	_, err := client.GetProjectInfos(context.Background(), model.ProviderOption{}, false)
+	require.Error(t, err)
+}
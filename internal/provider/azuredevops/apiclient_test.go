@@ -0,0 +1,441 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const (
+	testPAT       = "secret-pat"
+	testProjectID = "6ce954b1-ce1f-45d1-b94d-e6bf2464ba2c"
+)
+
+// fakeServer is a minimal in-memory Azure DevOps REST API for the organization org and project proj.
+type fakeServer struct {
+	mu           sync.Mutex
+	repos        []repository
+	commits      map[string]time.Time
+	policies     []policyConfiguration
+	lastCreate   map[string]any
+	nextID       int
+	nextPolicyID int
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeServer{commits: map[string]time.Time{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /org/_apis/projects/proj", fake.getProject)
+	mux.HandleFunc("GET /org/proj/_apis/git/repositories", fake.listRepos)
+	mux.HandleFunc("POST /org/proj/_apis/git/repositories", fake.createRepo)
+	mux.HandleFunc("GET /org/proj/_apis/git/repositories/{repo}", fake.getRepo)
+	mux.HandleFunc("PATCH /org/proj/_apis/git/repositories/{repo}", fake.updateRepo)
+	mux.HandleFunc("GET /org/proj/_apis/git/repositories/{repo}/commits", fake.listCommits)
+	mux.HandleFunc("POST /org/proj/_apis/policy/configurations", fake.addPolicy)
+	mux.HandleFunc("GET /org/proj/_apis/git/policy/configurations", fake.listPolicies)
+	mux.HandleFunc("DELETE /org/proj/_apis/policy/configurations/{id}", fake.deletePolicy)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); !ok || password != testPAT {
+			writeError(w, http.StatusUnauthorized, "Access denied")
+
+			return
+		}
+
+		if r.URL.Query().Get("api-version") != apiVersion {
+			writeError(w, http.StatusBadRequest, "No api-version was supplied")
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeServer) addRepo(repo repository) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	repo.ID = "repo-" + strconv.Itoa(f.nextID)
+	f.repos = append(f.repos, repo)
+}
+
+func (f *fakeServer) findRepo(nameOrID string) int {
+	for index, repo := range f.repos {
+		if repo.ID == nameOrID || strings.EqualFold(repo.Name, nameOrID) {
+			return index
+		}
+	}
+
+	return -1
+}
+
+func (f *fakeServer) getProject(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, teamProject{ID: testProjectID, Name: "proj", Visibility: "private"})
+}
+
+func (f *fakeServer) listRepos(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, list[repository]{Value: f.repos, Count: len(f.repos)})
+}
+
+func (f *fakeServer) createRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.lastCreate = body
+	f.nextID++
+
+	name, _ := body["name"].(string)
+	repo := repository{ID: "repo-" + strconv.Itoa(f.nextID), Name: name}
+	f.repos = append(f.repos, repo)
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, repo)
+}
+
+func (f *fakeServer) getRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index := f.findRepo(r.PathValue("repo"))
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "TF401019: The Git repository does not exist")
+
+		return
+	}
+
+	writeJSON(w, f.repos[index])
+}
+
+func (f *fakeServer) updateRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index := f.findRepo(r.PathValue("repo"))
+	if index < 0 || f.repos[index].ID != r.PathValue("repo") {
+		writeError(w, http.StatusNotFound, "TF401019: The Git repository does not exist")
+
+		return
+	}
+
+	var update repository
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.repos[index].DefaultBranch = update.DefaultBranch
+	writeJSON(w, f.repos[index])
+}
+
+func (f *fakeServer) listCommits(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var commits []commitRef
+
+	if date, ok := f.commits[r.PathValue("repo")]; ok && r.URL.Query().Get("searchCriteria.$top") == "1" {
+		commit := commitRef{CommitID: "abc"}
+		commit.Committer.Date = date
+		commits = append(commits, commit)
+	}
+
+	writeJSON(w, list[commitRef]{Value: commits, Count: len(commits)})
+}
+
+func (f *fakeServer) addPolicy(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var policy policyConfiguration
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.nextPolicyID++
+	policy.ID = f.nextPolicyID
+	f.policies = append(f.policies, policy)
+
+	writeJSON(w, policy)
+}
+
+func (f *fakeServer) listPolicies(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query := r.URL.Query()
+
+	var matching []policyConfiguration
+
+	for _, policy := range f.policies {
+		scope := policy.Settings.Scope[0]
+		if scope.RepositoryID == query.Get("repositoryId") && scope.RefName == query.Get("refName") && policy.Type.ID == query.Get("policyType") {
+			matching = append(matching, policy)
+		}
+	}
+
+	writeJSON(w, list[policyConfiguration]{Value: matching, Count: len(matching)})
+}
+
+func (f *fakeServer) deletePolicy(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	kept := f.policies[:0]
+
+	for _, policy := range f.policies {
+		if strconv.Itoa(policy.ID) != r.PathValue("id") {
+			kept = append(kept, policy)
+		}
+	}
+
+	f.policies = kept
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `{"message":%q}`, message)
+}
+
+func newTestAPIClient(t *testing.T, server *httptest.Server, token string) APIClient {
+	t.Helper()
+
+	api, err := NewAzureDevOpsAPIClient(context.Background(), server.Client(), model.GitProviderClientOption{
+		ProviderType: config.AZUREDEVOPS,
+		Domain:       strings.TrimPrefix(server.URL, "http://"),
+		AuthCfg:      config.AuthConfig{HTTPScheme: "http", Token: token},
+	})
+	require.NoError(t, err)
+
+	return api
+}
+
+func TestAPIClient_NewAzureDevOpsAPIClient(t *testing.T) {
+	_, err := NewAzureDevOpsAPIClient(context.Background(), http.DefaultClient, model.GitProviderClientOption{})
+	require.ErrorIs(t, err, ErrNoBaseURL)
+}
+
+func TestAPIClient_GetProjectInfos(t *testing.T) {
+	tests := []struct {
+		name         string
+		providerOpt  model.ProviderOption
+		token        string
+		wantNames    []string
+		wantErr      error
+		wantAPIError int
+	}{
+		{
+			name:        "project repositories without forks and disabled repositories",
+			providerOpt: model.ProviderOption{Owner: "org/proj"},
+			token:       testPAT,
+			wantNames:   []string{"one", "empty"},
+		},
+		{
+			name:        "project repositories including forks",
+			providerOpt: model.ProviderOption{Owner: "org/proj", IncludeForks: true},
+			token:       testPAT,
+			wantNames:   []string{"one", "fork", "empty"},
+		},
+		{
+			name:        "owner without project",
+			providerOpt: model.ProviderOption{Owner: "org"},
+			token:       testPAT,
+			wantErr:     ErrInvalidOwner,
+		},
+		{
+			name:         "wrong token",
+			providerOpt:  model.ProviderOption{Owner: "org/proj"},
+			token:        "wrong",
+			wantAPIError: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake, server := newFakeServer(t)
+			fake.addRepo(repository{
+				Name:          "one",
+				DefaultBranch: "refs/heads/main",
+				RemoteURL:     "https://org@dev.azure.com/org/proj/_git/one",
+				SSHURL:        "git@ssh.dev.azure.com:v3/org/proj/one",
+				Project:       teamProject{Visibility: "public"},
+			})
+			fake.addRepo(repository{Name: "fork", DefaultBranch: "refs/heads/main", IsFork: true})
+			fake.addRepo(repository{Name: "disabled", DefaultBranch: "refs/heads/main", IsDisabled: true})
+			fake.addRepo(repository{Name: "empty", Project: teamProject{Visibility: "private"}})
+
+			committedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			fake.commits["repo-1"] = committedAt
+
+			api := newTestAPIClient(t, server, tabletest.token)
+
+			got, err := api.GetProjectInfos(context.Background(), tabletest.providerOpt, false)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+
+				return
+			}
+
+			if tabletest.wantAPIError != 0 {
+				var apiErr *APIError
+
+				require.ErrorAs(err, &apiErr)
+				require.Equal(tabletest.wantAPIError, apiErr.StatusCode)
+				require.Equal("Access denied", apiErr.Message)
+
+				return
+			}
+
+			require.NoError(err)
+
+			names := make([]string, 0, len(got))
+			for _, info := range got {
+				names = append(names, info.OriginalName)
+			}
+
+			require.Equal(tabletest.wantNames, names)
+
+			require.Equal("main", got[0].DefaultBranch)
+			require.Equal(PUBLIC, got[0].Visibility)
+			require.Equal("org/proj/repo-1", got[0].ProjectID)
+			require.Equal("https://dev.azure.com/org/proj/_git/one", got[0].HTTPSURL)
+			require.Equal("git@ssh.dev.azure.com:v3/org/proj/one", got[0].SSHURL)
+			require.Equal(committedAt, *got[0].LastActivityAt)
+
+			empty := got[len(got)-1]
+			require.Equal(PRIVATE, empty.Visibility)
+			require.Nil(empty.LastActivityAt)
+		})
+	}
+}
+
+func TestAPIClient_CreateProject(t *testing.T) {
+	require := require.New(t)
+
+	fake, server := newFakeServer(t)
+	api := newTestAPIClient(t, server, testPAT)
+
+	got, err := api.CreateProject(context.Background(), model.CreateProjectOption{RepositoryName: "new-repo", Owner: "org/proj"})
+	require.NoError(err)
+	require.Equal("org/proj/repo-1", got)
+	require.Equal("new-repo", fake.lastCreate["name"])
+	require.Equal(map[string]any{"id": testProjectID}, fake.lastCreate["project"])
+
+	_, err = api.CreateProject(context.Background(), model.CreateProjectOption{RepositoryName: "new-repo", Owner: "org"})
+	require.ErrorIs(err, ErrInvalidOwner)
+}
+
+func TestAPIClient_ProjectExists(t *testing.T) {
+	tests := []struct {
+		name   string
+		repo   string
+		want   bool
+		wantID string
+	}{
+		{name: "existing repository", repo: "repo", want: true, wantID: "org/proj/repo-1"},
+		{name: "missing repository", repo: "missing"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake, server := newFakeServer(t)
+			fake.addRepo(repository{Name: "repo"})
+
+			api := newTestAPIClient(t, server, testPAT)
+
+			exists, projectID, err := api.ProjectExists(context.Background(), "org/proj", tabletest.repo)
+			require.NoError(err)
+			require.Equal(tabletest.want, exists)
+			require.Equal(tabletest.wantID, projectID)
+		})
+	}
+}
+
+func TestAPIClient_SetDefaultBranch(t *testing.T) {
+	require := require.New(t)
+
+	fake, server := newFakeServer(t)
+	fake.addRepo(repository{Name: "repo", DefaultBranch: "refs/heads/main"})
+
+	api := newTestAPIClient(t, server, testPAT)
+
+	require.NoError(api.SetDefaultBranch(context.Background(), "org/proj", "repo", "develop"))
+	require.Equal("refs/heads/develop", fake.repos[0].DefaultBranch)
+}
+
+func TestAPIClient_ProtectUnprotect(t *testing.T) {
+	require := require.New(t)
+
+	fake, server := newFakeServer(t)
+	fake.addRepo(repository{Name: "repo"})
+
+	api := newTestAPIClient(t, server, testPAT)
+
+	require.NoError(api.Protect(context.Background(), "org/proj", "main", "org/proj/repo-1"))
+	require.Len(fake.policies, 1)
+	require.True(fake.policies[0].IsBlocking)
+	require.Equal(minimumReviewersPolicyType, fake.policies[0].Type.ID)
+	require.Equal([]policyScope{{RepositoryID: "repo-1", RefName: "refs/heads/main", MatchKind: "exact"}}, fake.policies[0].Settings.Scope)
+
+	require.NoError(api.Unprotect(context.Background(), "main", "org/proj/repo-1"))
+	require.Empty(fake.policies)
+
+	require.ErrorIs(api.Protect(context.Background(), "org/proj", "main", "repo-1"), ErrInvalidProjectID)
+}
+
+func TestAPIClient_IsValidProjectName(t *testing.T) {
+	api := APIClient{}
+
+	require.True(t, api.IsValidProjectName(context.Background(), "valid name"))
+	require.False(t, api.IsValidProjectName(context.Background(), "invalid/name"))
+}
+
+func TestAPIClient_Name(t *testing.T) {
+	require.Equal(t, config.AZUREDEVOPS, APIClient{}.Name())
+}
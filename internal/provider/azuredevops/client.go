@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiVersion = "7.1"
+
+var ErrNoBaseURL = errors.New("no Azure DevOps base URL configured")
+
+// Client is a minimal Azure DevOps Services / Server REST client.
+// It authenticates with a personal access token (PAT) sent as the basic auth password.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// APIError is returned for responses with a non-2xx status code.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// isNotFound reports whether err is an APIError with status 404.
+func isNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// list is a collection returned by Azure DevOps.
+type list[T any] struct {
+	Value []T `json:"value"`
+	Count int `json:"count"`
+}
+
+// errorResponse is the error body returned by Azure DevOps.
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func NewClient(httpClient *http.Client, baseURL, token string) (*Client, error) {
+	if baseURL == "" {
+		return nil, ErrNoBaseURL
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+	}, nil
+}
+
+// do sends a request to path, relative to the base URL, encoding body as JSON
+// and decoding the response into out, when they are not nil.
+// The api-version query parameter is always added.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+
+	query.Set("api-version", apiVersion)
+	reqURL := c.baseURL + path + "?" + query.Encode()
+
+	var reqBody io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.SetBasicAuth("", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(method, path, resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+func newAPIError(method, path string, resp *http.Response) error {
+	apiErr := &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	var errResp errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Message != "" {
+		apiErr.Message = errResp.Message
+	}
+
+	return apiErr
+}
+
+// projectPath returns the REST path of a project of an organization.
+func projectPath(organization, project string) string {
+	return "/" + url.PathEscape(organization) + "/" + url.PathEscape(project)
+}
+
+// repositoriesPath returns the REST path of the git repositories of a project.
+func repositoriesPath(organization, project string) string {
+	return projectPath(organization, project) + "/_apis/git/repositories"
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+
+	"itiquette/git-provider-sync/internal/functiondefinition"
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+)
+
+type filterService struct{}
+
+func NewFilter() filterService {
+	return filterService{}
+}
+
+func (filterService) FilterProjectinfos(ctx context.Context, opt model.ProviderOption, projectinfos []model.ProjectInfo, filterExcludedIncludedFunc functiondefinition.FilterIncludedExcludedFunc, isInInterval interfaces.IsInIntervalFunc) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:FilterProjectinfos")
+
+	filtered, err := filterExcludedIncludedFunc(ctx, opt, projectinfos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter repositories by include/exclude: %w", err)
+	}
+
+	return filterByDate(ctx, filtered, isInInterval)
+}
+
+// filterByDate keeps the repositories whose last commit is within the configured interval.
+// Repositories without commits have no activity time and are left out.
+func filterByDate(ctx context.Context, projectInfos []model.ProjectInfo, isInInterval interfaces.IsInIntervalFunc) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:filterByDate")
+
+	filtered := make([]model.ProjectInfo, 0, len(projectInfos))
+
+	for _, projectInfo := range projectInfos {
+		if projectInfo.LastActivityAt == nil {
+			continue
+		}
+
+		include, err := isInInterval(ctx, *projectInfo.LastActivityAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter include by activity time: %w", err)
+		}
+
+		if include {
+			filtered = append(filtered, projectInfo)
+		}
+	}
+
+	return filtered, nil
+}
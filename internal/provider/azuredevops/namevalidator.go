@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxNameLength is the maximum length of an Azure DevOps repository name.
+const maxNameLength = 64
+
+// invalidCharsRegex matches the characters that are not allowed in an Azure DevOps repository name.
+var invalidCharsRegex = regexp.MustCompile(`[\x00-\x1f\\/:*?"<>|;#${},+=\[\]]`)
+
+// reservedNames are names reserved by Windows and IIS, which can not be used as repository names.
+var reservedNames = []string{
+	"aux", "con", "nul", "prn",
+	"com1", "com2", "com3", "com4", "com5", "com6", "com7", "com8", "com9",
+	"lpt1", "lpt2", "lpt3", "lpt4", "lpt5", "lpt6", "lpt7", "lpt8", "lpt9",
+	"app_code", "app_browsers", "app_data", "app_globalresources", "app_localresources",
+	"app_themes", "app_webresources", "bin", "web.config",
+}
+
+// IsValidAzureDevOpsRepositoryName checks if the given name is a valid Azure DevOps repository name.
+// It returns true if the name:
+//  1. Is between 1 and 64 characters long
+//  2. Contains no control characters or any of \ / : * ? " < > | ; # $ { } , + = [ ]
+//  3. Does not start with an underscore or a dot, and does not end with a dot
+//  4. Is not a reserved name, like CON or App_Data
+//
+// Parameters:
+//   - name: The repository name to validate
+//
+// Returns:
+//   - bool: true if the name is valid, false otherwise
+func IsValidAzureDevOpsRepositoryName(name string) bool {
+	if len(name) == 0 || len(name) > maxNameLength || invalidCharsRegex.MatchString(name) {
+		return false
+	}
+
+	if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return false
+	}
+
+	return !slices.Contains(reservedNames, strings.ToLower(name))
+}
+
+// SplitOwner splits an owner given as <organization>/<project>.
+// It returns false if the owner is not made of exactly two non-empty parts.
+func SplitOwner(owner string) (string, string, bool) {
+	organization, project, found := strings.Cut(owner, "/")
+	if !found || organization == "" || project == "" || strings.Contains(project, "/") {
+		return "", "", false
+	}
+
+	return organization, project, true
+}
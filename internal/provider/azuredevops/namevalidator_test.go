@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidAzureDevOpsRepositoryName(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		want     bool
+	}{
+		{"simple name", "repo", true},
+		{"space and dot", "My Repo.v2", true},
+		{"max length", strings.Repeat("a", 64), true},
+		{"too long", strings.Repeat("a", 65), false},
+		{"empty", "", false},
+		{"slash", "my/repo", false},
+		{"hash", "repo#1", false},
+		{"leading underscore", "_repo", false},
+		{"leading dot", ".repo", false},
+		{"trailing dot", "repo.", false},
+		{"reserved device name", "CON", false},
+		{"reserved IIS name", "App_Data", false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, IsValidAzureDevOpsRepositoryName(tabletest.repoName))
+		})
+	}
+}
+
+func TestSplitOwner(t *testing.T) {
+	tests := []struct {
+		name      string
+		owner     string
+		wantOrg   string
+		wantProj  string
+		wantFound bool
+	}{
+		{"organization and project", "org/project", "org", "project", true},
+		{"organization only", "org", "", "", false},
+		{"empty project", "org/", "", "", false},
+		{"too many parts", "org/project/repo", "", "", false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			organization, project, found := SplitOwner(tabletest.owner)
+			require.Equal(tabletest.wantOrg, organization)
+			require.Equal(tabletest.wantProj, project)
+			require.Equal(tabletest.wantFound, found)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+)
+
+const (
+	PUBLIC  = "public"
+	PRIVATE = "private"
+
+	branchRefPrefix = "refs/heads/"
+)
+
+var (
+	ErrInvalidOwner     = errors.New("invalid owner, expected <organization>/<project>")
+	ErrInvalidProjectID = errors.New("invalid project id, expected <organization>/<project>/<repository id>")
+)
+
+type teamProject struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+type repository struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	DefaultBranch string      `json:"defaultBranch"`
+	RemoteURL     string      `json:"remoteUrl"`
+	SSHURL        string      `json:"sshUrl"`
+	IsFork        bool        `json:"isFork"`
+	IsDisabled    bool        `json:"isDisabled"`
+	Project       teamProject `json:"project"`
+}
+
+type commitRef struct {
+	CommitID  string `json:"commitId"`
+	Committer struct {
+		Date time.Time `json:"date"`
+	} `json:"committer"`
+}
+
+type ProjectService struct {
+	client *Client
+}
+
+func NewProjectService(client *Client) ProjectService {
+	return ProjectService{client: client}
+}
+
+// CreateProject creates a repository in the project of opt.Owner.
+// Visibility is a project setting in Azure DevOps and repositories have no description, so both are left out.
+func (p ProjectService) CreateProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:CreateProject")
+	opt.DebugLog(logger).Msg("AzureDevOps:CreateOption")
+
+	organization, project, ok := SplitOwner(opt.Owner)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrInvalidOwner, opt.Owner)
+	}
+
+	var team teamProject
+	if err := p.client.do(ctx, http.MethodGet, "/"+url.PathEscape(organization)+"/_apis/projects/"+url.PathEscape(project), nil, nil, &team); err != nil {
+		return "", fmt.Errorf("failed to get project %s: %w", project, err)
+	}
+
+	body := map[string]any{
+		"name":    opt.RepositoryName,
+		"project": map[string]string{"id": team.ID},
+	}
+
+	var created repository
+	if err := p.client.do(ctx, http.MethodPost, repositoriesPath(organization, project), nil, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create project. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	logger.Debug().Str("name", opt.RepositoryName).Msg("Repository created successfully")
+
+	return projectID(organization, project, created.ID), nil
+}
+
+func (p ProjectService) ProjectExists(ctx context.Context, owner, repo string) (bool, string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:ProjectExists")
+
+	organization, project, ok := SplitOwner(owner)
+	if !ok {
+		return false, "", fmt.Errorf("%w: %s", ErrInvalidOwner, owner)
+	}
+
+	found, err := p.getRepository(ctx, organization, project, repo)
+	if isNotFound(err) {
+		return false, "", nil
+	}
+
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get repository %s/%s: %w", owner, repo, err)
+	}
+
+	return true, projectID(organization, project, found.ID), nil
+}
+
+func (p ProjectService) GetProjectInfos(ctx context.Context, providerOpt model.ProviderOption, _ bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:GetProjectInfos")
+
+	organization, project, ok := SplitOwner(providerOpt.Owner)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOwner, providerOpt.Owner)
+	}
+
+	var repositories list[repository]
+	if err := p.client.do(ctx, http.MethodGet, repositoriesPath(organization, project), nil, nil, &repositories); err != nil {
+		return nil, fmt.Errorf("failed to list repositories. owner: %s, err: %w", providerOpt.Owner, err)
+	}
+
+	logger.Debug().Int("total_repositories", len(repositories.Value)).Msg("Found repositories")
+
+	projectinfos := make([]model.ProjectInfo, 0, len(repositories.Value))
+
+	for _, repo := range repositories.Value {
+		// Disabled repositories can not be cloned
+		if repo.IsDisabled || (!providerOpt.IncludeForks && repo.IsFork) {
+			continue
+		}
+
+		projectInfo, err := p.newProjectInfo(ctx, organization, project, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init projectInfo. name: %s, err: %w", repo.Name, err)
+		}
+
+		projectinfos = append(projectinfos, projectInfo)
+	}
+
+	return projectinfos, nil
+}
+
+func (p ProjectService) SetDefaultBranch(ctx context.Context, owner, projectName, branch string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:SetDefaultBranch")
+
+	organization, project, ok := SplitOwner(owner)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidOwner, owner)
+	}
+
+	repo, err := p.getRepository(ctx, organization, project, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to get repository %s: %w", projectName, err)
+	}
+
+	body := map[string]string{"defaultBranch": branchRefPrefix + branch}
+
+	if err := p.client.do(ctx, http.MethodPatch, repositoriesPath(organization, project)+"/"+url.PathEscape(repo.ID), nil, body, nil); err != nil {
+		return fmt.Errorf("failed to set default branch. err: %w", err)
+	}
+
+	return nil
+}
+
+// getRepository gets a repository by name or id.
+func (p ProjectService) getRepository(ctx context.Context, organization, project, nameOrID string) (repository, error) {
+	var repo repository
+
+	err := p.client.do(ctx, http.MethodGet, repositoriesPath(organization, project)+"/"+url.PathEscape(nameOrID), nil, nil, &repo)
+
+	return repo, err
+}
+
+func (p ProjectService) newProjectInfo(ctx context.Context, organization, project string, repo repository) (model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:newProjectInfo")
+	logger.Debug().Str("name", repo.Name).Msg("AzureDevOps:newProjectInfo")
+
+	var lastActivityAt *time.Time
+
+	// Repositories without a default branch are empty and have no commits
+	if repo.DefaultBranch != "" {
+		query := url.Values{"searchCriteria.$top": []string{"1"}}
+
+		var commits list[commitRef]
+		if err := p.client.do(ctx, http.MethodGet, repositoriesPath(organization, project)+"/"+url.PathEscape(repo.ID)+"/commits", query, nil, &commits); err != nil && !isNotFound(err) {
+			return model.ProjectInfo{}, fmt.Errorf("failed to get latest commit: %w", err)
+		}
+
+		if len(commits.Value) > 0 {
+			committedAt := commits.Value[0].Committer.Date
+			lastActivityAt = &committedAt
+		}
+	}
+
+	return model.ProjectInfo{
+		DefaultBranch:  strings.TrimPrefix(repo.DefaultBranch, branchRefPrefix),
+		HTTPSURL:       removeUser(repo.RemoteURL),
+		LastActivityAt: lastActivityAt,
+		OriginalName:   repo.Name,
+		ProjectID:      projectID(organization, project, repo.ID),
+		SSHURL:         repo.SSHURL,
+		Visibility:     getVisibility(repo.Project.Visibility),
+	}, nil
+}
+
+// removeUser removes the organization user name Azure DevOps adds to HTTPS remote URLs.
+func removeUser(remoteURL string) string {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return remoteURL
+	}
+
+	parsed.User = nil
+
+	return parsed.String()
+}
+
+// projectID identifies a repository as <organization>/<project>/<repository id>.
+func projectID(organization, project, repositoryID string) string {
+	return organization + "/" + project + "/" + repositoryID
+}
+
+// splitProjectID splits a projectID into organization, project and repository id.
+func splitProjectID(projectIDStr string) (string, string, string, error) {
+	parts := strings.Split(projectIDStr, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("%w: %s", ErrInvalidProjectID, projectIDStr)
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
+
+func getVisibility(projectVisibility string) string {
+	if strings.EqualFold(projectVisibility, PUBLIC) {
+		return PUBLIC
+	}
+
+	return PRIVATE
+}
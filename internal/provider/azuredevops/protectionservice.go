@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// minimumReviewersPolicyType is the id of the built-in "Minimum number of reviewers" branch policy type.
+const minimumReviewersPolicyType = "fa4e907d-c16b-4a4c-9dfa-4906e5d171dd"
+
+type policyScope struct {
+	RepositoryID string `json:"repositoryId"`
+	RefName      string `json:"refName"`
+	MatchKind    string `json:"matchKind"`
+}
+
+type policySettings struct {
+	MinimumApproverCount int           `json:"minimumApproverCount"`
+	CreatorVoteCounts    bool          `json:"creatorVoteCounts"`
+	Scope                []policyScope `json:"scope"`
+}
+
+type policyType struct {
+	ID string `json:"id"`
+}
+
+type policyConfiguration struct {
+	ID         int            `json:"id,omitempty"`
+	IsEnabled  bool           `json:"isEnabled"`
+	IsBlocking bool           `json:"isBlocking"`
+	Type       policyType     `json:"type"`
+	Settings   policySettings `json:"settings"`
+}
+
+type ProtectionService struct {
+	client *Client
+}
+
+func NewProtectionService(client *Client) ProtectionService {
+	return ProtectionService{client: client}
+}
+
+// Protect adds a blocking minimum reviewers policy to the default branch,
+// which makes it only updatable through pull requests.
+func (p ProtectionService) Protect(ctx context.Context, _ string, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:Protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("AzureDevOps:Protect")
+
+	organization, project, repositoryID, err := splitProjectID(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	policy := policyConfiguration{
+		IsEnabled:  true,
+		IsBlocking: true,
+		Type:       policyType{ID: minimumReviewersPolicyType},
+		Settings: policySettings{
+			MinimumApproverCount: 1,
+			Scope:                []policyScope{{RepositoryID: repositoryID, RefName: branchRefPrefix + branch, MatchKind: "exact"}},
+		},
+	}
+
+	if err := p.client.do(ctx, http.MethodPost, projectPath(organization, project)+"/_apis/policy/configurations", nil, policy, nil); err != nil {
+		return fmt.Errorf("failed to add branch policy for %s. err: %w", branch, err)
+	}
+
+	return nil
+}
+
+// Unprotect removes the minimum reviewers policies of the default branch.
+func (p ProtectionService) Unprotect(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:Unprotect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("AzureDevOps:Unprotect")
+
+	organization, project, repositoryID, err := splitProjectID(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{
+		"repositoryId": []string{repositoryID},
+		"refName":      []string{branchRefPrefix + branch},
+		"policyType":   []string{minimumReviewersPolicyType},
+	}
+
+	var policies list[policyConfiguration]
+
+	err = p.client.do(ctx, http.MethodGet, projectPath(organization, project)+"/_apis/git/policy/configurations", query, nil, &policies)
+	if isNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to list branch policies. err: %w", err)
+	}
+
+	for _, policy := range policies.Value {
+		path := projectPath(organization, project) + "/_apis/policy/configurations/" + strconv.Itoa(policy.ID)
+		if err := p.client.do(ctx, http.MethodDelete, path, nil, nil, nil); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to remove branch policy %d. err: %w", policy.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
+)
+
+// APIClient represents a facade to Bitbucket Cloud API operations.
+type APIClient struct {
+	raw               *Client
+	projectService    interfaces.ProjectServicer
+	protectionService interfaces.ProtectionServicer
+	filterService     interfaces.FilterServicer
+}
+
+func (api APIClient) CreateProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:CreateProject")
+	opt.DebugLog(logger).Msg("Bitbucket:CreateOption")
+
+	projectID, err := api.projectService.CreateProject(ctx, opt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create a Bitbucket project. err: %w", err)
+	}
+
+	return projectID, nil
+}
+
+func (api APIClient) ProjectExists(ctx context.Context, owner, repo string) (bool, string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:ProjectExists")
+
+	exists, projectID, err := api.projectService.ProjectExists(ctx, owner, repo)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to see if project existed. err: %w", err)
+	}
+
+	return exists, projectID, nil
+}
+
+func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:IsValidProjectName")
+	logger.Debug().Str("name", name).Msg("Bitbucket:IsValidProjectName")
+
+	return IsValidBitbucketRepositoryName(name)
+}
+
+func (APIClient) Name() string {
+	return config.BITBUCKET
+}
+
+func (api APIClient) GetProjectInfos(ctx context.Context, providerOpt model.ProviderOption, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:ProjectInfos")
+	logger.Debug().Bool("filtering", filtering).Msg("Bitbucket:ProjectInfos")
+
+	projectInfos, err := api.projectService.GetProjectInfos(ctx, providerOpt, filtering)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project infos. err: %w", err)
+	}
+
+	if filtering {
+		return api.filterService.FilterProjectinfos(ctx, providerOpt, projectInfos, targetfilter.FilterIncludedExcludedGen(), targetfilter.IsInInterval) //nolint
+	}
+
+	return projectInfos, nil
+}
+
+func (api APIClient) Protect(ctx context.Context, owner string, defaultBranch string, projectIDstr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:Protect")
+	logger.Debug().Str("defaultBranch", defaultBranch).Str("projectIDStr", projectIDstr).Msg("Bitbucket:Protect")
+
+	if err := api.protectionService.Protect(ctx, owner, defaultBranch, projectIDstr); err != nil {
+		return fmt.Errorf("failed to protect project. projectIDStr: %s, err: %w", projectIDstr, err)
+	}
+
+	return nil
+}
+
+func (api APIClient) SetDefaultBranch(ctx context.Context, owner, projectName, branch string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:SetDefaultBranch")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("branch", branch).Msg("Bitbucket:SetDefaultBranch")
+
+	if err := api.projectService.SetDefaultBranch(ctx, owner, projectName, branch); err != nil {
+		return fmt.Errorf("failed to set default branch: %s, projectName: %s, owner: %s, err: %w", branch, projectName, owner, err)
+	}
+
+	return nil
+}
+
+func (api APIClient) Unprotect(ctx context.Context, defaultBranch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:Unprotect")
+	logger.Debug().Str("defaultBranch", defaultBranch).Str("projectIDStr", projectIDStr).Msg("Bitbucket:Unprotect")
+
+	if err := api.protectionService.Unprotect(ctx, defaultBranch, projectIDStr); err != nil {
+		return fmt.Errorf("failed to unprotect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+func NewBitbucketAPIClient(ctx context.Context, httpClient *http.Client, opt model.GitProviderClientOption) (APIClient, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:NewBitbucketAPIClient")
+
+	rawClient, err := NewClient(httpClient, apiBaseURL(opt), opt.AuthCfg.Username, opt.AuthCfg.Token)
+	if err != nil {
+		return APIClient{}, fmt.Errorf("create new Bitbucket client: %w", err)
+	}
+
+	return APIClient{
+		raw:               rawClient,
+		projectService:    NewProjectService(rawClient),
+		protectionService: NewProtectionService(rawClient),
+		filterService:     NewFilter(),
+	}, nil
+}
+
+// apiBaseURL returns the REST API base URL, served from the api subdomain of bitbucket.org.
+func apiBaseURL(opt model.GitProviderClientOption) string {
+	if opt.Domain == "" {
+		return ""
+	}
+
+	if opt.Domain == defaultDomain {
+		return "https://api." + defaultDomain + apiVersionPath
+	}
+
+	return opt.DomainWithScheme(opt.AuthCfg.HTTPScheme) + apiVersionPath
+}
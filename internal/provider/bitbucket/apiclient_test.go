@@ -0,0 +1,516 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const (
+	testToken       = "secret-token"
+	testUser        = "alice"
+	testAppPassword = "app-password"
+)
+
+// fakeServer is a minimal in-memory Bitbucket Cloud REST API.
+type fakeServer struct {
+	mu             sync.Mutex
+	server         *httptest.Server
+	repos          map[string][]repository
+	restrictions   map[string][]branchRestriction
+	lastCreate     createRepositoryOptions
+	lastQuery      string
+	nextRestrictID int
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	fake := &fakeServer{
+		repos:        map[string][]repository{},
+		restrictions: map[string][]branchRestriction{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiVersionPath+"/repositories/{workspace}", fake.listRepos)
+	mux.HandleFunc("GET "+apiVersionPath+"/repositories/{workspace}/{slug}", fake.getRepo)
+	mux.HandleFunc("POST "+apiVersionPath+"/repositories/{workspace}/{slug}", fake.createRepo)
+	mux.HandleFunc("PUT "+apiVersionPath+"/repositories/{workspace}/{slug}", fake.updateRepo)
+	mux.HandleFunc("GET "+apiVersionPath+"/repositories/{workspace}/{slug}/branch-restrictions", fake.listRestrictions)
+	mux.HandleFunc("POST "+apiVersionPath+"/repositories/{workspace}/{slug}/branch-restrictions", fake.addRestriction)
+	mux.HandleFunc("DELETE "+apiVersionPath+"/repositories/{workspace}/{slug}/branch-restrictions/{id}", fake.deleteRestriction)
+
+	fake.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, basic := r.BasicAuth()
+
+		bearer := r.Header.Get("Authorization") == "Bearer "+testToken
+		if !bearer && (!basic || user != testUser || password != testAppPassword) {
+			writeError(w, http.StatusUnauthorized, "Invalid credentials")
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func (f *fakeServer) addRepo(workspace string, repo repository) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repo.FullName = workspace + "/" + repo.Slug
+	f.repos[workspace] = append(f.repos[workspace], repo)
+}
+
+func (f *fakeServer) findRepo(workspace, slug string) (int, bool) {
+	for index, repo := range f.repos[workspace] {
+		if repo.Slug == slug {
+			return index, true
+		}
+	}
+
+	return 0, false
+}
+
+// listRepos serves the repositories of a workspace two per page, linking the next page.
+func (f *fakeServer) listRepos(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repos, ok := f.repos[r.PathValue("workspace")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No workspace with identifier")
+
+		return
+	}
+
+	f.lastQuery = r.URL.Query().Get("q")
+
+	if f.lastQuery != "" {
+		var matching []repository
+
+		for _, repo := range repos {
+			if repo.Project != nil && f.lastQuery == fmt.Sprintf("project.key=%q", repo.Project.Key) {
+				matching = append(matching, repo)
+			}
+		}
+
+		repos = matching
+	}
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	end := min(start+2, len(repos))
+
+	var next string
+
+	if end < len(repos) {
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(end))
+		next = f.server.URL + r.URL.Path + "?" + query.Encode()
+	}
+
+	writeJSON(w, page[repository]{Values: repos[start:end], Next: next})
+}
+
+func (f *fakeServer) getRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index, ok := f.findRepo(r.PathValue("workspace"), r.PathValue("slug"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Repository not found")
+
+		return
+	}
+
+	writeJSON(w, f.repos[r.PathValue("workspace")][index])
+}
+
+func (f *fakeServer) createRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var opts createRepositoryOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.lastCreate = opts
+
+	workspace := r.PathValue("workspace")
+	repo := repository{Name: opts.Name, Slug: r.PathValue("slug"), IsPrivate: opts.IsPrivate, FullName: workspace + "/" + r.PathValue("slug")}
+	f.repos[workspace] = append(f.repos[workspace], repo)
+
+	writeJSON(w, repo)
+}
+
+func (f *fakeServer) updateRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	workspace := r.PathValue("workspace")
+
+	index, ok := f.findRepo(workspace, r.PathValue("slug"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Repository not found")
+
+		return
+	}
+
+	var update repository
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.repos[workspace][index].MainBranch = update.MainBranch
+
+	writeJSON(w, f.repos[workspace][index])
+}
+
+func (f *fakeServer) listRestrictions(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, page[branchRestriction]{Values: f.restrictions[r.PathValue("workspace")+"/"+r.PathValue("slug")]})
+}
+
+func (f *fakeServer) addRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var added branchRestriction
+	if err := json.NewDecoder(r.Body).Decode(&added); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.nextRestrictID++
+	added.ID = f.nextRestrictID
+	id := r.PathValue("workspace") + "/" + r.PathValue("slug")
+	f.restrictions[id] = append(f.restrictions[id], added)
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, added)
+}
+
+func (f *fakeServer) deleteRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := r.PathValue("workspace") + "/" + r.PathValue("slug")
+	kept := f.restrictions[id][:0]
+
+	for _, existing := range f.restrictions[id] {
+		if strconv.Itoa(existing.ID) != r.PathValue("id") {
+			kept = append(kept, existing)
+		}
+	}
+
+	f.restrictions[id] = kept
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `{"type":"error","error":{"message":%q}}`, message)
+}
+
+func newTestAPIClient(t *testing.T, fake *fakeServer, username, token string) APIClient {
+	t.Helper()
+
+	api, err := NewBitbucketAPIClient(context.Background(), fake.server.Client(), model.GitProviderClientOption{
+		ProviderType: config.BITBUCKET,
+		Domain:       strings.TrimPrefix(fake.server.URL, "http://"),
+		AuthCfg:      config.AuthConfig{HTTPScheme: "http", Username: username, Token: token},
+	})
+	require.NoError(t, err)
+
+	return api
+}
+
+func testRepository(slug string, private bool, projectKey string) repository {
+	updatedOn := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := repository{Name: slug, Slug: slug, IsPrivate: private, UpdatedOn: &updatedOn}
+	repo.MainBranch = &struct {
+		Name string `json:"name"`
+	}{Name: "main"}
+	repo.Project = &projectRef{Key: projectKey}
+	repo.Links.Clone = []struct {
+		Href string `json:"href"`
+		Name string `json:"name"`
+	}{
+		{Href: "https://alice@bitbucket.org/team/" + slug + ".git", Name: "https"},
+		{Href: "git@bitbucket.org:team/" + slug + ".git", Name: "ssh"},
+	}
+
+	return repo
+}
+
+func TestAPIClient_NewBitbucketAPIClient(t *testing.T) {
+	_, err := NewBitbucketAPIClient(context.Background(), http.DefaultClient, model.GitProviderClientOption{})
+	require.ErrorIs(t, err, ErrNoBaseURL)
+
+	require.Equal(t, "https://api.bitbucket.org/2.0", apiBaseURL(model.GitProviderClientOption{
+		Domain:  "bitbucket.org",
+		AuthCfg: config.AuthConfig{HTTPScheme: "https"},
+	}))
+}
+
+func TestAPIClient_GetProjectInfos(t *testing.T) {
+	tests := []struct {
+		name        string
+		username    string
+		token       string
+		providerOpt model.ProviderOption
+		wantNames   []string
+		wantQuery   string
+		wantStatus  int
+	}{
+		{
+			name:        "workspace repositories over several pages without forks",
+			token:       testToken,
+			providerOpt: model.ProviderOption{Owner: "team", OwnerType: config.GROUP},
+			wantNames:   []string{"one", "two", "four"},
+		},
+		{
+			name:        "workspace repositories including forks with app password",
+			username:    testUser,
+			token:       testAppPassword,
+			providerOpt: model.ProviderOption{Owner: "team", OwnerType: config.GROUP, IncludeForks: true},
+			wantNames:   []string{"one", "two", "fork", "four"},
+		},
+		{
+			name:        "repositories of a workspace project",
+			token:       testToken,
+			providerOpt: model.ProviderOption{Owner: "team/OPS", OwnerType: config.GROUP},
+			wantNames:   []string{"two", "four"},
+			wantQuery:   `project.key="OPS"`,
+		},
+		{
+			name:        "personal workspace of a user",
+			username:    TokenUser,
+			token:       testToken,
+			providerOpt: model.ProviderOption{Owner: "alice", OwnerType: config.USER},
+			wantNames:   []string{"personal"},
+		},
+		{
+			name:        "wrong app password",
+			username:    testUser,
+			token:       "wrong",
+			providerOpt: model.ProviderOption{Owner: "team", OwnerType: config.GROUP},
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake := newFakeServer(t)
+			fork := testRepository("fork", true, "DEV")
+			fork.Parent = &repository{FullName: "other/fork"}
+
+			fake.addRepo("team", testRepository("one", false, "DEV"))
+			fake.addRepo("team", testRepository("two", true, "OPS"))
+			fake.addRepo("team", fork)
+			fake.addRepo("team", testRepository("four", true, "OPS"))
+			fake.addRepo("alice", testRepository("personal", true, "~"))
+
+			api := newTestAPIClient(t, fake, tabletest.username, tabletest.token)
+
+			got, err := api.GetProjectInfos(context.Background(), tabletest.providerOpt, false)
+			if tabletest.wantStatus != 0 {
+				var apiErr *APIError
+
+				require.ErrorAs(err, &apiErr)
+				require.Equal(tabletest.wantStatus, apiErr.StatusCode)
+				require.Equal("Invalid credentials", apiErr.Message)
+
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tabletest.wantQuery, fake.lastQuery)
+
+			names := make([]string, 0, len(got))
+			for _, info := range got {
+				names = append(names, info.OriginalName)
+			}
+
+			require.Equal(tabletest.wantNames, names)
+
+			if tabletest.providerOpt.Owner == "team" {
+				require.Equal("main", got[0].DefaultBranch)
+				require.Equal(PUBLIC, got[0].Visibility)
+				require.Equal(PRIVATE, got[1].Visibility)
+				require.Equal("team/one", got[0].ProjectID)
+				require.Equal("https://bitbucket.org/team/one.git", got[0].HTTPSURL)
+				require.Equal("git@bitbucket.org:team/one.git", got[0].SSHURL)
+				require.NotNil(got[0].LastActivityAt)
+			}
+		})
+	}
+}
+
+func TestAPIClient_CreateProject(t *testing.T) {
+	tests := []struct {
+		name           string
+		opt            model.CreateProjectOption
+		want           string
+		wantPrivate    bool
+		wantForkPolicy string
+		wantProject    *projectRef
+		wantErr        error
+	}{
+		{
+			name:           "public repository in the default project",
+			opt:            model.CreateProjectOption{RepositoryName: "My-Repo", Owner: "team", Visibility: PUBLIC, Description: "desc"},
+			want:           "team/my-repo",
+			wantForkPolicy: forkPolicyAllow,
+		},
+		{
+			name:           "disabled private repository in a project",
+			opt:            model.CreateProjectOption{RepositoryName: "repo", Owner: "team/OPS", Visibility: PRIVATE, Disabled: true},
+			want:           "team/repo",
+			wantPrivate:    true,
+			wantForkPolicy: forkPolicyNone,
+			wantProject:    &projectRef{Key: "OPS"},
+		},
+		{
+			name:    "missing owner",
+			opt:     model.CreateProjectOption{RepositoryName: "repo"},
+			wantErr: ErrNoOwner,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake := newFakeServer(t)
+			api := newTestAPIClient(t, fake, "", testToken)
+
+			got, err := api.CreateProject(context.Background(), tabletest.opt)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tabletest.want, got)
+			require.Equal("git", fake.lastCreate.Scm)
+			require.Equal(tabletest.opt.RepositoryName, fake.lastCreate.Name)
+			require.Equal(tabletest.wantPrivate, fake.lastCreate.IsPrivate)
+			require.Equal(tabletest.wantForkPolicy, fake.lastCreate.ForkPolicy)
+			require.Equal(tabletest.wantProject, fake.lastCreate.Project)
+		})
+	}
+}
+
+func TestAPIClient_ProjectExists(t *testing.T) {
+	tests := []struct {
+		name   string
+		owner  string
+		repo   string
+		want   bool
+		wantID string
+	}{
+		{name: "existing repository", owner: "team", repo: "Repo", want: true, wantID: "team/repo"},
+		{name: "existing repository with project owner", owner: "team/OPS", repo: "repo", want: true, wantID: "team/repo"},
+		{name: "missing repository", owner: "team", repo: "missing"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake := newFakeServer(t)
+			fake.addRepo("team", testRepository("repo", true, "OPS"))
+
+			api := newTestAPIClient(t, fake, "", testToken)
+
+			exists, projectID, err := api.ProjectExists(context.Background(), tabletest.owner, tabletest.repo)
+			require.NoError(err)
+			require.Equal(tabletest.want, exists)
+			require.Equal(tabletest.wantID, projectID)
+		})
+	}
+}
+
+func TestAPIClient_SetDefaultBranch(t *testing.T) {
+	require := require.New(t)
+
+	fake := newFakeServer(t)
+	fake.addRepo("team", testRepository("repo", true, "OPS"))
+
+	api := newTestAPIClient(t, fake, "", testToken)
+
+	require.NoError(api.SetDefaultBranch(context.Background(), "team/OPS", "repo", "develop"))
+	require.Equal("develop", fake.repos["team"][0].MainBranch.Name)
+}
+
+func TestAPIClient_ProtectUnprotect(t *testing.T) {
+	require := require.New(t)
+
+	fake := newFakeServer(t)
+	fake.addRepo("team", testRepository("repo", true, "OPS"))
+
+	api := newTestAPIClient(t, fake, "", testToken)
+
+	require.NoError(api.Protect(context.Background(), "team", "main", "team/repo"))
+
+	restrictions := fake.restrictions["team/repo"]
+	require.Len(restrictions, 2)
+	require.Equal(restrictionPush, restrictions[0].Kind)
+	require.Equal("*", restrictions[0].Pattern)
+	require.Empty(restrictions[0].Users)
+	require.Equal(restrictionDelete, restrictions[1].Kind)
+	require.Equal("main", restrictions[1].Pattern)
+
+	require.NoError(api.Unprotect(context.Background(), "main", "team/repo"))
+	require.Empty(fake.restrictions["team/repo"])
+
+	require.ErrorIs(api.Protect(context.Background(), "team", "main", "invalid"), ErrInvalidProjectID)
+}
+
+func TestAPIClient_IsValidProjectName(t *testing.T) {
+	api := APIClient{}
+
+	require.True(t, api.IsValidProjectName(context.Background(), "valid-name"))
+	require.False(t, api.IsValidProjectName(context.Background(), "invalid name"))
+}
+
+func TestAPIClient_Name(t *testing.T) {
+	require.Equal(t, config.BITBUCKET, APIClient{}.Name())
+}
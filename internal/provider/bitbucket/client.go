@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	apiVersionPath = "/2.0"
+	defaultDomain  = "bitbucket.org"
+	pageLen        = 100
+
+	// TokenUser is the user name to authenticate git over HTTPS with an access token.
+	// REST requests made with it send the token as a bearer token.
+	TokenUser = "x-token-auth"
+)
+
+var ErrNoBaseURL = errors.New("no Bitbucket base URL configured")
+
+// Client is a minimal Bitbucket Cloud REST client.
+// It authenticates with an app password as basic auth when a user name is given,
+// and with an access token sent as a bearer token otherwise.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	username   string
+	token      string
+}
+
+// APIError is returned for responses with a non-2xx status code.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// isNotFound reports whether err is an APIError with status 404.
+func isNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// page is a page of a paginated Bitbucket Cloud collection.
+type page[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// errorResponse is the error body returned by Bitbucket Cloud.
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewClient(httpClient *http.Client, baseURL, username, token string) (*Client, error) {
+	if baseURL == "" {
+		return nil, ErrNoBaseURL
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		username:   username,
+		token:      token,
+	}, nil
+}
+
+// do sends a request to path, relative to the base URL, encoding body as JSON
+// and decoding the response into out, when they are not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	return c.doURL(ctx, method, reqURL, path, body, out)
+}
+
+// doURL sends a request to the absolute reqURL. The path is only used in errors.
+func (c *Client) doURL(ctx context.Context, method, reqURL, path string, body, out any) error {
+	var reqBody io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.setAuth(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(method, path, resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+func (c *Client) setAuth(req *http.Request) {
+	if c.token == "" {
+		return
+	}
+
+	if c.username != "" && c.username != TokenUser {
+		req.SetBasicAuth(c.username, c.token)
+
+		return
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+}
+
+func newAPIError(method, path string, resp *http.Response) error {
+	apiErr := &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	var errResp errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error.Message != "" {
+		apiErr.Message = errResp.Error.Message
+	}
+
+	return apiErr
+}
+
+// getAll fetches every page of the paginated collection at path, following the next links.
+func getAll[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	var all []T
+
+	if query == nil {
+		query = url.Values{}
+	}
+
+	query.Set("pagelen", strconv.Itoa(pageLen))
+	next := c.baseURL + path + "?" + query.Encode()
+
+	for next != "" {
+		var current page[T]
+		if err := c.doURL(ctx, http.MethodGet, next, path, nil, &current); err != nil {
+			return nil, err
+		}
+
+		all = append(all, current.Values...)
+		next = current.Next
+	}
+
+	return all, nil
+}
+
+// repoPath returns the REST path of a repository.
+func repoPath(workspace, slug string) string {
+	return "/repositories/" + url.PathEscape(workspace) + "/" + url.PathEscape(slug)
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxSlugLength is the maximum length of a Bitbucket Cloud repository slug.
+const maxSlugLength = 62
+
+// Regular expression for valid Bitbucket Cloud repository slug characters.
+// It allows slugs made of letters, numbers, dots, underscores and hyphens.
+var slugRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// IsValidBitbucketRepositoryName checks if the given name can be used as a Bitbucket Cloud repository slug.
+// It returns true if the name:
+//  1. Contains only valid characters (defined by slugRegex)
+//  2. Is at most 62 characters long
+//  3. Is not "." or ".." and does not end with ".git"
+//
+// Parameters:
+//   - name: The repository name to validate
+//
+// Returns:
+//   - bool: true if the name is valid, false otherwise
+func IsValidBitbucketRepositoryName(name string) bool {
+	if len(name) > maxSlugLength || !slugRegex.MatchString(name) {
+		return false
+	}
+
+	if name == "." || name == ".." || strings.HasSuffix(strings.ToLower(name), ".git") {
+		return false
+	}
+
+	return true
+}
+
+// Slug returns the repository slug of a repository name, which is used in REST and clone URLs.
+// Bitbucket Cloud slugs are lowercase.
+func Slug(name string) string {
+	return strings.ToLower(name)
+}
+
+// SplitOwner splits an owner into the workspace and the optional project key, given as <workspace>/<project key>.
+func SplitOwner(owner string) (string, string) {
+	workspace, projectKey, _ := strings.Cut(owner, "/")
+
+	return workspace, projectKey
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidBitbucketRepositoryName(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		want     bool
+	}{
+		{"simple name", "repo", true},
+		{"mixed characters", "My_Repo-1.0", true},
+		{"max length", strings.Repeat("a", 62), true},
+		{"too long", strings.Repeat("a", 63), false},
+		{"empty", "", false},
+		{"space", "my repo", false},
+		{"slash", "my/repo", false},
+		{"dot", ".", false},
+		{"dot dot", "..", false},
+		{"git suffix", "repo.git", false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, IsValidBitbucketRepositoryName(tabletest.repoName))
+		})
+	}
+}
+
+func TestSplitOwner(t *testing.T) {
+	tests := []struct {
+		name          string
+		owner         string
+		wantWorkspace string
+		wantProject   string
+	}{
+		{"workspace", "team", "team", ""},
+		{"workspace and project", "team/PROJ", "team", "PROJ"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			workspace, projectKey := SplitOwner(tabletest.owner)
+			require.Equal(tabletest.wantWorkspace, workspace)
+			require.Equal(tabletest.wantProject, projectKey)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+// Fork policies of a Bitbucket Cloud repository.
+const (
+	forkPolicyAllow = "allow_forks"
+	forkPolicyNone  = "no_forks"
+)
+
+type projectRef struct {
+	Key string `json:"key"`
+}
+
+// createRepositoryOptions is the request body for creating a repository.
+type createRepositoryOptions struct {
+	Scm         string      `json:"scm"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	IsPrivate   bool        `json:"is_private"`
+	ForkPolicy  string      `json:"fork_policy"`
+	HasIssues   bool        `json:"has_issues"`
+	HasWiki     bool        `json:"has_wiki"`
+	Project     *projectRef `json:"project,omitempty"`
+}
+
+type ProjectOptionsBuilder struct {
+	opts *createRepositoryOptions
+}
+
+func NewProjectOptionsBuilder() *ProjectOptionsBuilder {
+	return &ProjectOptionsBuilder{
+		opts: &createRepositoryOptions{Scm: "git", ForkPolicy: forkPolicyAllow, IsPrivate: true},
+	}
+}
+
+func (p *ProjectOptionsBuilder) WithBasicOpts(visibility, name, description, projectKey string) {
+	p.opts.Name = name
+	p.opts.Description = description
+	p.opts.IsPrivate = visibility != PUBLIC
+
+	if projectKey != "" {
+		p.opts.Project = &projectRef{Key: projectKey}
+	}
+}
+
+// WithDisabledFeatures disables forking, issues and the wiki of the repository.
+func (p *ProjectOptionsBuilder) WithDisabledFeatures() {
+	p.opts.ForkPolicy = forkPolicyNone
+	p.opts.HasIssues = false
+	p.opts.HasWiki = false
+}
@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+)
+
+const (
+	PUBLIC  = "public"
+	PRIVATE = "private"
+)
+
+var ErrNoOwner = errors.New("no workspace given")
+
+type repository struct {
+	Slug        string      `json:"slug"`
+	Name        string      `json:"name"`
+	FullName    string      `json:"full_name"`
+	Description string      `json:"description"`
+	IsPrivate   bool        `json:"is_private"`
+	Parent      *repository `json:"parent"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	UpdatedOn *time.Time  `json:"updated_on"`
+	Project   *projectRef `json:"project"`
+	Links     struct {
+		Clone []struct {
+			Href string `json:"href"`
+			Name string `json:"name"`
+		} `json:"clone"`
+	} `json:"links"`
+}
+
+type ProjectService struct {
+	client *Client
+}
+
+func NewProjectService(client *Client) ProjectService {
+	return ProjectService{client: client}
+}
+
+func (p ProjectService) CreateProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:CreateProject")
+	opt.DebugLog(logger).Msg("Bitbucket:CreateOption")
+
+	workspace, projectKey := SplitOwner(opt.Owner)
+	if workspace == "" {
+		return "", fmt.Errorf("%w: repository: %s", ErrNoOwner, opt.RepositoryName)
+	}
+
+	optBuilder := NewProjectOptionsBuilder()
+	optBuilder.WithBasicOpts(opt.Visibility, opt.RepositoryName, opt.Description, projectKey)
+
+	if opt.Disabled {
+		optBuilder.WithDisabledFeatures()
+	}
+
+	var created repository
+	if err := p.client.do(ctx, http.MethodPost, repoPath(workspace, Slug(opt.RepositoryName)), nil, optBuilder.opts, &created); err != nil {
+		return "", fmt.Errorf("failed to create project. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	logger.Debug().Str("name", opt.RepositoryName).Msg("Repository created successfully")
+
+	return created.FullName, nil
+}
+
+func (p ProjectService) ProjectExists(ctx context.Context, owner, repo string) (bool, string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:ProjectExists")
+
+	workspace, _ := SplitOwner(owner)
+
+	var found repository
+
+	err := p.client.do(ctx, http.MethodGet, repoPath(workspace, Slug(repo)), nil, nil, &found)
+	if isNotFound(err) {
+		return false, "", nil
+	}
+
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get repository %s/%s: %w", workspace, repo, err)
+	}
+
+	return true, found.FullName, nil
+}
+
+// GetProjectInfos lists the repositories of the workspace of providerOpt,
+// limited to a project when the owner is given as <workspace>/<project key>.
+func (p ProjectService) GetProjectInfos(ctx context.Context, providerOpt model.ProviderOption, _ bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:GetProjectInfos")
+
+	workspace, projectKey := SplitOwner(providerOpt.Owner)
+
+	query := url.Values{}
+	if projectKey != "" {
+		query.Set("q", fmt.Sprintf("project.key=%q", projectKey))
+	}
+
+	repositories, err := getAll[repository](ctx, p.client, "/repositories/"+url.PathEscape(workspace), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories. workspace: %s, err: %w", workspace, err)
+	}
+
+	logger.Debug().Int("total_repositories", len(repositories)).Msg("Found repositories")
+
+	projectinfos := make([]model.ProjectInfo, 0, len(repositories))
+
+	for _, repo := range repositories {
+		if !providerOpt.IncludeForks && repo.Parent != nil {
+			continue
+		}
+
+		projectinfos = append(projectinfos, newProjectInfo(repo))
+	}
+
+	return projectinfos, nil
+}
+
+func (p ProjectService) SetDefaultBranch(ctx context.Context, owner, projectName, branchName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:SetDefaultBranch")
+
+	workspace, _ := SplitOwner(owner)
+	body := map[string]any{"mainbranch": map[string]string{"name": branchName}}
+
+	if err := p.client.do(ctx, http.MethodPut, repoPath(workspace, Slug(projectName)), nil, body, nil); err != nil {
+		return fmt.Errorf("failed to set default branch. err: %w", err)
+	}
+
+	return nil
+}
+
+func newProjectInfo(repo repository) model.ProjectInfo {
+	var defaultBranch string
+	if repo.MainBranch != nil {
+		defaultBranch = repo.MainBranch.Name
+	}
+
+	httpsURL, sshURL := cloneURLs(repo)
+
+	return model.ProjectInfo{
+		DefaultBranch:  defaultBranch,
+		Description:    repo.Description,
+		HTTPSURL:       httpsURL,
+		LastActivityAt: repo.UpdatedOn,
+		OriginalName:   repo.Name,
+		ProjectID:      repo.FullName,
+		SSHURL:         sshURL,
+		Visibility:     getVisibility(repo.IsPrivate),
+	}
+}
+
+// cloneURLs returns the HTTPS and SSH clone URLs of repo.
+// The user name Bitbucket adds to the HTTPS clone URL of the authenticated user is removed.
+func cloneURLs(repo repository) (string, string) {
+	var httpsURL, sshURL string
+
+	for _, link := range repo.Links.Clone {
+		switch link.Name {
+		case "https":
+			httpsURL = link.Href
+
+			if parsed, err := url.Parse(link.Href); err == nil {
+				parsed.User = nil
+				httpsURL = parsed.String()
+			}
+		case "ssh":
+			sshURL = link.Href
+		}
+	}
+
+	return httpsURL, sshURL
+}
+
+func getVisibility(isPrivate bool) string {
+	if isPrivate {
+		return PRIVATE
+	}
+
+	return PUBLIC
+}
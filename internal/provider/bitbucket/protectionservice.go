@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+var ErrInvalidProjectID = errors.New("invalid project id, expected <workspace>/<slug>")
+
+// Branch restriction kinds.
+const (
+	restrictionPush   = "push"
+	restrictionDelete = "delete"
+)
+
+type branchRestriction struct {
+	ID              int      `json:"id,omitempty"`
+	Kind            string   `json:"kind"`
+	BranchMatchKind string   `json:"branch_match_kind"`
+	Pattern         string   `json:"pattern"`
+	Users           []string `json:"users"`
+	Groups          []string `json:"groups"`
+}
+
+type ProtectionService struct {
+	client *Client
+}
+
+func NewProtectionService(client *Client) ProtectionService {
+	return ProtectionService{client: client}
+}
+
+// Protect prevents pushing to every branch of the repository and deleting its default branch.
+func (p ProtectionService) Protect(ctx context.Context, _ string, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:Protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("Bitbucket:Protect")
+
+	path, err := restrictionsPath(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	restrictions := []branchRestriction{
+		newBranchRestriction(restrictionPush, "*"),
+		newBranchRestriction(restrictionDelete, branch),
+	}
+
+	for _, restriction := range restrictions {
+		if err := p.client.do(ctx, http.MethodPost, path, nil, restriction, nil); err != nil {
+			return fmt.Errorf("failed to add %s branch restriction for %s. err: %w", restriction.Kind, restriction.Pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Unprotect removes every branch restriction of the repository.
+func (p ProtectionService) Unprotect(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:Unprotect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("Bitbucket:Unprotect")
+
+	path, err := restrictionsPath(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	restrictions, err := getAll[branchRestriction](ctx, p.client, path, nil)
+	if isNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to list branch restrictions. err: %w", err)
+	}
+
+	for _, restriction := range restrictions {
+		if err := p.client.do(ctx, http.MethodDelete, path+"/"+strconv.Itoa(restriction.ID), nil, nil, nil); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to remove branch restriction %d. err: %w", restriction.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func newBranchRestriction(kind, pattern string) branchRestriction {
+	return branchRestriction{
+		Kind:            kind,
+		BranchMatchKind: "glob",
+		Pattern:         pattern,
+		Users:           []string{},
+		Groups:          []string{},
+	}
+}
+
+// restrictionsPath returns the branch restrictions path of the repository identified by <workspace>/<slug>.
+func restrictionsPath(projectIDStr string) (string, error) {
+	workspace, slug, found := strings.Cut(projectIDStr, "/")
+	if !found || workspace == "" || slug == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidProjectID, projectIDStr)
+	}
+
+	return repoPath(workspace, slug) + "/branch-restrictions", nil
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
+)
+
+// APIClient represents a facade to Bitbucket Server / Data Center API operations.
+type APIClient struct {
+	raw               *Client
+	projectService    interfaces.ProjectServicer
+	protectionService interfaces.ProtectionServicer
+	filterService     interfaces.FilterServicer
+}
+
+func (api APIClient) CreateProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:CreateProject")
+	opt.DebugLog(logger).Msg("BitbucketServer:CreateOption")
+
+	projectID, err := api.projectService.CreateProject(ctx, opt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create a Bitbucket Server project. err: %w", err)
+	}
+
+	return projectID, nil
+}
+
+func (api APIClient) ProjectExists(ctx context.Context, owner, repo string) (bool, string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:ProjectExists")
+
+	exists, projectID, err := api.projectService.ProjectExists(ctx, owner, repo)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to see if project existed. err: %w", err)
+	}
+
+	return exists, projectID, nil
+}
+
+func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:IsValidProjectName")
+	logger.Debug().Str("name", name).Msg("BitbucketServer:IsValidProjectName")
+
+	return IsValidBitbucketServerRepositoryName(name)
+}
+
+func (APIClient) Name() string {
+	return config.BITBUCKETSERVER
+}
+
+func (api APIClient) GetProjectInfos(ctx context.Context, providerOpt model.ProviderOption, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:ProjectInfos")
+	logger.Debug().Bool("filtering", filtering).Msg("BitbucketServer:ProjectInfos")
+
+	projectInfos, err := api.projectService.GetProjectInfos(ctx, providerOpt, filtering)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project infos. err: %w", err)
+	}
+
+	if filtering {
+		return api.filterService.FilterProjectinfos(ctx, providerOpt, projectInfos, targetfilter.FilterIncludedExcludedGen(), targetfilter.IsInInterval) //nolint
+	}
+
+	return projectInfos, nil
+}
+
+func (api APIClient) Protect(ctx context.Context, owner string, defaultBranch string, projectIDstr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:Protect")
+	logger.Debug().Str("defaultBranch", defaultBranch).Str("projectIDStr", projectIDstr).Msg("BitbucketServer:Protect")
+
+	if err := api.protectionService.Protect(ctx, owner, defaultBranch, projectIDstr); err != nil {
+		return fmt.Errorf("failed to protect project. projectIDStr: %s, err: %w", projectIDstr, err)
+	}
+
+	return nil
+}
+
+func (api APIClient) SetDefaultBranch(ctx context.Context, owner, projectName, branch string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:SetDefaultBranch")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("branch", branch).Msg("BitbucketServer:SetDefaultBranch")
+
+	if err := api.projectService.SetDefaultBranch(ctx, owner, projectName, branch); err != nil {
+		return fmt.Errorf("failed to set default branch: %s, projectName: %s, owner: %s, err: %w", branch, projectName, owner, err)
+	}
+
+	return nil
+}
+
+func (api APIClient) Unprotect(ctx context.Context, defaultBranch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:Unprotect")
+	logger.Debug().Str("defaultBranch", defaultBranch).Str("projectIDStr", projectIDStr).Msg("BitbucketServer:Unprotect")
+
+	if err := api.protectionService.Unprotect(ctx, defaultBranch, projectIDStr); err != nil {
+		return fmt.Errorf("failed to unprotect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+func NewBitbucketServerAPIClient(ctx context.Context, httpClient *http.Client, opt model.GitProviderClientOption) (APIClient, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:NewBitbucketServerAPIClient")
+
+	baseURL := ""
+	if opt.Domain != "" {
+		baseURL = opt.DomainWithScheme(opt.AuthCfg.HTTPScheme)
+	}
+
+	rawClient, err := NewClient(httpClient, baseURL, opt.AuthCfg.Token)
+	if err != nil {
+		return APIClient{}, fmt.Errorf("create new Bitbucket Server client: %w", err)
+	}
+
+	return APIClient{
+		raw:               rawClient,
+		projectService:    NewProjectService(rawClient),
+		protectionService: NewProtectionService(rawClient),
+		filterService:     NewFilter(),
+	}, nil
+}
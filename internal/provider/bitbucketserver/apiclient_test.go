@@ -0,0 +1,491 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package bitbucketserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const testToken = "secret-token"
+
+// fakeServer is a minimal in-memory Bitbucket Server REST API.
+type fakeServer struct {
+	mu             sync.Mutex
+	repos          map[string][]repository
+	defaultBranch  map[string]string
+	restrictions   map[string][]restriction
+	lastCreate     createRepositoryOptions
+	nextRestrictID int
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeServer{
+		repos:         map[string][]repository{},
+		defaultBranch: map[string]string{},
+		restrictions:  map[string][]restriction{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiPath+"/projects/{key}/repos", fake.listRepos)
+	mux.HandleFunc("POST "+apiPath+"/projects/{key}/repos", fake.createRepo)
+	mux.HandleFunc("GET "+apiPath+"/projects/{key}/repos/{slug}", fake.getRepo)
+	mux.HandleFunc("GET "+apiPath+"/projects/{key}/repos/{slug}/default-branch", fake.getDefaultBranch)
+	mux.HandleFunc("PUT "+apiPath+"/projects/{key}/repos/{slug}/default-branch", fake.setDefaultBranch)
+	mux.HandleFunc("GET "+apiPath+"/projects/{key}/repos/{slug}/commits", fake.listCommits)
+	mux.HandleFunc("GET "+branchPermissionPath+"/projects/{key}/repos/{slug}/restrictions", fake.listRestrictions)
+	mux.HandleFunc("POST "+branchPermissionPath+"/projects/{key}/repos/{slug}/restrictions", fake.addRestriction)
+	mux.HandleFunc("DELETE "+branchPermissionPath+"/projects/{key}/repos/{slug}/restrictions/{id}", fake.deleteRestriction)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			writeError(w, http.StatusUnauthorized, "Authentication failed")
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeServer) addRepo(key string, repo repository) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repo.Project.Key = key
+	f.repos[key] = append(f.repos[key], repo)
+}
+
+func (f *fakeServer) findRepo(key, slug string) (repository, bool) {
+	for _, repo := range f.repos[key] {
+		if repo.Slug == slug {
+			return repo, true
+		}
+	}
+
+	return repository{}, false
+}
+
+// listRepos serves the repositories of a project two per page.
+func (f *fakeServer) listRepos(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repos, ok := f.repos[r.PathValue("key")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Project does not exist")
+
+		return
+	}
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	end := min(start+2, len(repos))
+
+	writeJSON(w, page[repository]{Values: repos[start:end], IsLastPage: end == len(repos), NextPageStart: end})
+}
+
+func (f *fakeServer) createRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var opts createRepositoryOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.lastCreate = opts
+
+	repo := repository{Name: opts.Name, Slug: Slug(opts.Name), Public: opts.Public}
+	repo.Project.Key = r.PathValue("key")
+	f.repos[repo.Project.Key] = append(f.repos[repo.Project.Key], repo)
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, repo)
+}
+
+func (f *fakeServer) getRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repo, ok := f.findRepo(r.PathValue("key"), r.PathValue("slug"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Repository does not exist")
+
+		return
+	}
+
+	writeJSON(w, repo)
+}
+
+func (f *fakeServer) getDefaultBranch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name, ok := f.defaultBranch[r.PathValue("key")+"/"+r.PathValue("slug")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No default branch")
+
+		return
+	}
+
+	writeJSON(w, branch{ID: "refs/heads/" + name, DisplayID: name})
+}
+
+func (f *fakeServer) setDefaultBranch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.defaultBranch[r.PathValue("key")+"/"+r.PathValue("slug")] = strings.TrimPrefix(body.ID, "refs/heads/")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeServer) listCommits(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, page[commit]{Values: []commit{{ID: "abc", CommitterTimestamp: 1700000000000}}, IsLastPage: true})
+}
+
+func (f *fakeServer) listRestrictions(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, page[restriction]{Values: f.restrictions[r.PathValue("key")+"/"+r.PathValue("slug")], IsLastPage: true})
+}
+
+func (f *fakeServer) addRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var added restriction
+	if err := json.NewDecoder(r.Body).Decode(&added); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	f.nextRestrictID++
+	added.ID = f.nextRestrictID
+	id := r.PathValue("key") + "/" + r.PathValue("slug")
+	f.restrictions[id] = append(f.restrictions[id], added)
+
+	writeJSON(w, added)
+}
+
+func (f *fakeServer) deleteRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := r.PathValue("key") + "/" + r.PathValue("slug")
+	kept := f.restrictions[id][:0]
+
+	for _, existing := range f.restrictions[id] {
+		if strconv.Itoa(existing.ID) != r.PathValue("id") {
+			kept = append(kept, existing)
+		}
+	}
+
+	f.restrictions[id] = kept
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `{"errors":[{"message":%q}]}`, message)
+}
+
+func newTestAPIClient(t *testing.T, server *httptest.Server, token string) APIClient {
+	t.Helper()
+
+	api, err := NewBitbucketServerAPIClient(context.Background(), server.Client(), model.GitProviderClientOption{
+		ProviderType: config.BITBUCKETSERVER,
+		Domain:       strings.TrimPrefix(server.URL, "http://"),
+		AuthCfg:      config.AuthConfig{HTTPScheme: "http", Token: token},
+	})
+	require.NoError(t, err)
+
+	return api
+}
+
+func testRepository(slug string, public bool) repository {
+	repo := repository{Name: slug, Slug: slug, Public: public}
+	repo.Links.Clone = []struct {
+		Href string `json:"href"`
+		Name string `json:"name"`
+	}{
+		{Href: "https://admin@bitbucket.example.com/scm/proj/" + slug + ".git", Name: "http"},
+		{Href: "ssh://git@bitbucket.example.com:7999/proj/" + slug + ".git", Name: "ssh"},
+	}
+
+	return repo
+}
+
+func TestAPIClient_NewBitbucketServerAPIClient(t *testing.T) {
+	_, err := NewBitbucketServerAPIClient(context.Background(), http.DefaultClient, model.GitProviderClientOption{})
+	require.ErrorIs(t, err, ErrNoBaseURL)
+}
+
+func TestAPIClient_GetProjectInfos(t *testing.T) {
+	tests := []struct {
+		name         string
+		providerOpt  model.ProviderOption
+		wantNames    []string
+		wantErr      bool
+		wantAPIError int
+	}{
+		{
+			name:        "project repositories over several pages without forks",
+			providerOpt: model.ProviderOption{Owner: "PROJ", OwnerType: config.GROUP},
+			wantNames:   []string{"one", "two", "four"},
+		},
+		{
+			name:        "project repositories including forks",
+			providerOpt: model.ProviderOption{Owner: "PROJ", OwnerType: config.GROUP, IncludeForks: true},
+			wantNames:   []string{"one", "two", "fork", "four"},
+		},
+		{
+			name:        "personal repositories of a user",
+			providerOpt: model.ProviderOption{Owner: "alice", OwnerType: config.USER},
+			wantNames:   []string{"personal"},
+		},
+		{
+			name:         "unknown project",
+			providerOpt:  model.ProviderOption{Owner: "NOPE", OwnerType: config.GROUP},
+			wantErr:      true,
+			wantAPIError: http.StatusNotFound,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake, server := newFakeServer(t)
+			fork := testRepository("fork", false)
+			fork.Origin = &repository{Slug: "upstream"}
+
+			fake.addRepo("PROJ", testRepository("one", true))
+			fake.addRepo("PROJ", testRepository("two", false))
+			fake.addRepo("PROJ", fork)
+			fake.addRepo("PROJ", testRepository("four", false))
+			fake.addRepo("~alice", testRepository("personal", false))
+			fake.defaultBranch["PROJ/one"] = "main"
+
+			api := newTestAPIClient(t, server, testToken)
+
+			got, err := api.GetProjectInfos(context.Background(), tabletest.providerOpt, false)
+			if tabletest.wantErr {
+				var apiErr *APIError
+
+				require.ErrorAs(err, &apiErr)
+				require.Equal(tabletest.wantAPIError, apiErr.StatusCode)
+
+				return
+			}
+
+			require.NoError(err)
+
+			names := make([]string, 0, len(got))
+			for _, info := range got {
+				names = append(names, info.OriginalName)
+			}
+
+			require.Equal(tabletest.wantNames, names)
+
+			if tabletest.providerOpt.Owner == "PROJ" {
+				require.Equal("main", got[0].DefaultBranch)
+				require.Equal(PUBLIC, got[0].Visibility)
+				require.Equal(PRIVATE, got[1].Visibility)
+				require.Equal("PROJ/one", got[0].ProjectID)
+				require.Equal("https://bitbucket.example.com/scm/proj/one.git", got[0].HTTPSURL)
+				require.Equal("ssh://git@bitbucket.example.com:7999/proj/one.git", got[0].SSHURL)
+				require.NotNil(got[0].LastActivityAt)
+				require.Equal(int64(1700000000000), got[0].LastActivityAt.UnixMilli())
+			}
+		})
+	}
+}
+
+func TestAPIClient_CreateProject(t *testing.T) {
+	tests := []struct {
+		name         string
+		opt          model.CreateProjectOption
+		want         string
+		wantForkable bool
+		wantPublic   bool
+		wantErr      error
+	}{
+		{
+			name: "public repository",
+			opt: model.CreateProjectOption{
+				RepositoryName: "My Repo", Owner: "PROJ", Visibility: PUBLIC, Description: "desc", DefaultBranch: "main",
+			},
+			want:         "PROJ/my-repo",
+			wantForkable: true,
+			wantPublic:   true,
+		},
+		{
+			name: "disabled private repository",
+			opt: model.CreateProjectOption{
+				RepositoryName: "repo", Owner: "PROJ", Visibility: PRIVATE, Disabled: true,
+			},
+			want: "PROJ/repo",
+		},
+		{
+			name:    "missing owner",
+			opt:     model.CreateProjectOption{RepositoryName: "repo"},
+			wantErr: ErrNoOwner,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake, server := newFakeServer(t)
+			api := newTestAPIClient(t, server, testToken)
+
+			got, err := api.CreateProject(context.Background(), tabletest.opt)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tabletest.want, got)
+			require.Equal(tabletest.opt.RepositoryName, fake.lastCreate.Name)
+			require.Equal("git", fake.lastCreate.ScmID)
+			require.Equal(tabletest.opt.Description, fake.lastCreate.Description)
+			require.Equal(tabletest.wantForkable, fake.lastCreate.Forkable)
+			require.Equal(tabletest.wantPublic, fake.lastCreate.Public)
+		})
+	}
+}
+
+func TestAPIClient_ProjectExists(t *testing.T) {
+	tests := []struct {
+		name       string
+		owner      string
+		repo       string
+		want       bool
+		wantID     string
+		wantErr    bool
+		wrongToken bool
+	}{
+		{name: "existing repository", owner: "PROJ", repo: "Repo", want: true, wantID: "PROJ/repo"},
+		{name: "missing repository", owner: "PROJ", repo: "missing"},
+		{name: "unauthorized", owner: "PROJ", repo: "repo", wantErr: true, wrongToken: true},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake, server := newFakeServer(t)
+			fake.addRepo("PROJ", testRepository("repo", false))
+
+			token := testToken
+			if tabletest.wrongToken {
+				token = "wrong"
+			}
+
+			api := newTestAPIClient(t, server, token)
+
+			exists, projectID, err := api.ProjectExists(context.Background(), tabletest.owner, tabletest.repo)
+			if tabletest.wantErr {
+				var apiErr *APIError
+
+				require.ErrorAs(err, &apiErr)
+				require.Equal(http.StatusUnauthorized, apiErr.StatusCode)
+				require.Equal("Authentication failed", apiErr.Message)
+
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tabletest.want, exists)
+			require.Equal(tabletest.wantID, projectID)
+		})
+	}
+}
+
+func TestAPIClient_SetDefaultBranch(t *testing.T) {
+	require := require.New(t)
+
+	fake, server := newFakeServer(t)
+	fake.addRepo("PROJ", testRepository("repo", false))
+
+	api := newTestAPIClient(t, server, testToken)
+
+	require.NoError(api.SetDefaultBranch(context.Background(), "PROJ", "repo", "develop"))
+	require.Equal("develop", fake.defaultBranch["PROJ/repo"])
+}
+
+func TestAPIClient_ProtectUnprotect(t *testing.T) {
+	require := require.New(t)
+
+	fake, server := newFakeServer(t)
+	fake.addRepo("PROJ", testRepository("repo", false))
+
+	api := newTestAPIClient(t, server, testToken)
+
+	require.NoError(api.Protect(context.Background(), "PROJ", "main", "PROJ/repo"))
+
+	restrictions := fake.restrictions["PROJ/repo"]
+	require.Len(restrictions, 2)
+	require.Equal(restrictionReadOnly, restrictions[0].Type)
+	require.Equal("PATTERN", restrictions[0].Matcher.Type.ID)
+	require.Equal("*", restrictions[0].Matcher.ID)
+	require.Equal(restrictionNoDeletes, restrictions[1].Type)
+	require.Equal("BRANCH", restrictions[1].Matcher.Type.ID)
+	require.Equal("refs/heads/main", restrictions[1].Matcher.ID)
+
+	require.NoError(api.Unprotect(context.Background(), "main", "PROJ/repo"))
+	require.Empty(fake.restrictions["PROJ/repo"])
+
+	require.ErrorIs(api.Protect(context.Background(), "PROJ", "main", "invalid"), ErrInvalidProjectID)
+}
+
+func TestAPIClient_IsValidProjectName(t *testing.T) {
+	api := APIClient{}
+
+	require.True(t, api.IsValidProjectName(context.Background(), "valid-name"))
+	require.False(t, api.IsValidProjectName(context.Background(), "-invalid"))
+}
+
+func TestAPIClient_Name(t *testing.T) {
+	require.Equal(t, config.BITBUCKETSERVER, APIClient{}.Name())
+}
@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	apiPath              = "/rest/api/1.0"
+	branchPermissionPath = "/rest/branch-permissions/2.0"
+	pageLimit            = 100
+)
+
+var ErrNoBaseURL = errors.New("no Bitbucket Server base URL configured")
+
+// Client is a minimal Bitbucket Server / Data Center REST client.
+// It authenticates with an HTTP access token sent as a bearer token.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// APIError is returned for responses with a non-2xx status code.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// isNotFound reports whether err is an APIError with status 404.
+func isNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// page is a page of a paged Bitbucket Server collection.
+type page[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// errorResponse is the error body returned by Bitbucket Server.
+type errorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func NewClient(httpClient *http.Client, baseURL, token string) (*Client, error) {
+	if baseURL == "" {
+		return nil, ErrNoBaseURL
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+	}, nil
+}
+
+// do sends a request to path, relative to the base URL, encoding body as JSON
+// and decoding the response into out, when they are not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(method, path, resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+func newAPIError(method, path string, resp *http.Response) error {
+	apiErr := &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	var errResp errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && len(errResp.Errors) > 0 {
+		messages := make([]string, 0, len(errResp.Errors))
+		for _, e := range errResp.Errors {
+			messages = append(messages, e.Message)
+		}
+
+		apiErr.Message = strings.Join(messages, "; ")
+	}
+
+	return apiErr
+}
+
+// getAll fetches every page of the paged collection at path.
+func getAll[T any](ctx context.Context, c *Client, path string) ([]T, error) {
+	var all []T
+
+	start := 0
+
+	for {
+		query := url.Values{
+			"start": []string{strconv.Itoa(start)},
+			"limit": []string{strconv.Itoa(pageLimit)},
+		}
+
+		var current page[T]
+		if err := c.do(ctx, http.MethodGet, path, query, nil, &current); err != nil {
+			return nil, err
+		}
+
+		all = append(all, current.Values...)
+
+		if current.IsLastPage || len(current.Values) == 0 {
+			return all, nil
+		}
+
+		start = current.NextPageStart
+	}
+}
+
+// repoPath returns the REST path of a repository.
+func repoPath(projectKey, slug string) string {
+	return "/projects/" + url.PathEscape(projectKey) + "/repos/" + url.PathEscape(slug)
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxNameLength is the maximum length of a Bitbucket Server repository name.
+const maxNameLength = 128
+
+// Regular expression for valid Bitbucket Server repository name characters.
+// It allows names that start with a letter or number,
+// followed by any number of letters, numbers, spaces, dots, underscores or hyphens.
+var nameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9 ._-]*$`)
+
+// slugReplaceRegex matches the runs of characters that are replaced by a hyphen in a repository slug.
+var slugReplaceRegex = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// IsValidBitbucketServerRepositoryName checks if the given name is a valid Bitbucket Server repository name.
+// It returns true if the name:
+//  1. Contains only valid characters (defined by nameRegex)
+//  2. Is at most 128 characters long
+//
+// Parameters:
+//   - name: The repository name to validate
+//
+// Returns:
+//   - bool: true if the name is valid, false otherwise
+func IsValidBitbucketServerRepositoryName(name string) bool {
+	return len(name) <= maxNameLength && nameRegex.MatchString(name)
+}
+
+// Slug returns the repository slug Bitbucket Server derives from a repository name,
+// which is used in its REST and clone URLs.
+//
+// Parameters:
+//   - name: The repository name
+//
+// Returns:
+//   - string: The lowercase name with every run of other characters than letters, numbers,
+//     dots, underscores and hyphens replaced by a hyphen
+func Slug(name string) string {
+	return slugReplaceRegex.ReplaceAllString(strings.ToLower(name), "-")
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidBitbucketServerRepositoryName(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		want     bool
+	}{
+		{"valid name", "valid-repo-name", true},
+		{"valid name with numbers", "repo123", true},
+		{"valid name with dots", "repo.name", true},
+		{"valid name with underscore", "repo_name", true},
+		{"valid name with space", "repo name", true},
+		{"valid name with max length", strings.Repeat("a", 128), true},
+		{"invalid name too long", strings.Repeat("a", 129), false},
+		{"invalid name with plus", "repo+name", false},
+		{"invalid name with at symbol", "invalid@repo", false},
+		{"invalid name starting with dot", ".invalidrepo", false},
+		{"invalid name starting with hyphen", "-invalidrepo", false},
+		{"invalid name starting with underscore", "_invalidrepo", false},
+		{"empty name", "", false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, IsValidBitbucketServerRepositoryName(tabletest.repoName))
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		want     string
+	}{
+		{"lowercase name", "repo", "repo"},
+		{"mixed case name", "My.Repo_Name", "my.repo_name"},
+		{"name with spaces", "my  repo name", "my-repo-name"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, Slug(tabletest.repoName))
+		})
+	}
+}
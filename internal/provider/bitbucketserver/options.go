@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+// createRepositoryOptions is the request body for creating a repository.
+type createRepositoryOptions struct {
+	Name          string `json:"name"`
+	ScmID         string `json:"scmId"`
+	Description   string `json:"description,omitempty"`
+	DefaultBranch string `json:"defaultBranch,omitempty"`
+	Forkable      bool   `json:"forkable"`
+	Public        bool   `json:"public"`
+}
+
+type ProjectOptionsBuilder struct {
+	opts *createRepositoryOptions
+}
+
+func NewProjectOptionsBuilder() *ProjectOptionsBuilder {
+	return &ProjectOptionsBuilder{
+		opts: &createRepositoryOptions{ScmID: "git", Forkable: true},
+	}
+}
+
+func (p *ProjectOptionsBuilder) WithBasicOpts(visibility, name, description, defaultBranch string) {
+	p.opts.Name = name
+	p.opts.Description = description
+	p.opts.DefaultBranch = defaultBranch
+	p.opts.Public = visibility == PUBLIC
+}
+
+// WithDisabledFeatures disables forking, the only repository feature that can be turned off on creation.
+func (p *ProjectOptionsBuilder) WithDisabledFeatures() {
+	p.opts.Forkable = false
+}
@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+)
+
+const (
+	PUBLIC  = "public"
+	PRIVATE = "private"
+)
+
+var ErrNoOwner = errors.New("no project key given")
+
+type repository struct {
+	ID          int         `json:"id"`
+	Slug        string      `json:"slug"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Public      bool        `json:"public"`
+	Origin      *repository `json:"origin"`
+	Project     struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Clone []struct {
+			Href string `json:"href"`
+			Name string `json:"name"`
+		} `json:"clone"`
+	} `json:"links"`
+}
+
+type branch struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId"`
+}
+
+type commit struct {
+	ID                 string `json:"id"`
+	CommitterTimestamp int64  `json:"committerTimestamp"`
+}
+
+type ProjectService struct {
+	client *Client
+}
+
+func NewProjectService(client *Client) ProjectService {
+	return ProjectService{client: client}
+}
+
+func (p ProjectService) CreateProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:CreateProject")
+	opt.DebugLog(logger).Msg("BitbucketServer:CreateOption")
+
+	if opt.Owner == "" {
+		return "", fmt.Errorf("%w: repository: %s", ErrNoOwner, opt.RepositoryName)
+	}
+
+	optBuilder := NewProjectOptionsBuilder()
+	optBuilder.WithBasicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch)
+
+	if opt.Disabled {
+		optBuilder.WithDisabledFeatures()
+	}
+
+	var created repository
+	if err := p.client.do(ctx, http.MethodPost, apiPath+"/projects/"+url.PathEscape(opt.Owner)+"/repos", nil, optBuilder.opts, &created); err != nil {
+		return "", fmt.Errorf("failed to create project. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	logger.Debug().Str("name", opt.RepositoryName).Msg("Repository created successfully")
+
+	return projectID(created.Project.Key, created.Slug), nil
+}
+
+func (p ProjectService) ProjectExists(ctx context.Context, owner, repo string) (bool, string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:ProjectExists")
+
+	var found repository
+
+	err := p.client.do(ctx, http.MethodGet, apiPath+repoPath(owner, Slug(repo)), nil, nil, &found)
+	if isNotFound(err) {
+		return false, "", nil
+	}
+
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get repository %s/%s: %w", owner, repo, err)
+	}
+
+	return true, projectID(found.Project.Key, found.Slug), nil
+}
+
+func (p ProjectService) GetProjectInfos(ctx context.Context, providerOpt model.ProviderOption, _ bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:GetProjectInfos")
+
+	key := projectKey(providerOpt)
+
+	repositories, err := getAll[repository](ctx, p.client, apiPath+"/projects/"+url.PathEscape(key)+"/repos")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories. projectKey: %s, err: %w", key, err)
+	}
+
+	logger.Debug().Int("total_repositories", len(repositories)).Msg("Found repositories")
+
+	projectinfos := make([]model.ProjectInfo, 0, len(repositories))
+
+	for _, repo := range repositories {
+		if !providerOpt.IncludeForks && repo.Origin != nil {
+			continue
+		}
+
+		projectInfo, err := p.newProjectInfo(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init projectInfo. slug: %s, err: %w", repo.Slug, err)
+		}
+
+		projectinfos = append(projectinfos, projectInfo)
+	}
+
+	return projectinfos, nil
+}
+
+func (p ProjectService) newProjectInfo(ctx context.Context, repo repository) (model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:newProjectInfo")
+	logger.Debug().Str("projectKey", repo.Project.Key).Str("slug", repo.Slug).Msg("BitbucketServer:newProjectInfo")
+
+	path := apiPath + repoPath(repo.Project.Key, repo.Slug)
+
+	var defaultBranch branch
+	if err := p.client.do(ctx, http.MethodGet, path+"/default-branch", nil, nil, &defaultBranch); err != nil && !isNotFound(err) {
+		return model.ProjectInfo{}, fmt.Errorf("failed to get default branch: %w", err)
+	}
+
+	var commits page[commit]
+	if err := p.client.do(ctx, http.MethodGet, path+"/commits", url.Values{"limit": []string{"1"}}, nil, &commits); err != nil && !isNotFound(err) {
+		return model.ProjectInfo{}, fmt.Errorf("failed to get latest commit: %w", err)
+	}
+
+	var lastActivityAt *time.Time
+
+	if len(commits.Values) > 0 {
+		committedAt := time.UnixMilli(commits.Values[0].CommitterTimestamp)
+		lastActivityAt = &committedAt
+	}
+
+	httpsURL, sshURL := cloneURLs(repo)
+
+	return model.ProjectInfo{
+		DefaultBranch:  defaultBranch.DisplayID,
+		Description:    repo.Description,
+		HTTPSURL:       httpsURL,
+		LastActivityAt: lastActivityAt,
+		OriginalName:   repo.Name,
+		ProjectID:      projectID(repo.Project.Key, repo.Slug),
+		SSHURL:         sshURL,
+		Visibility:     getVisibility(repo.Public),
+	}, nil
+}
+
+func (p ProjectService) SetDefaultBranch(ctx context.Context, owner, projectName, branchName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:SetDefaultBranch")
+
+	body := map[string]string{"id": "refs/heads/" + branchName}
+
+	if err := p.client.do(ctx, http.MethodPut, apiPath+repoPath(owner, Slug(projectName))+"/default-branch", nil, body, nil); err != nil {
+		return fmt.Errorf("failed to set default branch. err: %w", err)
+	}
+
+	return nil
+}
+
+// cloneURLs returns the HTTP and SSH clone URLs of repo.
+// The user name Bitbucket Server adds to the HTTP clone URL of the authenticated user is removed.
+func cloneURLs(repo repository) (string, string) {
+	var httpsURL, sshURL string
+
+	for _, link := range repo.Links.Clone {
+		switch link.Name {
+		case "http":
+			httpsURL = link.Href
+
+			if parsed, err := url.Parse(link.Href); err == nil {
+				parsed.User = nil
+				httpsURL = parsed.String()
+			}
+		case "ssh":
+			sshURL = link.Href
+		}
+	}
+
+	return httpsURL, sshURL
+}
+
+// projectKey returns the key of the project holding the repositories of providerOpt.
+// Personal repositories of a user are in the project ~<user>.
+func projectKey(providerOpt model.ProviderOption) string {
+	if providerOpt.IsGroup() || strings.HasPrefix(providerOpt.Owner, "~") {
+		return providerOpt.Owner
+	}
+
+	return "~" + providerOpt.Owner
+}
+
+// projectID identifies a repository as <project key>/<slug>.
+func projectID(projectKey, slug string) string {
+	return projectKey + "/" + slug
+}
+
+// splitProjectID splits a projectID into project key and slug.
+func splitProjectID(projectIDStr string) (string, string, bool) {
+	key, slug, found := strings.Cut(projectIDStr, "/")
+
+	return key, slug, found && key != "" && slug != ""
+}
+
+func getVisibility(public bool) string {
+	if public {
+		return PUBLIC
+	}
+
+	return PRIVATE
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+var ErrInvalidProjectID = errors.New("invalid project id, expected <project key>/<slug>")
+
+// Branch permission restriction types.
+const (
+	restrictionReadOnly  = "read-only"
+	restrictionNoDeletes = "no-deletes"
+)
+
+type restrictionMatcherType struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type restrictionMatcher struct {
+	ID        string                 `json:"id"`
+	DisplayID string                 `json:"displayId"`
+	Type      restrictionMatcherType `json:"type"`
+	Active    bool                   `json:"active"`
+}
+
+type restriction struct {
+	ID         int                `json:"id,omitempty"`
+	Type       string             `json:"type"`
+	Matcher    restrictionMatcher `json:"matcher"`
+	Users      []string           `json:"users"`
+	Groups     []string           `json:"groups"`
+	AccessKeys []int              `json:"accessKeys"`
+}
+
+type ProtectionService struct {
+	client *Client
+}
+
+func NewProtectionService(client *Client) ProtectionService {
+	return ProtectionService{client: client}
+}
+
+// Protect makes every branch and tag of the repository read-only and prevents the default branch from being deleted.
+func (p ProtectionService) Protect(ctx context.Context, _ string, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:Protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("BitbucketServer:Protect")
+
+	key, slug, ok := splitProjectID(projectIDStr)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidProjectID, projectIDStr)
+	}
+
+	restrictions := []restriction{
+		newRestriction(restrictionReadOnly, restrictionMatcher{
+			ID:        "*",
+			DisplayID: "*",
+			Type:      restrictionMatcherType{ID: "PATTERN", Name: "Pattern"},
+		}),
+		newRestriction(restrictionNoDeletes, restrictionMatcher{
+			ID:        "refs/heads/" + branch,
+			DisplayID: branch,
+			Type:      restrictionMatcherType{ID: "BRANCH", Name: "Branch"},
+		}),
+	}
+
+	for _, restriction := range restrictions {
+		if err := p.client.do(ctx, http.MethodPost, restrictionsPath(key, slug), nil, restriction, nil); err != nil {
+			return fmt.Errorf("failed to add %s branch restriction for %s. err: %w", restriction.Type, restriction.Matcher.DisplayID, err)
+		}
+	}
+
+	return nil
+}
+
+// Unprotect removes every branch restriction of the repository.
+func (p ProtectionService) Unprotect(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:Unprotect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("BitbucketServer:Unprotect")
+
+	key, slug, ok := splitProjectID(projectIDStr)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidProjectID, projectIDStr)
+	}
+
+	restrictions, err := getAll[restriction](ctx, p.client, restrictionsPath(key, slug))
+	if isNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to list branch restrictions. err: %w", err)
+	}
+
+	for _, restriction := range restrictions {
+		path := restrictionsPath(key, slug) + "/" + strconv.Itoa(restriction.ID)
+		if err := p.client.do(ctx, http.MethodDelete, path, nil, nil, nil); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to remove branch restriction %d. err: %w", restriction.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func newRestriction(restrictionType string, matcher restrictionMatcher) restriction {
+	matcher.Active = true
+
+	return restriction{
+		Type:       restrictionType,
+		Matcher:    matcher,
+		Users:      []string{},
+		Groups:     []string{},
+		AccessKeys: []int{},
+	}
+}
+
+func restrictionsPath(projectKey, slug string) string {
+	return branchPermissionPath + repoPath(projectKey, slug) + "/restrictions"
+}
@@ -17,15 +17,22 @@ import (
 	"path/filepath"
 	"time"
 
+	"itiquette/git-provider-sync/internal/configuration"
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/objectstore"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
 	"itiquette/git-provider-sync/internal/provider/archive"
+	"itiquette/git-provider-sync/internal/provider/azuredevops"
+	"itiquette/git-provider-sync/internal/provider/bitbucket"
+	"itiquette/git-provider-sync/internal/provider/bitbucketserver"
 	"itiquette/git-provider-sync/internal/provider/directory"
 	"itiquette/git-provider-sync/internal/provider/gitea"
 	"itiquette/git-provider-sync/internal/provider/github"
 	"itiquette/git-provider-sync/internal/provider/gitlab"
+	"itiquette/git-provider-sync/internal/provider/plaingit"
+	"itiquette/git-provider-sync/internal/provider/s3"
 
 	"github.com/go-git/go-git/v5/plumbing/transport/client"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
@@ -45,6 +52,13 @@ func NewGitProviderClient(ctx context.Context, opt model.GitProviderClientOption
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering NewGitProviderClient")
 
+	authCfg, err := configuration.ResolveCredentials(ctx, opt.AuthCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+
+	opt.AuthCfg = authCfg
+
 	httpClient, err := newHTTPClient(ctx, opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
@@ -67,7 +81,25 @@ func createProvider(ctx context.Context, opt model.GitProviderClientOption, http
 	var err error
 
 	switch opt.ProviderType {
-	case config.GITEA:
+	case config.AZUREDEVOPS:
+		provider, err = azuredevops.NewAzureDevOpsAPIClient(ctx, httpClient, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure DevOps client: %w", err)
+		}
+
+	case config.BITBUCKET:
+		provider, err = bitbucket.NewBitbucketAPIClient(ctx, httpClient, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Bitbucket client: %w", err)
+		}
+
+	case config.BITBUCKETSERVER:
+		provider, err = bitbucketserver.NewBitbucketServerAPIClient(ctx, httpClient, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Bitbucket Server client: %w", err)
+		}
+
+	case config.GITEA, config.FORGEJO, config.GOGS:
 		provider, err = gitea.NewGiteaAPIClient(ctx, httpClient, opt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create Gitea client: %w", err)
@@ -85,10 +117,14 @@ func createProvider(ctx context.Context, opt model.GitProviderClientOption, http
 			return nil, fmt.Errorf("failed to create GitLab client: %w", err)
 		}
 
+	case config.GIT:
+		provider = plaingit.NewPlainGitAPIClient(ctx, opt)
 	case config.ARCHIVE:
-		provider = archive.Client{}
+		provider = archive.NewArchiveClient(ctx, opt)
 	case config.DIRECTORY:
-		provider = directory.Client{}
+		provider = directory.NewDirectoryClient(ctx, opt)
+	case config.S3:
+		provider = s3.NewS3Client(ctx)
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrNonSupportedProvider, opt.ProviderType)
 	}
@@ -98,6 +134,37 @@ func createProvider(ctx context.Context, opt model.GitProviderClientOption, http
 	return provider, nil
 }
 
+// NewObjectStoreClient creates the client of the bucket an s3 mirror writes its archives to.
+// The username of the auth configuration is the access key ID and its token the secret access key,
+// falling back to the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.
+// The proxy, certificates and request timeout of the auth configuration apply.
+func NewObjectStoreClient(ctx context.Context, mirrorCfg config.MirrorConfig) (*objectstore.Client, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering NewObjectStoreClient")
+
+	httpClient, err := newHTTPClient(ctx, model.GitProviderClientOption{AuthCfg: mirrorCfg.Auth})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	accessKeyID := mirrorCfg.Auth.Username
+	if accessKeyID == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+
+	secretAccessKey := mirrorCfg.Auth.Token
+	if secretAccessKey == "" {
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	client, err := objectstore.NewClient(httpClient, mirrorCfg.S3, accessKeyID, secretAccessKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object storage client: %w", err)
+	}
+
+	return client, nil
+}
+
 // newHTTPClient creates a new HTTP client with proper error handling.
 func newHTTPClient(ctx context.Context, opt model.GitProviderClientOption) (*http.Client, error) {
 	logger := log.Logger(ctx)
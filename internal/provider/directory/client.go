@@ -2,16 +2,30 @@
 //
 // SPDX-License-Identifier: EUPL-1.2
 
+// Package directory provides the directory provider. As a mirror target it does nothing on its own,
+// as a source it lists the repositories in a directory mirror, to restore from.
 package directory
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
+	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
-type Client struct{}
+type Client struct {
+	path string
+}
 
 func (Client) CreateProject(_ context.Context, _ model.CreateProjectOption) (string, error) {
 	return "", nil
@@ -33,8 +47,91 @@ func (Client) ProjectExists(_ context.Context, _, _ string) (bool, string, error
 	return false, "", nil
 }
 
-func (Client) GetProjectInfos(_ context.Context, _ model.ProviderOption, _ bool) ([]model.ProjectInfo, error) {
-	return nil, nil
+// GetProjectInfos lists the git repositories in the source path, one per subdirectory.
+// The repository directory is used as clone URL, and the default branch is read from its HEAD.
+func (client Client) GetProjectInfos(ctx context.Context, opt model.ProviderOption, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Directory:GetProjectInfos")
+	logger.Debug().Str("path", client.path).Bool("filtering", filtering).Msg("Directory:GetProjectInfos")
+
+	entries, err := os.ReadDir(client.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", client.path, err)
+	}
+
+	projectinfos := make([]model.ProjectInfo, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		repoPath := filepath.Join(client.path, entry.Name())
+
+		repo, err := git.PlainOpen(repoPath)
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			logger.Debug().Str("dir", repoPath).Msg("Skipping directory without a repository")
+
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to open repository %s: %w", repoPath, err)
+		}
+
+		defaultBranch, lastActivityAt, err := headInfo(repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read HEAD of %s: %w", entry.Name(), err)
+		}
+
+		projectinfos = append(projectinfos, model.ProjectInfo{
+			OriginalName:   entry.Name(),
+			HTTPSURL:       repoPath,
+			SSHURL:         repoPath,
+			DefaultBranch:  defaultBranch,
+			Visibility:     "private",
+			LastActivityAt: lastActivityAt,
+			ProjectID:      repoPath,
+		})
+	}
+
+	if filtering {
+		return targetfilter.FilterIncludedExcludedGen()(ctx, opt, projectinfos) //nolint
+	}
+
+	return projectinfos, nil
+}
+
+// headInfo returns the branch HEAD points at and the time of its commit.
+// An empty repository has a branch but no commit time.
+func headInfo(repo *git.Repository) (string, *time.Time, error) {
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return "", nil, err //nolint:wrapcheck
+	}
+
+	var branch string
+	if head.Type() == plumbing.SymbolicReference {
+		branch = head.Target().Short()
+	}
+
+	resolved, err := repo.Reference(plumbing.HEAD, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return branch, nil, nil
+	}
+
+	if err != nil {
+		return "", nil, err //nolint:wrapcheck
+	}
+
+	commit, err := repo.CommitObject(resolved.Hash())
+	if err != nil {
+		return "", nil, err //nolint:wrapcheck
+	}
+
+	committedAt := commit.Committer.When
+
+	return branch, &committedAt, nil
 }
 
 func (Client) Protect(_ context.Context, _, _, _ string) error {
@@ -44,3 +141,10 @@ func (Client) Protect(_ context.Context, _, _, _ string) error {
 func (Client) Unprotect(_ context.Context, _, _ string) error {
 	return nil
 }
+
+func NewDirectoryClient(ctx context.Context, opt model.GitProviderClientOption) Client {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Directory:NewDirectoryClient")
+
+	return Client{path: opt.Path}
+}
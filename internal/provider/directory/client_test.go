@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package directory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepository creates a repository with one commit made at the given time, with HEAD on the given branch.
+func newRepository(t *testing.T, dir, branch string, committedAt time.Time) {
+	t.Helper()
+
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName(branch)},
+	})
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte(branch), 0o600))
+
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: committedAt},
+	})
+	require.NoError(t, err)
+}
+
+func TestClient_GetProjectInfos(t *testing.T) {
+	mirrorDir := t.TempDir()
+	committedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	newRepository(t, filepath.Join(mirrorDir, "tools"), "trunk", committedAt)
+	newRepository(t, filepath.Join(mirrorDir, "docs"), "main", committedAt)
+
+	_, err := git.PlainInit(filepath.Join(mirrorDir, "empty"), true)
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(mirrorDir, "notarepo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, "notes.txt"), []byte("not a repository"), 0o600))
+
+	tests := []struct {
+		name        string
+		providerOpt model.ProviderOption
+		filtering   bool
+		want        map[string]string
+	}{
+		{
+			name: "all repositories",
+			want: map[string]string{"tools": "trunk", "docs": "main", "empty": "master"},
+		},
+		{
+			name:        "filtered by include",
+			providerOpt: model.ProviderOption{IncludedRepositories: []string{"tools"}},
+			filtering:   true,
+			want:        map[string]string{"tools": "trunk"},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			client := NewDirectoryClient(context.Background(), model.GitProviderClientOption{Path: mirrorDir})
+
+			infos, err := client.GetProjectInfos(context.Background(), tabletest.providerOpt, tabletest.filtering)
+			require.NoError(err)
+
+			got := map[string]string{}
+			for _, info := range infos {
+				require.Equal(filepath.Join(mirrorDir, info.OriginalName), info.HTTPSURL)
+				require.Equal(info.HTTPSURL, info.SSHURL)
+
+				if info.OriginalName == "empty" {
+					require.Nil(info.LastActivityAt)
+				} else {
+					require.True(committedAt.Equal(*info.LastActivityAt))
+				}
+
+				got[info.OriginalName] = info.DefaultBranch
+			}
+
+			require.Equal(tabletest.want, got)
+		})
+	}
+}
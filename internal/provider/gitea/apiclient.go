@@ -21,6 +21,7 @@ type APIClient struct {
 	projectService    *ProjectService
 	protectionService *ProtectionService
 	filterService     *FilterService
+	capabilities      Capabilities
 }
 
 func (api APIClient) CreateProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
@@ -58,8 +59,8 @@ func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
 	return IsValidGiteaRepositoryName(name)
 }
 
-func (APIClient) Name() string {
-	return config.GITEA
+func (api APIClient) Name() string {
+	return api.capabilities.Flavor
 }
 
 func (api APIClient) GetProjectInfos(ctx context.Context, opt model.ProviderOption, filtering bool) ([]model.ProjectInfo, error) {
@@ -134,6 +135,14 @@ func NewGiteaAPIClient(ctx context.Context, httpClient *http.Client, opt model.G
 		defaultBaseURL = opt.DomainWithScheme(opt.AuthCfg.HTTPScheme)
 	}
 
+	capabilities := giteaCapabilities()
+
+	// Forgejo and Gogs do not report a version the SDK can rely on, so probe them ourselves
+	if opt.ProviderType == config.FORGEJO || opt.ProviderType == config.GOGS {
+		capabilities = probeCapabilities(ctx, httpClient, defaultBaseURL, opt.ProviderType, opt.AuthCfg.Token)
+		clientOptions = append(clientOptions, gitea.SetGiteaVersion(capabilities.GiteaVersion))
+	}
+
 	rawClient, err := gitea.NewClient(
 		defaultBaseURL,
 		clientOptions...,
@@ -142,10 +151,15 @@ func NewGiteaAPIClient(ctx context.Context, httpClient *http.Client, opt model.G
 		return APIClient{}, fmt.Errorf("failed to create a new Gitea client: %w", err)
 	}
 
+	logger.Debug().Str("flavor", capabilities.Flavor).Str("version", capabilities.Version).
+		Bool("branchProtection", capabilities.BranchProtection).Bool("pagination", capabilities.Pagination).
+		Bool("repoSettings", capabilities.RepoSettings).Msg("Gitea:capabilities")
+
 	return APIClient{
 		raw:               rawClient,
-		projectService:    NewProjectService(rawClient),
-		protectionService: NewProtectionService(rawClient),
+		projectService:    NewProjectService(rawClient, capabilities),
+		protectionService: NewProtectionService(rawClient, capabilities),
 		filterService:     NewFilter(),
+		capabilities:      capabilities,
 	}, nil
 }
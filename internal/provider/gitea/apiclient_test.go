@@ -0,0 +1,366 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const testToken = "secret-token"
+
+// fakeServer is a minimal in-memory Gitea compatible API, behaving as gitea, forgejo or gogs.
+type fakeServer struct {
+	mu            sync.Mutex
+	flavor        string
+	repos         []string
+	maxLimit      int
+	linkHeader    bool
+	listRequests  int
+	protections   []string
+	edits         int
+	noProtections bool
+}
+
+func newFakeServer(t *testing.T, flavor string, repoCount int) (*fakeServer, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeServer{flavor: flavor, maxLimit: 50, linkHeader: true}
+	for i := range repoCount {
+		fake.repos = append(fake.repos, fmt.Sprintf("repo%03d", i))
+	}
+
+	var server *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/version", fake.version)
+	mux.HandleFunc("GET /api/forgejo/v1/version", fake.forgejoVersion)
+	mux.HandleFunc("GET /api/v1/orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		fake.listRepos(w, r, server.URL)
+	})
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}", fake.getRepo)
+	mux.HandleFunc("PATCH /api/v1/repos/{owner}/{repo}", fake.editRepo)
+	mux.HandleFunc("POST /api/v1/repos/{owner}/{repo}/branch_protections", fake.createProtection)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/branch_protections", fake.listProtections)
+	mux.HandleFunc("DELETE /api/v1/repos/{owner}/{repo}/branch_protections/{name}", fake.deleteProtection)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/tags", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, []any{})
+	})
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+testToken {
+			http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeServer) version(w http.ResponseWriter, _ *http.Request) {
+	switch f.flavor {
+	case config.GOGS:
+		http.NotFound(w, nil)
+	case config.FORGEJO:
+		writeJSON(w, http.StatusOK, map[string]string{"version": "9.0.3+gitea-1.22.0"})
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"version": "1.22.3"})
+	}
+}
+
+func (f *fakeServer) forgejoVersion(w http.ResponseWriter, _ *http.Request) {
+	if f.flavor != config.FORGEJO {
+		http.NotFound(w, nil)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"version": "9.0.3"})
+}
+
+func (f *fakeServer) listRepos(w http.ResponseWriter, r *http.Request, baseURL string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.listRequests++
+
+	repos := f.repos
+
+	// Gogs ignores paging and returns everything
+	if f.flavor != config.GOGS {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page = max(page, 1)
+
+		if limit <= 0 || limit > f.maxLimit {
+			limit = f.maxLimit
+		}
+
+		start := min((page-1)*limit, len(repos))
+		end := min(start+limit, len(repos))
+
+		if f.linkHeader && end < len(repos) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d&limit=%d>; rel="next"`, baseURL, r.URL.Path, page+1, limit))
+		}
+
+		repos = repos[start:end]
+	}
+
+	out := make([]map[string]any, 0, len(repos))
+	for _, name := range repos {
+		out = append(out, repository(r.PathValue("org"), name))
+	}
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (f *fakeServer) getRepo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, repository(r.PathValue("owner"), r.PathValue("repo")))
+}
+
+func (f *fakeServer) editRepo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.edits++
+
+	writeJSON(w, http.StatusOK, repository(r.PathValue("owner"), r.PathValue("repo")))
+}
+
+func (f *fakeServer) createProtection(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.noProtections {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	var opt struct {
+		RuleName string `json:"rule_name"`
+	}
+
+	_ = json.NewDecoder(r.Body).Decode(&opt)
+	f.protections = append(f.protections, opt.RuleName)
+
+	writeJSON(w, http.StatusCreated, map[string]string{"rule_name": opt.RuleName})
+}
+
+func (f *fakeServer) listProtections(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]map[string]string, 0, len(f.protections))
+	for _, name := range f.protections {
+		out = append(out, map[string]string{"rule_name": name})
+	}
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (f *fakeServer) deleteProtection(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, name := range f.protections {
+		if name == r.PathValue("name") {
+			f.protections = append(f.protections[:i], f.protections[i+1:]...)
+
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func repository(owner, name string) map[string]any {
+	return map[string]any{
+		"name":           name,
+		"full_name":      owner + "/" + name,
+		"clone_url":      "https://forge.example.com/" + owner + "/" + name + ".git",
+		"default_branch": "main",
+		"owner":          map[string]any{"login": owner, "username": owner, "visibility": "public"},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestAPIClient(t *testing.T, server *httptest.Server, providerType string) APIClient {
+	t.Helper()
+
+	api, err := NewGiteaAPIClient(context.Background(), server.Client(), model.GitProviderClientOption{
+		ProviderType: providerType,
+		Domain:       strings.TrimPrefix(server.URL, "http://"),
+		AuthCfg:      config.AuthConfig{HTTPScheme: "http", Token: testToken},
+	})
+	require.NoError(t, err)
+
+	return api
+}
+
+func TestNewGiteaAPIClient_Capabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		flavor   string
+		expected Capabilities
+	}{
+		{
+			name:     "gitea",
+			flavor:   config.GITEA,
+			expected: Capabilities{Flavor: config.GITEA, BranchProtection: true, Pagination: true, RepoSettings: true},
+		},
+		{
+			name:   "forgejo reports its own and the gitea api version",
+			flavor: config.FORGEJO,
+			expected: Capabilities{
+				Flavor: config.FORGEJO, Version: "9.0.3", GiteaVersion: "1.22.0",
+				BranchProtection: true, Pagination: true, RepoSettings: true,
+			},
+		},
+		{
+			name:     "gogs without version endpoint",
+			flavor:   config.GOGS,
+			expected: Capabilities{Flavor: config.GOGS},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			_, server := newFakeServer(t, tabletest.flavor, 0)
+
+			api := newTestAPIClient(t, server, tabletest.flavor)
+
+			require.Equal(t, tabletest.expected, api.capabilities)
+			require.Equal(t, tabletest.flavor, api.Name())
+		})
+	}
+}
+
+func TestAPIClient_GetProjectInfos(t *testing.T) {
+	tests := []struct {
+		name             string
+		flavor           string
+		repoCount        int
+		maxLimit         int
+		linkHeader       bool
+		wantListRequests int
+	}{
+		{name: "gitea pages with link header", flavor: config.GITEA, repoCount: 120, maxLimit: 50, linkHeader: true, wantListRequests: 3},
+		{name: "forgejo caps page size below ours", flavor: config.FORGEJO, repoCount: 45, maxLimit: 20, linkHeader: true, wantListRequests: 3},
+		{name: "pages without link header", flavor: config.GITEA, repoCount: 100, maxLimit: 50, wantListRequests: 3},
+		{name: "gogs returns everything at once", flavor: config.GOGS, repoCount: 120, maxLimit: 50, wantListRequests: 1},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake, server := newFakeServer(t, tabletest.flavor, tabletest.repoCount)
+			fake.maxLimit = tabletest.maxLimit
+			fake.linkHeader = tabletest.linkHeader
+
+			api := newTestAPIClient(t, server, tabletest.flavor)
+
+			infos, err := api.GetProjectInfos(context.Background(), model.ProviderOption{Owner: "org", OwnerType: config.GROUP}, false)
+			require.NoError(err)
+			require.Len(infos, tabletest.repoCount)
+			require.Equal("org/repo000", infos[0].ProjectID)
+			require.Equal(tabletest.wantListRequests, fake.listRequests)
+		})
+	}
+}
+
+func TestAPIClient_ProtectUnprotect(t *testing.T) {
+	tests := []struct {
+		name            string
+		flavor          string
+		noProtections   bool
+		wantProtections []string
+	}{
+		{name: "gitea", flavor: config.GITEA, wantProtections: []string{"*", "main"}},
+		{name: "forgejo", flavor: config.FORGEJO, wantProtections: []string{"*", "main"}},
+		{name: "forgejo without protection endpoint", flavor: config.FORGEJO, noProtections: true},
+		{name: "gogs", flavor: config.GOGS},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			fake, server := newFakeServer(t, tabletest.flavor, 1)
+			fake.noProtections = tabletest.noProtections
+
+			api := newTestAPIClient(t, server, tabletest.flavor)
+
+			require.NoError(api.Protect(context.Background(), "org", "main", "org/repo000"))
+			require.Equal(tabletest.wantProtections, fake.protections)
+
+			require.NoError(api.Unprotect(context.Background(), "main", "org/repo000"))
+			require.Empty(fake.protections)
+		})
+	}
+}
+
+func TestAPIClient_SetDefaultBranch(t *testing.T) {
+	tests := []struct {
+		name      string
+		flavor    string
+		wantEdits int
+	}{
+		{name: "gitea", flavor: config.GITEA, wantEdits: 1},
+		{name: "gogs skips", flavor: config.GOGS, wantEdits: 0},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			fake, server := newFakeServer(t, tabletest.flavor, 1)
+
+			api := newTestAPIClient(t, server, tabletest.flavor)
+
+			require.NoError(t, api.SetDefaultBranch(context.Background(), "org", "repo000", "develop"))
+			require.Equal(t, tabletest.wantEdits, fake.edits)
+		})
+	}
+}
+
+func TestCompatVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		reported string
+		expected string
+	}{
+		{name: "forgejo with gitea suffix", reported: "9.0.3+gitea-1.22.0", expected: "1.22.0"},
+		{name: "older forgejo", reported: "1.21.11-1", expected: "1.21.11-1"},
+		{name: "gitea", reported: "1.22.3", expected: "1.22.3"},
+		{name: "empty", reported: "", expected: ""},
+		{name: "garbage", reported: "development", expected: ""},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.expected, compatVersion(tabletest.reported))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	giteaVersionPath   = "/api/v1/version"
+	forgejoVersionPath = "/api/forgejo/v1/version"
+
+	// Forgejo reports versions like 7.0.0+gitea-1.22.0, where the suffix is the Gitea API level.
+	giteaCompatMarker = "+gitea-"
+)
+
+// Capabilities describes the API features a Gitea compatible server offers.
+type Capabilities struct {
+	// Flavor is the provider type: gitea, forgejo or gogs.
+	Flavor string
+
+	// Version is the server version as reported by the server, empty when unknown.
+	Version string
+
+	// GiteaVersion is the Gitea API level the server is compatible with, empty when unknown.
+	GiteaVersion string
+
+	// BranchProtection tells if the branch protection endpoints exist.
+	BranchProtection bool
+
+	// Pagination tells if list endpoints honour page and limit.
+	Pagination bool
+
+	// RepoSettings tells if repository settings, such as default branch, can be edited.
+	RepoSettings bool
+}
+
+// giteaCapabilities returns the full feature set of an upstream Gitea server.
+func giteaCapabilities() Capabilities {
+	return Capabilities{
+		Flavor:           config.GITEA,
+		BranchProtection: true,
+		Pagination:       true,
+		RepoSettings:     true,
+	}
+}
+
+// probeCapabilities asks the server which API it speaks.
+// A failing probe is not an error, the server is then assumed to offer the
+// baseline feature set of its flavor.
+func probeCapabilities(ctx context.Context, httpClient *http.Client, baseURL, flavor, token string) Capabilities {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:probeCapabilities")
+
+	switch flavor {
+	case config.GOGS:
+		// Gogs has neither branch protection, paging nor repository edit endpoints
+		caps := Capabilities{Flavor: config.GOGS}
+
+		gogsVersion, err := fetchVersion(ctx, httpClient, baseURL+giteaVersionPath, token)
+		if err != nil {
+			logger.Debug().Err(err).Msg("Gitea:probeCapabilities: gogs version not available")
+		}
+
+		caps.Version = gogsVersion
+
+		return caps
+	case config.FORGEJO:
+		caps := giteaCapabilities()
+		caps.Flavor = config.FORGEJO
+
+		forgejoVersion, err := fetchVersion(ctx, httpClient, baseURL+forgejoVersionPath, token)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Gitea:probeCapabilities: forgejo version not available, assuming a gitea compatible server")
+		}
+
+		caps.Version = forgejoVersion
+
+		giteaVersion, err := fetchVersion(ctx, httpClient, baseURL+giteaVersionPath, token)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Gitea:probeCapabilities: gitea api version not available")
+		}
+
+		caps.GiteaVersion = compatVersion(giteaVersion)
+		if caps.Version == "" {
+			caps.Version = giteaVersion
+		}
+
+		return caps
+	default:
+		return giteaCapabilities()
+	}
+}
+
+// compatVersion returns the Gitea API level part of a reported server version,
+// or an empty string when it is not a valid version.
+func compatVersion(reported string) string {
+	if _, compat, found := strings.Cut(reported, giteaCompatMarker); found {
+		reported = compat
+	}
+
+	if _, err := version.NewVersion(reported); err != nil {
+		return ""
+	}
+
+	return reported
+}
+
+func fetchVersion(ctx context.Context, httpClient *http.Client, url, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create version request: %w", err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request version: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to request version: %s: status %d", url, resp.StatusCode)
+	}
+
+	var body struct {
+		Version string `json:"version"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode version: %w", err)
+	}
+
+	return body.Version, nil
+}
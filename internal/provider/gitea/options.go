@@ -35,6 +35,12 @@ func (p ProjectService) ApplyDisabledSettings(ctx context.Context, owner, projec
 	logger.Trace().Msg("Entering gitea:ApplyDisabledSettings")
 	logger.Debug().Str("owner", owner).Str("repo", projectName).Msg("Entering gitea:ApplyDisabledSettings")
 
+	if !p.capabilities.RepoSettings {
+		logger.Warn().Str("provider", p.capabilities.Flavor).Msg("disabling repository features is not supported, skipping")
+
+		return nil
+	}
+
 	// These settings can only be applied after repository creation
 	editOpts := gitea.EditRepoOption{
 		HasIssues:       new(bool),
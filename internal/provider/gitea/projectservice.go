@@ -14,14 +14,17 @@ import (
 	"code.gitea.io/sdk/gitea"
 )
 
+// pageSize is the number of repositories requested per page when listing.
+const pageSize = 50
+
 type ProjectService struct {
 	client            *gitea.Client
-	optBuilder        *ProjectOptionsBuilder
 	protectionService *ProtectionService
+	capabilities      Capabilities
 }
 
-func NewProjectService(client *gitea.Client) *ProjectService {
-	return &ProjectService{client: client, optBuilder: NewProjectOptionsBuilder(), protectionService: NewProtectionService(client)}
+func NewProjectService(client *gitea.Client, capabilities Capabilities) *ProjectService {
+	return &ProjectService{client: client, protectionService: NewProtectionService(client, capabilities), capabilities: capabilities}
 }
 
 func (p ProjectService) createProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
@@ -29,16 +32,17 @@ func (p ProjectService) createProject(ctx context.Context, opt model.CreateProje
 	logger.Trace().Msg("Entering gitea:creatNeProject")
 	opt.DebugLog(logger).Msg("gitea:CreateOption")
 
-	p.optBuilder.BasicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch)
+	optBuilder := NewProjectOptionsBuilder()
+	optBuilder.BasicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch)
 
 	var createdRepo *gitea.Repository
 
 	var err error
 
 	if opt.IsGroup {
-		createdRepo, _, err = p.client.CreateOrgRepo(opt.Owner, *p.optBuilder.opts)
+		createdRepo, _, err = p.client.CreateOrgRepo(opt.Owner, *optBuilder.opts)
 	} else {
-		createdRepo, _, err = p.client.CreateRepo(*p.optBuilder.opts)
+		createdRepo, _, err = p.client.CreateRepo(*optBuilder.opts)
 	}
 
 	if err != nil {
@@ -82,30 +86,7 @@ func (p ProjectService) getProjectInfos(ctx context.Context, providerOpt model.P
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering gitea:getProjectInfos")
 
-	var (
-		repositories []*gitea.Repository
-		err          error
-	)
-
-	if providerOpt.IsGroup() {
-		opt := gitea.ListOrgReposOptions{
-			ListOptions: gitea.ListOptions{
-				Page:     -1, // Set to -1 to get all items
-				PageSize: -1,
-			},
-		}
-		repositories, _, err = p.client.ListOrgRepos(providerOpt.Owner, opt)
-	} else {
-		opt := gitea.ListReposOptions{
-			ListOptions: gitea.ListOptions{
-				Page:     -1,
-				PageSize: -1,
-			},
-		}
-
-		repositories, _, err = p.client.ListUserRepos(providerOpt.User, opt)
-	}
-
+	repositories, err := p.listRepositories(providerOpt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
 	}
@@ -126,6 +107,52 @@ func (p ProjectService) getProjectInfos(ctx context.Context, providerOpt model.P
 	return projectinfos, nil
 }
 
+// listRepositories fetches all repositories of the owner, page by page when the server supports it.
+func (p ProjectService) listRepositories(providerOpt model.ProviderOption) ([]*gitea.Repository, error) {
+	listOpt := gitea.ListOptions{Page: 1, PageSize: pageSize}
+
+	// Servers without paging return everything at once and ignore page, asking again would loop forever
+	if !p.capabilities.Pagination {
+		listOpt = gitea.ListOptions{Page: -1, PageSize: -1}
+	}
+
+	var repositories []*gitea.Repository
+
+	for {
+		var (
+			page []*gitea.Repository
+			resp *gitea.Response
+			err  error
+		)
+
+		if providerOpt.IsGroup() {
+			page, resp, err = p.client.ListOrgRepos(providerOpt.Owner, gitea.ListOrgReposOptions{ListOptions: listOpt})
+		} else {
+			page, resp, err = p.client.ListUserRepos(providerOpt.User, gitea.ListReposOptions{ListOptions: listOpt})
+		}
+
+		if err != nil {
+			return nil, err //nolint
+		}
+
+		repositories = append(repositories, page...)
+
+		if !p.capabilities.Pagination || len(page) == 0 {
+			return repositories, nil
+		}
+
+		switch {
+		case resp != nil && resp.NextPage > 0:
+			listOpt.Page = resp.NextPage
+		case len(page) >= listOpt.PageSize:
+			// No Link header, a full page means there may be more
+			listOpt.Page++
+		default:
+			return repositories, nil
+		}
+	}
+}
+
 func (p ProjectService) Exists(ctx context.Context, owner, repo string) (bool, string, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering gitea:Exists")
@@ -147,6 +174,12 @@ func (p ProjectService) setDefaultBranch(ctx context.Context, owner string, proj
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering gitea:setDefaultBranch")
 
+	if !p.capabilities.RepoSettings {
+		logger.Warn().Str("provider", p.capabilities.Flavor).Msg("setting default branch is not supported, skipping")
+
+		return nil
+	}
+
 	editOptions := gitea.EditRepoOption{
 		DefaultBranch: &branch,
 	}
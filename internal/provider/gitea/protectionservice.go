@@ -7,17 +7,19 @@ import (
 	"context"
 	"fmt"
 	"itiquette/git-provider-sync/internal/log"
+	"net/http"
 	"strings"
 
 	"code.gitea.io/sdk/gitea"
 )
 
 type ProtectionService struct {
-	client *gitea.Client
+	client       *gitea.Client
+	capabilities Capabilities
 }
 
-func NewProtectionService(client *gitea.Client) *ProtectionService {
-	return &ProtectionService{client: client}
+func NewProtectionService(client *gitea.Client, capabilities Capabilities) *ProtectionService {
+	return &ProtectionService{client: client, capabilities: capabilities}
 }
 
 func (p ProtectionService) protect(ctx context.Context, branch string, projectIDStr string) error {
@@ -25,6 +27,12 @@ func (p ProtectionService) protect(ctx context.Context, branch string, projectID
 	logger.Trace().Msg("Entering Gitea:protect")
 	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("protect")
 
+	if !p.capabilities.BranchProtection {
+		logger.Warn().Str("provider", p.capabilities.Flavor).Msg("branch protection is not supported, skipping")
+
+		return nil
+	}
+
 	// projectIDStr is expected to be in format "owner/repo"
 	owner, repoName := splitProjectPath(projectIDStr)
 	if owner == "" || repoName == "" {
@@ -55,6 +63,12 @@ func (p ProtectionService) unprotect(ctx context.Context, branch string, project
 	logger.Trace().Msg("Entering Gitea:unprotect")
 	logger.Debug().Str("projectID", projectIDStr).Str("branch", branch).Msg("unprotect")
 
+	if !p.capabilities.BranchProtection {
+		logger.Debug().Str("provider", p.capabilities.Flavor).Msg("branch protection is not supported, nothing to unprotect")
+
+		return nil
+	}
+
 	owner, repoName := splitProjectPath(projectIDStr)
 	if owner == "" || repoName == "" {
 		return fmt.Errorf("invalid project path: %s", projectIDStr)
@@ -112,6 +126,7 @@ func (p ProtectionService) enableBranchProtection(ctx context.Context, branch, o
 	// Create protection for all branches
 	allBranchesOpts := gitea.CreateBranchProtectionOption{
 		BranchName:              "*",
+		RuleName:                "*",
 		EnablePush:              false,
 		EnablePushWhitelist:     true,
 		PushWhitelistUsernames:  []string{},
@@ -127,14 +142,21 @@ func (p ProtectionService) enableBranchProtection(ctx context.Context, branch, o
 		RequiredApprovals: 1,
 	}
 
-	_, _, err := p.client.CreateBranchProtection(owner, repo, allBranchesOpts)
+	_, resp, err := p.client.CreateBranchProtection(owner, repo, allBranchesOpts)
 	if err != nil {
+		if isUnsupported(resp) {
+			logger.Warn().Str("provider", p.capabilities.Flavor).Err(err).Msg("branch protection endpoint not available, skipping")
+
+			return nil
+		}
+
 		return fmt.Errorf("failed to protect all branches: %w", err)
 	}
 
 	// Create specific protection for the default branch
 	defaultBranchOpts := gitea.CreateBranchProtectionOption{
 		BranchName:              branch,
+		RuleName:                branch,
 		EnablePush:              false,
 		EnablePushWhitelist:     true,
 		PushWhitelistUsernames:  []string{},
@@ -202,6 +224,11 @@ func (p ProtectionService) disableTagProtection(ctx context.Context, owner, repo
 	return nil
 }
 
+// isUnsupported tells if a response means the server lacks the endpoint rather than failed a request.
+func isUnsupported(resp *gitea.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed)
+}
+
 func splitProjectPath(path string) (string, string) {
 	parts := strings.Split(path, "/")
 	if len(parts) != 2 {
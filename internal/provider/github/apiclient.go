@@ -12,6 +12,7 @@ import (
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/githubapp"
 
 	"github.com/google/go-github/v71/github"
 )
@@ -156,6 +157,15 @@ func NewGitHubAPIClient(ctx context.Context, httpClient *http.Client, opt model.
 		rawClient.UploadURL, _ = url.Parse(uploadBaseURL)
 	}
 
+	if opt.AuthCfg.IsGitHubApp() {
+		appClient, err := newAppClient(httpClient, rawClient, opt.AuthCfg)
+		if err != nil {
+			return APIClient{}, err
+		}
+
+		rawClient = appClient
+	}
+
 	// TODO: secondary rate limiting check
 
 	return APIClient{
@@ -165,3 +175,21 @@ func NewGitHubAPIClient(ctx context.Context, httpClient *http.Client, opt model.
 		filterService:     NewFilter(),
 	}, nil
 }
+
+// newAppClient returns a copy of rawClient authenticating as a GitHub App installation,
+// with installation tokens refreshed before they expire.
+func newAppClient(httpClient *http.Client, rawClient *github.Client, authCfg config.AuthConfig) (*github.Client, error) {
+	source, err := githubapp.Register(httpClient, rawClient.BaseURL, authCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub App token source: %w", err)
+	}
+
+	appHTTPClient := *httpClient
+	appHTTPClient.Transport = githubapp.NewTransport(source, httpClient.Transport)
+
+	appClient := github.NewClient(&appHTTPClient)
+	appClient.BaseURL = rawClient.BaseURL
+	appClient.UploadURL = rawClient.UploadURL
+
+	return appClient, nil
+}
@@ -17,12 +17,11 @@ import (
 
 type ProjectService struct {
 	client            *github.Client
-	optBuilder        *ProjectOptionsBuilder
 	protectionService *ProtectionService
 }
 
 func NewProjectService(client *github.Client) *ProjectService {
-	return &ProjectService{client: client, optBuilder: NewProjectOptionsBuilder(), protectionService: NewProtectionService(client)}
+	return &ProjectService{client: client, protectionService: NewProtectionService(client)}
 }
 
 func (p ProjectService) createProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
@@ -30,10 +29,11 @@ func (p ProjectService) createProject(ctx context.Context, opt model.CreateProje
 	logger.Trace().Msg("Entering GitHub:createProject")
 	opt.DebugLog(logger).Msg("GitHub:CreateOption")
 
-	p.optBuilder.basicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch)
+	optBuilder := NewProjectOptionsBuilder()
+	optBuilder.basicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch)
 
 	if opt.Disabled {
-		p.optBuilder.disableFeatures()
+		optBuilder.disableFeatures()
 	}
 
 	groupName := ""
@@ -41,7 +41,7 @@ func (p ProjectService) createProject(ctx context.Context, opt model.CreateProje
 		groupName = opt.Owner
 	}
 
-	createdRepo, _, err := p.client.Repositories.Create(ctx, groupName, p.optBuilder.opts)
+	createdRepo, _, err := p.client.Repositories.Create(ctx, groupName, optBuilder.opts)
 	if err != nil {
 		return "", fmt.Errorf("create: failed to create project. name: %s, err: %w", opt.RepositoryName, err)
 	}
@@ -0,0 +1,14 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package githubapp
+
+import "errors"
+
+var (
+	ErrInstallationToken = errors.New("failed to create GitHub App installation token")
+	ErrNoTokenSource     = errors.New("no GitHub App client created for installation")
+	ErrPrivateKey        = errors.New("invalid GitHub App private key")
+	ErrSignJWT           = errors.New("failed to sign GitHub App JWT")
+)
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package githubapp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+type installation struct {
+	appID          int64
+	installationID int64
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[installation]*TokenSource{}
+)
+
+// Register returns the token source of the app installation of the auth configuration,
+// creating it on first use. Each installation has a single token source per run,
+// shared by the API clients and the git transports.
+func Register(httpClient *http.Client, apiURL *url.URL, authCfg config.AuthConfig) (*TokenSource, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	key := installation{appID: authCfg.GitHubAppID, installationID: authCfg.GitHubAppInstallationID}
+	if source, ok := registry[key]; ok {
+		return source, nil
+	}
+
+	source, err := NewTokenSource(httpClient, apiURL, authCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	registry[key] = source
+
+	return source, nil
+}
+
+// ResolveAuth returns the auth configuration to run git with. For a GitHub App installation
+// the token is a current installation token, used with the x-access-token user name,
+// other configurations are returned as they are.
+func ResolveAuth(ctx context.Context, authCfg config.AuthConfig) (config.AuthConfig, error) {
+	if !authCfg.IsGitHubApp() {
+		return authCfg, nil
+	}
+
+	registryMu.Lock()
+	source, ok := registry[installation{appID: authCfg.GitHubAppID, installationID: authCfg.GitHubAppInstallationID}]
+	registryMu.Unlock()
+
+	if !ok {
+		return authCfg, fmt.Errorf("%w: app %d installation %d", ErrNoTokenSource, authCfg.GitHubAppID, authCfg.GitHubAppInstallationID)
+	}
+
+	token, err := source.Token(ctx)
+	if err != nil {
+		return authCfg, err
+	}
+
+	authCfg.Username = GitUsername
+	authCfg.Token = token
+
+	return authCfg, nil
+}
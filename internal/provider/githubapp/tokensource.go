@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package githubapp authenticates as a GitHub App installation.
+// It signs app JWTs, exchanges them for installation access tokens and refreshes the tokens
+// before they expire, for the GitHub API client and the git transports alike.
+package githubapp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/google/go-github/v71/github"
+)
+
+const (
+	// GitUsername is the user name git authenticates with installation tokens over HTTPS.
+	GitUsername = "x-access-token"
+
+	// jwtLifetime stays below the ten minutes GitHub accepts, clockSkew allows for a clock running ahead of GitHub's.
+	jwtLifetime = 9 * time.Minute
+	clockSkew   = time.Minute
+
+	// refreshMargin is how long before expiry a token is replaced, so a clone or push started
+	// with it does not outlive it.
+	refreshMargin = 10 * time.Minute
+)
+
+// TokenSource mints installation access tokens of a GitHub App installation,
+// and caches each until it is about to expire. It is safe for concurrent use.
+type TokenSource struct {
+	client         *github.Client
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewTokenSource creates a token source for the app installation of the auth configuration,
+// requesting tokens from the GitHub API at apiURL with httpClient.
+func NewTokenSource(httpClient *http.Client, apiURL *url.URL, authCfg config.AuthConfig) (*TokenSource, error) {
+	pemData, err := os.ReadFile(authCfg.GitHubAppPrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPrivateKey, err)
+	}
+
+	key, err := parsePrivateKey(pemData)
+	if err != nil {
+		return nil, err
+	}
+
+	client := github.NewClient(httpClient)
+	client.BaseURL = apiURL
+
+	return &TokenSource{
+		client:         client,
+		appID:          authCfg.GitHubAppID,
+		installationID: authCfg.GitHubAppInstallationID,
+		key:            key,
+		now:            time.Now,
+	}, nil
+}
+
+// Token returns an installation access token valid for at least the refresh margin,
+// minting a new one when the cached token is missing or about to expire.
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(refreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	logger := log.Logger(ctx)
+	logger.Debug().Int64("appID", s.appID).Int64("installationID", s.installationID).Msg("Minting GitHub App installation token")
+
+	jwt, err := s.jwt()
+	if err != nil {
+		return "", err
+	}
+
+	installationToken, _, err := s.client.WithAuthToken(jwt).Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: installation %d: %w", ErrInstallationToken, s.installationID, err)
+	}
+
+	s.token = installationToken.GetToken()
+	s.expiresAt = installationToken.GetExpiresAt().Time
+
+	return s.token, nil
+}
+
+// jwt returns a JWT identifying the app, signed with RS256 by its private key.
+func (s *TokenSource) jwt() (string, error) {
+	now := s.now()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSignJWT, err)
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-clockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSignJWT, err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSignJWT, err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses the PEM encoded RSA key GitHub generates for an app, PKCS#1 or PKCS#8.
+func parsePrivateKey(pemData []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data found", ErrPrivateKey)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPrivateKey, err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA key", ErrPrivateKey)
+	}
+
+	return key, nil
+}
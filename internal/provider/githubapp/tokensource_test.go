@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package githubapp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub issues installation tokens valid for an hour, numbered by request,
+// to requests carrying a JWT signed by the app key.
+func fakeGitHub(t *testing.T, key *rsa.PrivateKey, minted *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		jwt, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || verifyJWT(key, jwt) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		count := minted.Add(1)
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"token":      fmt.Sprintf("ghs_token%d", count),
+			"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func verifyJWT(key *rsa.PrivateKey, jwt string) error {
+	separator := strings.LastIndex(jwt, ".")
+	signingInput, signature := jwt[:separator], jwt[separator+1:]
+
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(signingInput))
+
+	return rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], decoded)
+}
+
+func writeAppKey(t *testing.T, key any) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "app.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	return path
+}
+
+func newTestSource(t *testing.T) (*TokenSource, *atomic.Int32) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	minted := &atomic.Int32{}
+	server := fakeGitHub(t, key, minted)
+
+	apiURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+
+	source, err := NewTokenSource(server.Client(), apiURL, config.AuthConfig{
+		GitHubAppID:             7,
+		GitHubAppInstallationID: 42,
+		GitHubAppPrivateKeyPath: writeAppKey(t, key),
+	})
+	require.NoError(t, err)
+
+	return source, minted
+}
+
+func TestTokenSource_Token(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	source, minted := newTestSource(t)
+
+	token, err := source.Token(ctx)
+	require.NoError(err)
+	require.Equal("ghs_token1", token)
+
+	// The token is reused while it is valid past the refresh margin
+	token, err = source.Token(ctx)
+	require.NoError(err)
+	require.Equal("ghs_token1", token)
+	require.Equal(int32(1), minted.Load())
+
+	// and replaced when it is about to expire
+	source.now = func() time.Time { return time.Now().Add(time.Hour - refreshMargin + time.Minute) }
+
+	token, err = source.Token(ctx)
+	require.NoError(err)
+	require.Equal("ghs_token2", token)
+}
+
+func TestTokenSource_JWT(t *testing.T) {
+	source, _ := newTestSource(t)
+
+	jwt, err := source.jwt()
+	require.NoError(t, err)
+	require.NoError(t, verifyJWT(source.key, jwt))
+
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	require.Equal(t, "7", claims.Issuer)
+	require.LessOrEqual(t, claims.ExpiresAt-claims.IssuedAt, int64((10 * time.Minute).Seconds()))
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		pemData []byte
+		wantErr bool
+	}{
+		{name: "PKCS#1", pemData: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})},
+		{name: "not PEM", pemData: []byte("not a key"), wantErr: true},
+		{name: "not RSA", pemData: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER}), wantErr: true},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			key, err := parsePrivateKey(tabletest.pemData)
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, ErrPrivateKey)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, rsaKey.Equal(key))
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	source, _ := newTestSource(t)
+
+	var authorization string
+
+	api := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(api.Close)
+
+	client := &http.Client{Transport: NewTransport(source, nil)}
+
+	resp, err := client.Get(api.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "Bearer ghs_token1", authorization)
+}
+
+func TestResolveAuth(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	tokenAuth := config.AuthConfig{Token: "pat", Username: "user"}
+
+	resolved, err := ResolveAuth(ctx, tokenAuth)
+	require.NoError(err)
+	require.Equal(tokenAuth, resolved)
+
+	appAuth := config.AuthConfig{GitHubAppID: 7, GitHubAppInstallationID: 4242}
+
+	_, err = ResolveAuth(ctx, appAuth)
+	require.ErrorIs(err, ErrNoTokenSource)
+
+	source, _ := newTestSource(t)
+
+	registryMu.Lock()
+	registry[installation{appID: 7, installationID: 4242}] = source
+	registryMu.Unlock()
+
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, installation{appID: 7, installationID: 4242})
+		registryMu.Unlock()
+	})
+
+	resolved, err = ResolveAuth(ctx, appAuth)
+	require.NoError(err)
+	require.Equal(GitUsername, resolved.Username)
+	require.Equal("ghs_token1", resolved.Token)
+}
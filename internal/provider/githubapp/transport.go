@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package githubapp
+
+import "net/http"
+
+// Transport authenticates requests to the GitHub API with the installation token of a token source.
+type Transport struct {
+	source *TokenSource
+	base   http.RoundTripper
+}
+
+func NewTransport(source *TokenSource, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{source: source, base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(authReq) //nolint:wrapcheck
+}
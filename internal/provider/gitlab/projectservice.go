@@ -21,12 +21,11 @@ import (
 
 type ProjectService struct {
 	client            *gitlab.Client
-	optBuilder        *ProjectOptionsBuilder
 	protectionService interfaces.ProtectionServicer
 }
 
 func NewProjectService(client *gitlab.Client) ProjectService {
-	return ProjectService{client: client, optBuilder: NewProjectOptionsBuilder(), protectionService: NewProtectionService(client)}
+	return ProjectService{client: client, protectionService: NewProtectionService(client)}
 }
 
 func (p ProjectService) CreateProject(ctx context.Context, opt model.CreateProjectOption) (string, error) {
@@ -38,13 +37,14 @@ func (p ProjectService) CreateProject(ctx context.Context, opt model.CreateProje
 		return "", fmt.Errorf("failed to get namespaceID. err: %w", err)
 	}
 
-	p.optBuilder.WithBasicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch, namespaceID)
+	optBuilder := NewProjectOptionsBuilder()
+	optBuilder.WithBasicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch, namespaceID)
 
 	if opt.Disabled {
-		p.optBuilder.WithDisabledFeatures()
+		optBuilder.WithDisabledFeatures()
 	}
 
-	createdRepo, _, err := p.client.Projects.CreateProject(p.optBuilder.opts)
+	createdRepo, _, err := p.client.Projects.CreateProject(optBuilder.opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to create project. name: %s, err: %w", opt.RepositoryName, err)
 	}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/cache"
+	"itiquette/git-provider-sync/internal/mirror/lfs"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/githubapp"
+)
+
+// mirrorLFS fetches the LFS objects of a repository from the source LFS server into the local store,
+// then uploads them to the target LFS server, or hands the store to a directory or archive target.
+func mirrorLFS(ctx context.Context, syncCfg config.SyncConfig, mirrorCfg config.MirrorConfig, repository interfaces.GitRepository, pushOption *model.PushOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering mirrorLFS")
+
+	pointers, err := lfs.ScanPointers(ctx, repository.GoGitRepository())
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if len(pointers) == 0 {
+		logger.Debug().Msg("No LFS pointers in repository")
+
+		return nil
+	}
+
+	store, err := lfsStore(ctx, syncCfg, repository)
+	if err != nil {
+		return err
+	}
+
+	source, err := newLFSClient(ctx, repository.ProjectInfo().HTTPSURL, syncCfg.Auth)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+
+	if err := source.Fetch(ctx, pointers, store); err != nil {
+		return fmt.Errorf("fetch from source: %w", err)
+	}
+
+	if isStorageTarget(mirrorCfg.ProviderType) {
+		pushOption.LFSObjectsDir = store.Dir()
+
+		return nil
+	}
+
+	target, err := newLFSClient(ctx, toGitURL(ctx, mirrorCfg, repository), mirrorCfg.Auth)
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	if err := target.Push(ctx, pointers, store); err != nil {
+		return fmt.Errorf("push to target: %w", err)
+	}
+
+	return nil
+}
+
+// lfsStore returns the local store of the repository's LFS objects. With a cache directory the objects
+// are kept inside the cache entry, so later runs only fetch new objects, else they live in the run's
+// temporary directory. Either way they are fetched once per run, however many mirrors use them.
+func lfsStore(ctx context.Context, syncCfg config.SyncConfig, repository interfaces.GitRepository) (lfs.Store, error) {
+	name := repository.ProjectInfo().Name(ctx)
+
+	if syncCfg.CacheDir != "" {
+		return lfs.RepositoryStore(cache.EntryPath(syncCfg.CacheDir, syncCfg.GetDomain(), syncCfg.Owner, name)), nil
+	}
+
+	tmpDir, err := model.GetTmpDirPath(ctx)
+	if err != nil {
+		return lfs.Store{}, fmt.Errorf("lfs store: %w", err)
+	}
+
+	return lfs.NewStore(filepath.Join(tmpDir, ".lfs", name)), nil
+}
+
+// newLFSClient creates an LFS client for the repository at cloneURL,
+// authenticating like git over HTTPS does with the token of the auth configuration.
+func newLFSClient(ctx context.Context, cloneURL string, authCfg config.AuthConfig) (*lfs.Client, error) {
+	endpoint, err := lfs.Endpoint(cloneURL)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	authCfg, err = githubapp.ResolveAuth(ctx, authCfg)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	httpClient, err := newHTTPClient(ctx, model.GitProviderClientOption{AuthCfg: authCfg})
+	if err != nil {
+		return nil, err
+	}
+
+	// Large objects take longer than an API request, transfers are bounded by the context instead
+	httpClient.Timeout = 0
+
+	username := "any"
+	if authCfg.Username != "" {
+		username = authCfg.Username
+	}
+
+	log.Logger(ctx).Debug().Str("endpoint", endpoint).Msg("newLFSClient")
+
+	return lfs.NewClient(httpClient, endpoint, username, authCfg.Token), nil
+}
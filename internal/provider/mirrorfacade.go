@@ -8,14 +8,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/mirror/objectstore"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/bitbucket"
+	"itiquette/git-provider-sync/internal/provider/bitbucketserver"
+	"itiquette/git-provider-sync/internal/provider/githubapp"
+	"itiquette/git-provider-sync/internal/provider/plaingit"
 	"itiquette/git-provider-sync/internal/provider/stringconvert"
+
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 // Error variables for common failure scenarios.
@@ -24,6 +32,9 @@ var (
 	ErrCreateRepository     = errors.New("failed to create repository")
 	ErrPushChanges          = errors.New("failed to push changes")
 	ErrDefaultBranch        = errors.New("failed to set default branch")
+	ErrProtectRepository    = errors.New("failed to protect repository")
+	ErrUnprotectRepository  = errors.New("failed to unprotect repository")
+	ErrMirrorLFS            = errors.New("failed to mirror lfs objects")
 )
 
 // Push handles the process of pushing changes to a Git provider.
@@ -37,7 +48,7 @@ var (
 //   - repository: The Git repository interface
 //
 // Returns an error if any step in the process fails.
-func Push(ctx context.Context, syncCfg config.SyncConfig, mirrorCfg config.MirrorConfig, provider interfaces.GitProvider, writer interfaces.MirrorWriter, repository interfaces.GitRepository) error {
+func Push(ctx context.Context, syncCfg config.SyncConfig, mirrorCfg config.MirrorConfig, provider interfaces.GitProvider, writer interfaces.MirrorWriter, repository interfaces.GitRepository) (err error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Push")
 	//	targetProviderCfg.DebugLog(logger).Msg("Push")
@@ -54,19 +65,72 @@ func Push(ctx context.Context, syncCfg config.SyncConfig, mirrorCfg config.Mirro
 		forcePush = true
 	}
 
-	if mirrorCfg.Settings.Disabled {
-		err := provider.Unprotect(ctx, repository.ProjectInfo().DefaultBranch, projectID)
-		if err != nil {
-			return fmt.Errorf("failed to protect the repository at provider: %w", err)
+	// Resolved, verified and pruned before unprotecting, so a mirror with nothing to push, or refs refused, is left as it was
+	refSpecs, err := pushRefSpecs(mirrorCfg, repository)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPushChanges, err)
+	}
+
+	if len(mirrorCfg.Settings.RefSpecs) > 0 && len(refSpecs) == 0 {
+		logger.Info().Str("name", repository.ProjectInfo().Name(ctx)).Msg("No refs match the mirror refspecs, nothing to push")
+
+		return nil
+	}
+
+	// A GitHub App installation pushes with a token minted now, valid for the whole push
+	mirrorCfg.Auth, err = githubapp.ResolveAuth(ctx, mirrorCfg.Auth)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPushChanges, err)
+	}
+
+	pushOption := getPushOption(ctx, mirrorCfg, repository, refSpecs, forcePush)
+
+	if syncCfg.IsPartialClone() {
+		pushOption.SourceAuthCfg = syncCfg.Auth
+	}
+
+	// Verification may replace wildcard refspecs with explicit ones, which prune then deletes refs for
+	if err := verify(ctx, mirrorCfg, writer, repository, &pushOption); err != nil {
+		return fmt.Errorf("%w: %w", ErrPushChanges, err)
+	}
+
+	if err := prune(ctx, mirrorCfg, writer, repository, &pushOption); err != nil {
+		return fmt.Errorf("%w: %w", ErrPushChanges, err)
+	}
+
+	// LFS objects go first, servers may refuse refs pointing at objects they do not have. They are not protected,
+	// so they are mirrored before unprotecting
+	if mirrorCfg.Settings.LFS {
+		if err := mirrorLFS(ctx, syncCfg, mirrorCfg, repository, &pushOption); err != nil {
+			return fmt.Errorf("%w: %w", ErrMirrorLFS, err)
 		}
 	}
 
-	pushOption := getPushOption(ctx, mirrorCfg, repository, forcePush)
+	if mirrorCfg.Settings.Disabled {
+		if err := provider.Unprotect(ctx, repository.ProjectInfo().DefaultBranch, projectID); err != nil {
+			return fmt.Errorf("%w: %w", ErrUnprotectRepository, err)
+		}
+
+		// A failing step leaves the default branch protected again, as the mirror was before the push
+		defer func() {
+			if err == nil || errors.Is(err, ErrProtectRepository) {
+				return
+			}
+
+			if protectErr := provider.Protect(ctx, mirrorCfg.Owner, repository.ProjectInfo().DefaultBranch, projectID); protectErr != nil {
+				logger.Error().Err(protectErr).Str("name", repository.ProjectInfo().Name(ctx)).Msg("Failed to protect the repository again")
+			}
+		}()
+	}
 
 	if err := writer.Push(ctx, repository, pushOption); err != nil {
 		return fmt.Errorf("%w: %w", ErrPushChanges, err)
 	}
 
+	if err := rotateArchives(ctx, mirrorCfg, pushOption); err != nil {
+		return err
+	}
+
 	if err := provider.SetDefaultBranch(ctx, mirrorCfg.Owner, repository.ProjectInfo().Name(ctx), repository.ProjectInfo().DefaultBranch); err != nil {
 		return fmt.Errorf("%w: %w", ErrDefaultBranch, err)
 	}
@@ -74,33 +138,125 @@ func Push(ctx context.Context, syncCfg config.SyncConfig, mirrorCfg config.Mirro
 	if mirrorCfg.Settings.Disabled {
 		err := provider.Protect(ctx, mirrorCfg.Owner, repository.ProjectInfo().DefaultBranch, projectID)
 		if err != nil {
-			return fmt.Errorf("failed to protect the repository at provider: %w", err)
+			return fmt.Errorf("%w: %w", ErrProtectRepository, err)
 		}
 	}
 
 	return nil
 }
 
+// FailureCategory classifies an error returned by Push into one of the model.Failure* categories.
+// Errors that cannot be attributed to a specific step are classified as push failures.
+func FailureCategory(err error) string {
+	switch {
+	case errors.Is(err, ErrCreateRepository):
+		return model.FailureCreate
+	case errors.Is(err, ErrProtectRepository), errors.Is(err, ErrUnprotectRepository):
+		return model.FailureProtect
+	case errors.Is(err, ErrDefaultBranch):
+		return model.FailureDefaultBranch
+	case errors.Is(err, ErrMirrorLFS):
+		return model.FailureLFS
+	case errors.Is(err, ErrVerifyRefs):
+		return model.FailureVerify
+	case errors.Is(err, ErrArchiveRetention):
+		return model.FailureRetention
+	default:
+		return model.FailurePush
+	}
+}
+
+// pushRefSpecs resolves the refspecs configured for the mirror against the refs of the repository,
+// so both git backends push the same refs. Without configured refspecs it returns nil,
+// pushing all branches and tags. Remote-tracking refs are never pushed.
+func pushRefSpecs(mirrorCfg config.MirrorConfig, repository interfaces.GitRepository) ([]string, error) {
+	if len(mirrorCfg.Settings.RefSpecs) == 0 {
+		return nil, nil
+	}
+
+	refNames, err := localRefNames(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.ExpandRefSpecs(mirrorCfg.Settings.RefSpecs, refNames), nil
+}
+
+// localRefNames returns the names of the refs of the repository, without remote-tracking refs.
+func localRefNames(repository interfaces.GitRepository) ([]string, error) {
+	refs, err := repository.GoGitRepository().References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	var refNames []string
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && !ref.Name().IsRemote() {
+			refNames = append(refNames, ref.Name().String())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	return refNames, nil
+}
+
 // getPushOption determines the appropriate PushOption based on the provider configuration.
 // It handles different scenarios for archive, directory, and remote Git providers.
-func getPushOption(ctx context.Context, mirrorCfg config.MirrorConfig, repository interfaces.GitRepository, forcePush bool) model.PushOption {
+func getPushOption(ctx context.Context, mirrorCfg config.MirrorConfig, repository interfaces.GitRepository, refSpecs []string, forcePush bool) model.PushOption {
 	switch strings.ToLower(mirrorCfg.ProviderType) {
 	case config.ARCHIVE:
 		name := repository.ProjectInfo().Name(ctx)
 
-		return model.NewPushOption(archive.TargetPath(name, mirrorCfg.Path), false, false, config.AuthConfig{})
+		target := archive.TargetPath(name, mirrorCfg.Path, mirrorCfg.Settings.Format)
+		if mirrorCfg.Encryption.IsEnabled() {
+			target += archive.EncryptedExtension
+		}
+
+		return model.NewPushOption(target, nil, false, false, config.AuthConfig{})
+	case config.S3:
+		name := repository.ProjectInfo().Name(ctx)
+
+		key := objectstore.Key(mirrorCfg.S3.Prefix, archive.TargetPath(name, "", mirrorCfg.Settings.Format))
+		if mirrorCfg.Encryption.IsEnabled() {
+			key += archive.EncryptedExtension
+		}
+
+		return model.NewPushOption(key, nil, false, false, config.AuthConfig{})
 	case config.DIRECTORY:
-		return model.NewPushOption(mirrorCfg.Path, false, false, config.AuthConfig{})
+		return model.NewPushOption(mirrorCfg.Path, nil, false, false, config.AuthConfig{})
+	case config.GIT:
+		// The template decides the transport, credentials only go into HTTP(S) URLs
+		gitURL := toGitURL(ctx, mirrorCfg, repository)
+		if mirrorCfg.Auth.Token != "" && strings.HasPrefix(gitURL, "http") {
+			username := "any"
+			if mirrorCfg.Auth.Username != "" {
+				username = mirrorCfg.Auth.Username
+			}
+
+			gitURL = stringconvert.AddBasicAuthToURL(ctx, gitURL, username, mirrorCfg.Auth.Token)
+		}
+
+		return model.NewPushOption(gitURL, refSpecs, false, forcePush, mirrorCfg.Auth)
 	default:
 		var gitURL string
 		if strings.EqualFold(mirrorCfg.Auth.Protocol, config.SSH) {
 			gitURL = toGitURL(ctx, mirrorCfg, repository)
 		} else {
 			url := toGitURL(ctx, mirrorCfg, repository)
-			gitURL = stringconvert.AddBasicAuthToURL(ctx, url, "any", mirrorCfg.Auth.Token)
+			username := "any"
+			if mirrorCfg.Auth.Username != "" {
+				username = mirrorCfg.Auth.Username
+			}
+
+			gitURL = stringconvert.AddBasicAuthToURL(ctx, url, username, mirrorCfg.Auth.Token)
 		}
 
-		return model.NewPushOption(gitURL, false, forcePush, mirrorCfg.Auth)
+		return model.NewPushOption(gitURL, refSpecs, false, forcePush, mirrorCfg.Auth)
 	}
 }
 
@@ -130,6 +286,7 @@ func create(ctx context.Context, mirrorCfg config.MirrorConfig, provider interfa
 	disabled := mirrorCfg.Settings.Disabled
 
 	option := model.NewCreateOption(name, visibility, description, repository.ProjectInfo().DefaultBranch, disabled)
+	option.Owner = mirrorCfg.Owner
 
 	projectID, err := provider.CreateProject(ctx, option)
 	if err != nil {
@@ -161,7 +318,7 @@ func exists(ctx context.Context, mirrorCfg config.MirrorConfig, provider interfa
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering exists")
 
-	if isArchiveOrDirectory(mirrorCfg.ProviderType) {
+	if isStorageTarget(mirrorCfg.ProviderType) {
 		return false, ctx, "", nil
 	}
 
@@ -190,9 +347,10 @@ func exists(ctx context.Context, mirrorCfg config.MirrorConfig, provider interfa
 	return true, ctx, projectID, nil
 }
 
-// isArchiveOrDirectory checks if the provider is of type ARCHIVE or DIRECTORY.
-func isArchiveOrDirectory(provider string) bool {
-	return strings.EqualFold(provider, config.ARCHIVE) || strings.EqualFold(provider, config.DIRECTORY)
+// isStorageTarget checks if the provider is of type ARCHIVE, DIRECTORY or S3, which store repositories
+// without a git server.
+func isStorageTarget(provider string) bool {
+	return strings.EqualFold(provider, config.ARCHIVE) || strings.EqualFold(provider, config.DIRECTORY) || strings.EqualFold(provider, config.S3)
 }
 
 // SetGPSUpstreamRemoteFromOrigin sets the GPSUPSTREAM remote to match the ORIGIN remote.
@@ -235,12 +393,37 @@ func toGitURL(ctx context.Context, mirrorCfg config.MirrorConfig, repository int
 		repositoryName = repository.ProjectInfo().CleanName
 	}
 
+	return mirrorURL(ctx, mirrorCfg, repositoryName)
+}
+
+// mirrorURL returns the URL of the repository named repositoryName at the mirror.
+func mirrorURL(ctx context.Context, mirrorCfg config.MirrorConfig, repositoryName string) string {
+	logger := log.Logger(ctx)
+
+	if mirrorCfg.ProviderType == config.GIT {
+		return plaingit.ExpandURLTemplate(mirrorCfg.URLTemplate, mirrorCfg.Owner, repositoryName)
+	}
+
 	trimmedProviderConfigURL := strings.TrimRight(mirrorCfg.GetDomain(), "/")
 	projectPath := getProjectPath(repositoryName, mirrorCfg)
 
+	// Bitbucket Server serves HTTP(S) clones below /scm and needs the ssh:// form for SSH
+	isBitbucketServer := mirrorCfg.ProviderType == config.BITBUCKETSERVER
+	if isBitbucketServer && mirrorCfg.Auth.Protocol != config.SSH {
+		projectPath = "scm/" + projectPath
+	}
+
 	// Handle URL scheme based on auth protocol type
 	switch mirrorCfg.Auth.Protocol {
 	case config.SSH:
+		switch mirrorCfg.ProviderType {
+		case config.BITBUCKETSERVER:
+			return fmt.Sprintf("ssh://git@%s/%s", trimmedProviderConfigURL, projectPath)
+		case config.AZUREDEVOPS:
+			// Azure DevOps serves SSH from its ssh subdomain, with a v3 path without _git
+			return fmt.Sprintf("git@ssh.%s:v3/%s", trimmedProviderConfigURL, strings.Replace(projectPath, "/_git/", "/", 1))
+		}
+
 		url := fmt.Sprintf("git@%s:%s", trimmedProviderConfigURL, projectPath)
 
 		return url
@@ -265,5 +448,16 @@ func toGitURL(ctx context.Context, mirrorCfg config.MirrorConfig, repository int
 
 // getProjectPath constructs the project path based on whether it's a group or user repository.
 func getProjectPath(repositoryName string, mirrorCfg config.MirrorConfig) string {
+	switch mirrorCfg.ProviderType {
+	case config.AZUREDEVOPS:
+		return fmt.Sprintf("%s/_git/%s", mirrorCfg.Owner, url.PathEscape(repositoryName))
+	case config.BITBUCKET:
+		workspace, _ := bitbucket.SplitOwner(mirrorCfg.Owner)
+
+		return fmt.Sprintf("%s/%s.git", workspace, bitbucket.Slug(repositoryName))
+	case config.BITBUCKETSERVER:
+		return fmt.Sprintf("%s/%s.git", strings.ToLower(mirrorCfg.Owner), bitbucketserver.Slug(repositoryName))
+	}
+
 	return fmt.Sprintf("%s/%s", mirrorCfg.Owner, repositoryName)
 }
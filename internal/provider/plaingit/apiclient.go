@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package plaingit provides a git provider for servers without an API, such as cgit,
+// gitolite or bare SSH hosts. Repositories are given as clone URLs, and their default
+// branch is read from the HEAD the server advertises.
+package plaingit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
+)
+
+// ErrNoURLs is returned when neither clone URLs nor a URL template with repository names are configured.
+var ErrNoURLs = errors.New("no repository urls configured")
+
+// APIClient lists and mirrors repositories by their clone URLs alone.
+type APIClient struct {
+	authCfg     config.AuthConfig
+	urls        []string
+	urlTemplate string
+}
+
+// CreateProject does nothing, a plain git server has no API to create repositories with.
+// The repository must exist at the target, or be created by the server on push.
+func (APIClient) CreateProject(_ context.Context, _ model.CreateProjectOption) (string, error) {
+	return "", nil
+}
+
+// ProjectExists always reports the repository as existing, as there is no API to ask.
+func (APIClient) ProjectExists(ctx context.Context, owner, repo string) (bool, string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering PlainGit:ProjectExists")
+	logger.Debug().Str("owner", owner).Str("repo", repo).Msg("PlainGit:ProjectExists")
+
+	return true, "", nil
+}
+
+func (APIClient) IsValidProjectName(_ context.Context, name string) bool {
+	return name != ""
+}
+
+func (APIClient) Name() string {
+	return config.GIT
+}
+
+// GetProjectInfos synthesizes project infos for the configured URLs.
+// The URLs are either given as is, or expanded from the URL template for each included repository name.
+func (api APIClient) GetProjectInfos(ctx context.Context, opt model.ProviderOption, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering PlainGit:GetProjectInfos")
+	logger.Debug().Bool("filtering", filtering).Msg("PlainGit:GetProjectInfos")
+
+	urls, err := api.repositoryURLs(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	projectinfos := make([]model.ProjectInfo, 0, len(urls))
+
+	for _, cloneURL := range urls {
+		defaultBranch, err := headBranch(ctx, cloneURL, api.authCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read default branch of %s: %w", RepositoryName(cloneURL), err)
+		}
+
+		projectinfos = append(projectinfos, model.ProjectInfo{
+			OriginalName:  RepositoryName(cloneURL),
+			HTTPSURL:      cloneURL,
+			SSHURL:        cloneURL,
+			DefaultBranch: defaultBranch,
+			Visibility:    "private",
+			ProjectID:     cloneURL,
+		})
+	}
+
+	if filtering {
+		return targetfilter.FilterIncludedExcludedGen()(ctx, opt, projectinfos) //nolint
+	}
+
+	return projectinfos, nil
+}
+
+func (api APIClient) repositoryURLs(opt model.ProviderOption) ([]string, error) {
+	if len(api.urls) > 0 {
+		return api.urls, nil
+	}
+
+	if api.urlTemplate == "" || len(opt.IncludedRepositories) == 0 {
+		return nil, ErrNoURLs
+	}
+
+	urls := make([]string, 0, len(opt.IncludedRepositories))
+	for _, name := range opt.IncludedRepositories {
+		urls = append(urls, ExpandURLTemplate(api.urlTemplate, opt.Owner, name))
+	}
+
+	return urls, nil
+}
+
+// Protect does nothing, a plain git server has no branch protection API.
+func (APIClient) Protect(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+// Unprotect does nothing, a plain git server has no branch protection API.
+func (APIClient) Unprotect(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// SetDefaultBranch does nothing, a plain git server has no API to set HEAD with.
+func (APIClient) SetDefaultBranch(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+func NewPlainGitAPIClient(ctx context.Context, opt model.GitProviderClientOption) APIClient {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering PlainGit:NewPlainGitAPIClient")
+
+	return APIClient{
+		authCfg:     opt.AuthCfg,
+		urls:        opt.URLs,
+		urlTemplate: opt.URLTemplate,
+	}
+}
@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package plaingit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepository creates a repository with one commit, with HEAD on the given branch.
+func newRepository(t *testing.T, dir, branch string) string {
+	t.Helper()
+
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	headRef := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(branch))
+	require.NoError(t, repo.Storer.SetReference(headRef))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte(branch), 0o600))
+
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	return dir
+}
+
+func TestAPIClient_GetProjectInfos(t *testing.T) {
+	root := t.TempDir()
+	tools := newRepository(t, filepath.Join(root, "backup", "tools.git"), "trunk")
+	docs := newRepository(t, filepath.Join(root, "backup", "docs.git"), "main")
+
+	emptyDir := filepath.Join(root, "backup", "empty.git")
+	_, err := git.PlainInit(emptyDir, true)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		clientOpt   model.GitProviderClientOption
+		providerOpt model.ProviderOption
+		filtering   bool
+		want        map[string]string
+		wantErr     error
+	}{
+		{
+			name:      "explicit urls",
+			clientOpt: model.GitProviderClientOption{URLs: []string{tools, docs, emptyDir}},
+			want:      map[string]string{"tools": "trunk", "docs": "main", "empty": ""},
+		},
+		{
+			name:        "explicit urls filtered by include",
+			clientOpt:   model.GitProviderClientOption{URLs: []string{tools, docs}},
+			providerOpt: model.ProviderOption{IncludedRepositories: []string{"docs"}},
+			filtering:   true,
+			want:        map[string]string{"docs": "main"},
+		},
+		{
+			name:        "url template with repository names",
+			clientOpt:   model.GitProviderClientOption{URLTemplate: filepath.Join(root, "{owner}", "{name}.git")},
+			providerOpt: model.ProviderOption{Owner: "backup", IncludedRepositories: []string{"tools", "docs"}},
+			filtering:   true,
+			want:        map[string]string{"tools": "trunk", "docs": "main"},
+		},
+		{
+			name:      "url template without repository names",
+			clientOpt: model.GitProviderClientOption{URLTemplate: filepath.Join(root, "{name}.git")},
+			wantErr:   ErrNoURLs,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			api := NewPlainGitAPIClient(context.Background(), tabletest.clientOpt)
+
+			infos, err := api.GetProjectInfos(context.Background(), tabletest.providerOpt, tabletest.filtering)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+
+				return
+			}
+
+			require.NoError(err)
+
+			got := map[string]string{}
+			for _, info := range infos {
+				require.Equal(info.HTTPSURL, info.SSHURL)
+				require.Equal(info.HTTPSURL, info.ProjectID)
+				got[info.OriginalName] = info.DefaultBranch
+			}
+
+			require.Equal(tabletest.want, got)
+		})
+	}
+}
+
+func TestAPIClient_GetProjectInfosUnreachable(t *testing.T) {
+	api := NewPlainGitAPIClient(context.Background(), model.GitProviderClientOption{
+		URLs: []string{filepath.Join(t.TempDir(), "missing.git")},
+	})
+
+	_, err := api.GetProjectInfos(context.Background(), model.ProviderOption{}, false)
+	require.ErrorContains(t, err, "missing")
+}
+
+func TestAPIClient_TargetOperations(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	api := NewPlainGitAPIClient(ctx, model.GitProviderClientOption{})
+
+	exists, projectID, err := api.ProjectExists(ctx, "owner", "repo")
+	require.NoError(err)
+	require.True(exists)
+	require.Empty(projectID)
+
+	require.NoError(api.Protect(ctx, "owner", "main", ""))
+	require.NoError(api.Unprotect(ctx, "main", ""))
+	require.NoError(api.SetDefaultBranch(ctx, "owner", "repo", "main"))
+	require.Equal(config.GIT, api.Name())
+}
+
+func TestResolveHead(t *testing.T) {
+	hash := plumbing.NewHash("1111111111111111111111111111111111111111")
+	other := plumbing.NewHash("2222222222222222222222222222222222222222")
+
+	tests := []struct {
+		name string
+		refs []*plumbing.Reference
+		want string
+	}{
+		{
+			name: "symbolic head",
+			refs: []*plumbing.Reference{
+				plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/develop"),
+				plumbing.NewHashReference("refs/heads/develop", hash),
+			},
+			want: "develop",
+		},
+		{
+			name: "head by commit prefers main",
+			refs: []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.HEAD, hash),
+				plumbing.NewHashReference("refs/heads/feature", hash),
+				plumbing.NewHashReference("refs/heads/main", hash),
+				plumbing.NewHashReference("refs/heads/other", other),
+			},
+			want: "main",
+		},
+		{
+			name: "head by commit without preferred branch",
+			refs: []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.HEAD, hash),
+				plumbing.NewHashReference("refs/heads/zeta", hash),
+				plumbing.NewHashReference("refs/heads/alpha", hash),
+			},
+			want: "alpha",
+		},
+		{
+			name: "no head",
+			refs: []*plumbing.Reference{plumbing.NewHashReference("refs/heads/main", hash)},
+			want: "",
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, resolveHead(tabletest.refs))
+		})
+	}
+}
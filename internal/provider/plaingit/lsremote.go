@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package plaingit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/gitlib"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// preferredBranches are picked, in order, when HEAD matches several branches.
+var preferredBranches = []string{"main", "master"}
+
+// headBranch lists the refs of a remote repository, like git ls-remote, and returns the branch HEAD points at.
+// An empty repository has no default branch and gives an empty string.
+func headBranch(ctx context.Context, cloneURL string, authCfg config.AuthConfig) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering PlainGit:headBranch")
+
+	auth, err := authMethod(ctx, cloneURL, authCfg)
+	if err != nil {
+		return "", err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &gogitconfig.RemoteConfig{
+		Name: config.ORIGIN,
+		URLs: []string{cloneURL},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to list remote refs: %w", err)
+	}
+
+	branch := resolveHead(refs)
+	logger.Debug().Str("branch", branch).Msg("PlainGit:headBranch")
+
+	return branch, nil
+}
+
+// resolveHead returns the branch name HEAD points at.
+// Servers without the symref capability only advertise the commit of HEAD,
+// the branch is then found by its commit.
+func resolveHead(refs []*plumbing.Reference) string {
+	index := slices.IndexFunc(refs, func(ref *plumbing.Reference) bool {
+		return ref.Name() == plumbing.HEAD
+	})
+	if index < 0 {
+		return ""
+	}
+
+	head := refs[index]
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target().Short()
+	}
+
+	var candidates []string
+
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			candidates = append(candidates, ref.Name().Short())
+		}
+	}
+
+	for _, preferred := range preferredBranches {
+		if slices.Contains(candidates, preferred) {
+			return preferred
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	slices.Sort(candidates)
+
+	return candidates[0]
+}
+
+// authMethod returns the auth method for the transport of the URL.
+// Local repositories need none, and HTTP(S) goes anonymous without a token.
+func authMethod(ctx context.Context, cloneURL string, authCfg config.AuthConfig) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(cloneURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository url: %w", err)
+	}
+
+	switch endpoint.Protocol {
+	case "ssh":
+		authCfg.Protocol = config.SSH
+	case "http", "https":
+		if authCfg.Token == "" {
+			return nil, nil //nolint:nilnil
+		}
+
+		authCfg.Protocol = config.TLS
+	default:
+		return nil, nil //nolint:nilnil
+	}
+
+	auth, err := gitlib.NewAuthService().GetAuthMethod(ctx, authCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth method: %w", err)
+	}
+
+	return auth, nil
+}
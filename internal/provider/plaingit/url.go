@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package plaingit
+
+import (
+	"path"
+	"strings"
+)
+
+// URL template placeholders.
+const (
+	OwnerPlaceholder = "{owner}"
+	NamePlaceholder  = "{name}"
+)
+
+// ExpandURLTemplate replaces the {owner} and {name} placeholders of a URL template.
+//
+// Example:
+//
+//	ExpandURLTemplate("git@git.example.com:{owner}/{name}.git", "backup", "tools")
+//	// git@git.example.com:backup/tools.git
+func ExpandURLTemplate(template, owner, name string) string {
+	return strings.NewReplacer(OwnerPlaceholder, owner, NamePlaceholder, name).Replace(template)
+}
+
+// RepositoryName derives the repository name from a clone URL,
+// the last path element without a .git suffix.
+// It handles both URLs and scp-like SSH addresses such as git@host:owner/name.git.
+func RepositoryName(cloneURL string) string {
+	trimmed := strings.TrimRight(cloneURL, "/")
+
+	// scp-like addresses separate host and path with a colon
+	if !strings.Contains(trimmed, "://") {
+		if _, repoPath, found := strings.Cut(trimmed, ":"); found {
+			trimmed = repoPath
+		}
+	}
+
+	return strings.TrimSuffix(path.Base(trimmed), ".git")
+}
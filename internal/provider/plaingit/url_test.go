@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package plaingit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandURLTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		owner    string
+		repo     string
+		expected string
+	}{
+		{name: "scp-like ssh", template: "git@git.example.com:{owner}/{name}.git", owner: "backup", repo: "tools", expected: "git@git.example.com:backup/tools.git"},
+		{name: "https without owner", template: "https://git.example.com/cgit/{name}", repo: "tools", expected: "https://git.example.com/cgit/tools"},
+		{name: "repeated placeholder", template: "/srv/{name}/{name}.git", repo: "tools", expected: "/srv/tools/tools.git"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.expected, ExpandURLTemplate(tabletest.template, tabletest.owner, tabletest.repo))
+		})
+	}
+}
+
+func TestRepositoryName(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "https with .git", url: "https://git.example.com/group/tools.git", expected: "tools"},
+		{name: "https with trailing slash", url: "https://git.example.com/cgit/tools/", expected: "tools"},
+		{name: "ssh url", url: "ssh://git@git.example.com:2222/srv/tools.git", expected: "tools"},
+		{name: "scp-like", url: "git@git.example.com:tools.git", expected: "tools"},
+		{name: "scp-like with path", url: "gitolite@host:team/tools", expected: "tools"},
+		{name: "local path", url: "/srv/git/tools.git", expected: "tools"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.expected, RepositoryName(tabletest.url))
+		})
+	}
+}
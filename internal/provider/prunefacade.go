@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// prune prepares the push to delete the refs of the mirror that the source no longer has.
+// The writers prune the refs under wildcard refspecs themselves, the refs under explicit refspecs,
+// resolved from the refspecs setting or by verification, are deleted with explicit delete refspecs.
+// The default branch is never pruned, providers refuse deleting it. In a prune dry run the refs
+// are only listed, and the push deletes nothing.
+func prune(ctx context.Context, mirrorCfg config.MirrorConfig, writer interfaces.MirrorWriter, repository interfaces.GitRepository, pushOption *model.PushOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering prune")
+
+	dryRun := model.CLIOptions(ctx).PruneDryRun
+	if !dryRun && !model.Prune(ctx, mirrorCfg) {
+		return nil
+	}
+
+	lister, ok := writer.(interfaces.RefLister)
+	if !ok {
+		return nil
+	}
+
+	refSpecs := mirrorCfg.Settings.RefSpecs
+	if len(refSpecs) == 0 {
+		refSpecs = config.DefaultRefSpecs()
+	}
+
+	refNames, err := localRefNames(repository)
+	if err != nil {
+		return err
+	}
+
+	targetRefs, err := lister.ListRefs(ctx, model.CloneOption{URL: toGitURL(ctx, mirrorCfg, repository), AuthCfg: mirrorCfg.Auth})
+	if err != nil {
+		return fmt.Errorf("failed to list mirror refs: %w", err)
+	}
+
+	targetRefNames := make([]string, 0, len(targetRefs))
+
+	defaultBranch := "refs/heads/" + repository.ProjectInfo().DefaultBranch
+	for refName := range targetRefs {
+		if refName != defaultBranch {
+			targetRefNames = append(targetRefNames, refName)
+		}
+	}
+
+	stale := config.StaleRefs(refSpecs, refNames, targetRefNames)
+
+	for _, refName := range stale {
+		if dryRun {
+			logger.Info().Str("ref", refName).Msg("Prune dry run, would delete ref at mirror")
+		} else {
+			logger.Info().Str("ref", refName).Msg("Pruning ref at mirror")
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	pushOption.Prune = true
+
+	if !slices.ContainsFunc(pushOption.RefSpecs, func(refSpec string) bool { return strings.Contains(refSpec, "*") }) {
+		for _, refName := range stale {
+			pushOption.RefSpecs = append(pushOption.RefSpecs, ":"+refName)
+		}
+	}
+
+	return nil
+}
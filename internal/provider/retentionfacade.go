@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrArchiveRetention = errors.New("failed to apply archive retention")
+
+// archiveStore is where a mirror keeps its archives, a directory or an object storage bucket.
+// The path of an archive is its file path or object key.
+type archiveStore interface {
+	ListArchives(ctx context.Context, name string) ([]archive.Archive, error)
+	CheckArchive(ctx context.Context, path string) error
+	DeleteArchive(ctx context.Context, path string) error
+}
+
+// rotateArchives deletes the archives of the repository the retention policy of an archive or s3 mirror no longer
+// keeps, with their manifests, after the push wrote a new archive. Nothing is deleted unless the new archive is the
+// newest and can be read, so the newest good archive is never removed. In a retention dry run the archives are only listed.
+func rotateArchives(ctx context.Context, mirrorCfg config.MirrorConfig, pushOption model.PushOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering rotateArchives")
+
+	if !mirrorCfg.WritesArchives() || !mirrorCfg.Settings.HasRetention() {
+		return nil
+	}
+
+	dryRun := model.CLIOptions(ctx).RetentionDryRun
+
+	maxAge, err := mirrorCfg.Settings.MaxAgeDuration()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveRetention, err)
+	}
+
+	policy := archive.RetentionPolicy{
+		KeepLast:    mirrorCfg.Settings.KeepLast,
+		KeepDaily:   mirrorCfg.Settings.KeepDaily,
+		KeepWeekly:  mirrorCfg.Settings.KeepWeekly,
+		KeepMonthly: mirrorCfg.Settings.KeepMonthly,
+		MaxAge:      maxAge,
+	}
+
+	name, _, ok := archive.ParseTargetPath(pushOption.Target)
+	if !ok {
+		return fmt.Errorf("%w: not an archive path: %s", ErrArchiveRetention, pushOption.Target)
+	}
+
+	store, err := newArchiveStore(ctx, mirrorCfg, pushOption.Target)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveRetention, err)
+	}
+
+	archives, err := store.ListArchives(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveRetention, err)
+	}
+
+	if err := checkNewestArchive(ctx, store, archives, pushOption.Target); err != nil {
+		logger.Warn().Err(err).Str("archive", pushOption.Target).Msg("Skipping archive retention, the new archive is not the newest good archive")
+
+		return nil
+	}
+
+	for _, expired := range policy.Expired(archives, time.Now()) {
+		if dryRun {
+			logger.Info().Str("archive", expired.Path).Time("createdAt", expired.CreatedAt).Msg("Retention dry run, would delete archive")
+
+			continue
+		}
+
+		if err := store.DeleteArchive(ctx, expired.Path); err != nil {
+			return fmt.Errorf("%w: %w", ErrArchiveRetention, err)
+		}
+
+		logger.Info().Str("archive", expired.Path).Time("createdAt", expired.CreatedAt).Msg("Deleted archive by retention")
+	}
+
+	return nil
+}
+
+// newArchiveStore returns the store of the archives of the mirror, the bucket of an s3 mirror,
+// else the directory of the archive at target.
+func newArchiveStore(ctx context.Context, mirrorCfg config.MirrorConfig, target string) (archiveStore, error) {
+	if mirrorCfg.ProviderType == config.S3 {
+		return NewObjectStoreClient(ctx, mirrorCfg)
+	}
+
+	encryption, err := archive.NewEncryption(mirrorCfg.Encryption)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return directoryArchiveStore{dir: filepath.Dir(target), encryption: encryption}, nil
+}
+
+// checkNewestArchive checks that the archive at target is the newest of the archives, and can be read.
+func checkNewestArchive(ctx context.Context, store archiveStore, archives []archive.Archive, target string) error {
+	if len(archives) == 0 || archives[0].Path != target {
+		return fmt.Errorf("%w: %s is not the newest archive", archive.ErrArchiveRead, target)
+	}
+
+	return store.CheckArchive(ctx, target) //nolint:wrapcheck
+}
+
+// directoryArchiveStore keeps the archives of an archive mirror in its directory.
+type directoryArchiveStore struct {
+	dir        string
+	encryption *archive.Encryption
+}
+
+func (d directoryArchiveStore) ListArchives(_ context.Context, name string) ([]archive.Archive, error) {
+	return archive.ListArchives(d.dir, name) //nolint:wrapcheck
+}
+
+// CheckArchive reads the HEAD of the archive at path. An encrypted archive the mirror has no identity for
+// is checked against the checksum in its manifest instead.
+func (d directoryArchiveStore) CheckArchive(ctx context.Context, path string) error {
+	if archive.IsEncrypted(path) && !d.encryption.CanDecrypt() {
+		return archive.VerifyChecksum(path) //nolint:wrapcheck
+	}
+
+	if _, err := archive.NewReader().WithEncryption(d.encryption).HeadBranch(ctx, path); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// DeleteArchive deletes the archive at path and its manifest, when it has one.
+func (d directoryArchiveStore) DeleteArchive(_ context.Context, path string) error {
+	if err := os.Remove(path); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if err := os.Remove(archive.ManifestPath(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
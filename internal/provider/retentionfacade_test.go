@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/mirror/archive"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// newArchives writes archives of a bare repository named app with their manifests to dir, created the given number
+// of hours ago and encrypted with the encryption when it is set, returning their paths in the same order.
+func newArchives(t *testing.T, dir string, encryption *archive.Encryption, hoursAgo ...int) []string {
+	t.Helper()
+
+	worktreeDir := t.TempDir()
+
+	repo, err := git.PlainInit(worktreeDir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, "file.txt"), []byte("content"), 0o600))
+
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	bareDir := filepath.Join(t.TempDir(), "app")
+	_, err = git.PlainClone(bareDir, true, &git.CloneOptions{URL: worktreeDir})
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(hoursAgo))
+
+	for _, hours := range hoursAgo {
+		createdAt := time.Now().Add(-time.Duration(hours) * time.Hour)
+		path := filepath.Join(dir, "app"+archive.FormatArchiveTimestamp(createdAt)+".tar.gz")
+		if encryption != nil {
+			path += archive.EncryptedExtension
+		}
+
+		require.NoError(t, archive.NewHandler().WithEncryption(encryption).CreateArchive(context.Background(), bareDir, path, "app"))
+
+		manifest, err := archive.NewManifest(bareDir, path, "app", worktreeDir, "test")
+		require.NoError(t, err)
+		require.NoError(t, archive.WriteManifest(path, manifest))
+
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+func TestRotateArchives(t *testing.T) {
+	tests := []struct {
+		name        string
+		dryRun      bool
+		keepLast    int
+		targetIndex int
+		wantKept    []int
+	}{
+		{name: "expired archives deleted", keepLast: 2, wantKept: []int{0, 1}},
+		{name: "dry run deletes nothing", keepLast: 2, dryRun: true, wantKept: []int{0, 1, 2}},
+		{name: "not the newest archive", keepLast: 1, targetIndex: 1, wantKept: []int{0, 1, 2}},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths := newArchives(t, dir, nil, 0, 24, 48)
+
+			ctx := model.WithCLIOpt(context.Background(), model.CLIOption{RetentionDryRun: tabletest.dryRun})
+			mirrorCfg := gpsconfig.MirrorConfig{
+				BaseConfig: gpsconfig.BaseConfig{ProviderType: gpsconfig.ARCHIVE},
+				Path:       dir,
+				Settings:   gpsconfig.MirrorSettings{KeepLast: tabletest.keepLast},
+			}
+			pushOption := model.NewPushOption(paths[tabletest.targetIndex], nil, false, false, gpsconfig.AuthConfig{})
+
+			require.NoError(t, rotateArchives(ctx, mirrorCfg, pushOption))
+
+			for index, path := range paths {
+				_, err := os.Stat(path)
+				if slices.Contains(tabletest.wantKept, index) {
+					require.NoError(t, err, path)
+				} else {
+					require.ErrorIs(t, err, os.ErrNotExist, path)
+					require.NoFileExists(t, archive.ManifestPath(path))
+				}
+			}
+		})
+	}
+}
+
+func TestRotateEncryptedArchives(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	encryptionCfg := gpsconfig.EncryptionConfig{Recipients: []string{identity.Recipient().String()}}
+
+	encryption, err := archive.NewEncryption(encryptionCfg)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		removeManifest bool
+		wantKept       []int
+	}{
+		{name: "checksum of the newest archive matches", wantKept: []int{0}},
+		{name: "newest archive without manifest", removeManifest: true, wantKept: []int{0, 1}},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths := newArchives(t, dir, encryption, 0, 24)
+
+			if tabletest.removeManifest {
+				require.NoError(t, os.Remove(archive.ManifestPath(paths[0])))
+			}
+
+			ctx := model.WithCLIOpt(context.Background(), model.CLIOption{})
+			mirrorCfg := gpsconfig.MirrorConfig{
+				BaseConfig: gpsconfig.BaseConfig{ProviderType: gpsconfig.ARCHIVE, Encryption: encryptionCfg},
+				Path:       dir,
+				Settings:   gpsconfig.MirrorSettings{KeepLast: 1},
+			}
+			pushOption := model.NewPushOption(paths[0], nil, false, false, gpsconfig.AuthConfig{})
+
+			require.NoError(t, rotateArchives(ctx, mirrorCfg, pushOption))
+
+			for index, path := range paths {
+				if slices.Contains(tabletest.wantKept, index) {
+					require.FileExists(t, path)
+				} else {
+					require.NoFileExists(t, path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package s3 provides the s3 provider, a mirror target writing archives to an S3-compatible object storage.
+// As the bucket holds no projects it only names the archives, it is not a source.
+package s3
+
+import (
+	"context"
+	"errors"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrNoSource = errors.New("s3 is a mirror target, not a source")
+
+type Client struct{}
+
+func (Client) CreateProject(_ context.Context, _ model.CreateProjectOption) (string, error) {
+	return "", nil
+}
+
+func (Client) Name() string {
+	return config.S3
+}
+
+func (Client) SetDefaultBranch(_ context.Context, _ string, _ string, _ string) error {
+	return nil
+}
+
+func (Client) IsValidProjectName(_ context.Context, _ string) bool {
+	return true
+}
+
+func (Client) ProjectExists(_ context.Context, _, _ string) (bool, string, error) {
+	return false, "", nil
+}
+
+func (Client) GetProjectInfos(_ context.Context, _ model.ProviderOption, _ bool) ([]model.ProjectInfo, error) {
+	return nil, ErrNoSource
+}
+
+func (Client) Protect(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+func (Client) Unprotect(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func NewS3Client(ctx context.Context) Client {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering S3:NewS3Client")
+
+	return Client{}
+}
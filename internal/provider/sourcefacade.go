@@ -9,12 +9,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
 	"itiquette/git-provider-sync/internal/provider/stringconvert"
+	"itiquette/git-provider-sync/internal/workerpool"
 )
 
 var ErrInvalidProjectInfoOriginalName = errors.New("empty OriginalName")
@@ -22,16 +24,26 @@ var ErrInvalidProjectInfoOriginalName = errors.New("empty OriginalName")
 // Clone clones multiple repositories based on their metadata.
 // It takes a context, a SourceReader interface for cloning operations,
 // and a slice of RepositoryMetainfo containing information about the repositories to clone.
+// Repositories are cloned concurrently, bounded by model.Concurrency, and returned in the order of projectinfos.
+// When model.ContinueOnError is enabled, a failing clone is recorded in the SyncRunMetainfo of ctx
+// and the repository is left out of the result instead of failing the whole call.
 // It returns a slice of GitRepository interfaces representing the cloned repositories and any error encountered.
 func Clone(ctx context.Context, reader interfaces.SourceReader, syncCfg config.SyncConfig, projectinfos []model.ProjectInfo) ([]interfaces.GitRepository, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Clone")
 
-	repositories := make([]interfaces.GitRepository, 0, len(projectinfos))
+	concurrency := model.Concurrency(ctx, syncCfg)
+	logger.Debug().Int("concurrency", concurrency).Int("repositories", len(projectinfos)).Msg("Clone")
+
+	repositories := make([]interfaces.GitRepository, len(projectinfos))
 
 	cliOpts := model.CLIOptions(ctx)
+	continueOnError := model.ContinueOnError(ctx, syncCfg)
+
+	err := workerpool.Run(ctx, concurrency, len(projectinfos), func(ctx context.Context, index int) error {
+		projectInfo := projectinfos[index]
+		ctx = log.WithRepository(ctx, projectInfo.OriginalName)
 
-	for _, projectInfo := range projectinfos {
 		name := stringconvert.RemoveNonAlphaNumericChars(ctx, projectInfo.OriginalName)
 		projectInfo.SetCleanName(name)
 
@@ -43,15 +55,35 @@ func Clone(ctx context.Context, reader interfaces.SourceReader, syncCfg config.S
 
 		resultRepo, err := reader.Clone(ctx, opt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to clone repository %s: %w", projectInfo.OriginalName, err)
+			err = fmt.Errorf("failed to clone repository %s: %w", projectInfo.OriginalName, err)
+			if !continueOnError {
+				return err
+			}
+
+			log.Logger(ctx).Error().Err(err).Msg("Clone failed, continuing")
+			model.RecordError(ctx, model.SyncError{
+				Category:   model.FailureClone,
+				Source:     syncCfg.GetDomain() + "/" + syncCfg.Owner,
+				Repository: projectInfo.OriginalName,
+				Err:        err,
+			})
+
+			return nil
 		}
 
 		resultRepo.ProjectMetaInfo = &projectInfo
 
-		repositories = append(repositories, resultRepo)
+		repositories[index] = resultRepo
+
+		return nil
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
 	}
 
-	return repositories, nil
+	return slices.DeleteFunc(repositories, func(repo interfaces.GitRepository) bool {
+		return repo == nil
+	}), nil
 }
 
 // FetchProjectInfos retrieves metadata information for repositories from a Git provider.
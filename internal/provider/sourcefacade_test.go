@@ -44,6 +44,21 @@ func TestClone(t *testing.T) {
 				srcR.EXPECT().Clone(mock.Anything, mock.Anything).Return(model.Repository{}, nil).Twice()
 			},
 		},
+		{
+			name: "successful concurrent clone",
+			projectinfos: []model.ProjectInfo{
+				{HTTPSURL: "https://github.com/user/repo1.git", OriginalName: "repo1"},
+				{HTTPSURL: "https://github.com/user/repo2.git", OriginalName: "repo2"},
+				{HTTPSURL: "https://github.com/user/repo3.git", OriginalName: "repo3"},
+			},
+			syncCfg: config.SyncConfig{
+				BaseConfig:  config.BaseConfig{},
+				Concurrency: 2,
+			},
+			mockSetup: func(srcR *mocks.SourceReader) {
+				srcR.EXPECT().Clone(mock.Anything, mock.Anything).Return(model.Repository{}, nil).Times(3)
+			},
+		},
 		{
 			name: "clone failure",
 			projectinfos: []model.ProjectInfo{
@@ -99,11 +114,54 @@ func TestClone(t *testing.T) {
 
 			require.NoError(t, err)
 			require.Len(t, repos, len(tabletest.projectinfos))
+
+			for index, repo := range repos {
+				require.Equal(t, tabletest.projectinfos[index].OriginalName, repo.ProjectInfo().OriginalName)
+			}
+
 			mockReader.AssertExpectations(t)
 		})
 	}
 }
 
+func TestCloneContinueOnError(t *testing.T) {
+	require := require.New(t)
+
+	meta := model.NewSyncRunMetainfo(0, "", "", 0)
+	ctx := context.WithValue(testContext(), model.SyncRunMetainfoKey{}, meta)
+
+	failFast := false
+	syncCfg := config.SyncConfig{
+		BaseConfig:  config.BaseConfig{Domain: "github.com", Owner: "user"},
+		Concurrency: 2,
+		FailFast:    &failFast,
+	}
+
+	projectinfos := []model.ProjectInfo{
+		{HTTPSURL: "https://github.com/user/repo1.git", OriginalName: "repo1"},
+		{HTTPSURL: "https://github.com/user/broken.git", OriginalName: "broken"},
+		{HTTPSURL: "https://github.com/user/repo3.git", OriginalName: "repo3"},
+	}
+
+	mockReader := new(mocks.SourceReader)
+	mockReader.EXPECT().Clone(mock.Anything, mock.MatchedBy(func(opt model.CloneOption) bool {
+		return opt.Name == "broken"
+	})).Return(model.Repository{}, errors.New("clone failed"))
+	mockReader.EXPECT().Clone(mock.Anything, mock.Anything).Return(model.Repository{}, nil).Twice()
+
+	repos, err := Clone(ctx, mockReader, syncCfg, projectinfos)
+	require.NoError(err)
+	require.Len(repos, 2)
+	require.Equal("repo1", repos[0].ProjectInfo().OriginalName)
+	require.Equal("repo3", repos[1].ProjectInfo().OriginalName)
+
+	failures := meta.Errors()
+	require.Len(failures, 1)
+	require.Equal(model.FailureClone, failures[0].Category)
+	require.Equal("broken", failures[0].Repository)
+	require.Equal("github.com/user", failures[0].Source)
+}
+
 func TestFetchProjectInfo(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/stringconvert"
+)
+
+var ErrSubmodules = errors.New("failed to read submodules")
+
+// Submodules returns the submodules declared in the .gitmodules of the repository's default branch,
+// none when it has no .gitmodules.
+func Submodules(ctx context.Context, repository interfaces.GitRepository) ([]model.Submodule, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Submodules")
+
+	repo := repository.GoGitRepository()
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(repository.ProjectInfo().DefaultBranch), true)
+	if err != nil {
+		if ref, err = repo.Head(); err != nil {
+			return nil, nil //nolint:nilerr // an empty repository has no submodules
+		}
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSubmodules, err)
+	}
+
+	file, err := commit.File(".gitmodules")
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSubmodules, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSubmodules, err)
+	}
+
+	modules := gogitconfig.NewModules()
+	if err := modules.Unmarshal([]byte(content)); err != nil {
+		return nil, fmt.Errorf("%w: .gitmodules: %w", ErrSubmodules, err)
+	}
+
+	submodules := make([]model.Submodule, 0, len(modules.Submodules))
+	for _, module := range modules.Submodules {
+		submodules = append(submodules, model.NewSubmodule(module.Name, module.Path, module.URL, repository.ProjectInfo().HTTPSURL))
+	}
+
+	slices.SortFunc(submodules, func(a, b model.Submodule) int { return strings.Compare(a.Name, b.Name) })
+
+	return submodules, nil
+}
+
+// AddSubmoduleRepositories clones the repositories of the source owner that the submodules of repositories
+// refer to and the sync would not mirror otherwise, because the repository filters left them out.
+// Their submodules are followed in turn. Repositories in projectInfos, the repositories the sync lists,
+// and excluded repositories are not added.
+func AddSubmoduleRepositories(ctx context.Context, reader interfaces.SourceReader, syncCfg config.SyncConfig, gitProvider interfaces.GitProvider,
+	projectInfos []model.ProjectInfo, repositories []interfaces.GitRepository,
+) ([]interfaces.GitRepository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AddSubmoduleRepositories")
+
+	listed := map[string]bool{}
+	for _, projectInfo := range projectInfos {
+		listed[strings.ToLower(projectInfo.OriginalName)] = true
+	}
+
+	var ownerProjectInfos []model.ProjectInfo
+
+	pending := repositories
+
+	for len(pending) > 0 {
+		missing := map[string]bool{}
+
+		for _, repository := range pending {
+			submodules, err := Submodules(ctx, repository)
+			if err != nil {
+				logger.Warn().Err(err).Str("name", repository.ProjectInfo().OriginalName).Msg("Failed to read submodules")
+
+				continue
+			}
+
+			for _, submodule := range submodules {
+				name := strings.ToLower(submodule.RepositoryName)
+				if !submodule.BelongsTo(syncCfg.GetDomain(), syncCfg.Owner) || listed[name] {
+					continue
+				}
+
+				listed[name] = true
+
+				if slices.ContainsFunc(syncCfg.Repositories.Exclude, func(excluded string) bool { return strings.EqualFold(excluded, name) }) {
+					logger.Info().Str("submodule", submodule.URL).Msg("Submodule repository is excluded, not mirroring it")
+
+					continue
+				}
+
+				missing[name] = true
+			}
+		}
+
+		if len(missing) == 0 {
+			break
+		}
+
+		if ownerProjectInfos == nil {
+			providerOption := model.NewProviderOption(syncCfg.IncludeForks, syncCfg.Owner, syncCfg.OwnerType, nil, nil)
+
+			var err error
+			if ownerProjectInfos, err = gitProvider.GetProjectInfos(ctx, providerOption, false); err != nil {
+				return nil, fmt.Errorf("%w: failed to fetch project informations: %w", ErrSubmodules, err)
+			}
+		}
+
+		added := []model.ProjectInfo{}
+
+		for _, projectInfo := range ownerProjectInfos {
+			if missing[strings.ToLower(projectInfo.OriginalName)] {
+				logger.Info().Str("name", projectInfo.OriginalName).Msg("Mirroring submodule repository")
+
+				delete(missing, strings.ToLower(projectInfo.OriginalName))
+
+				added = append(added, projectInfo)
+			}
+		}
+
+		for name := range missing {
+			logger.Warn().Str("name", name).Str("owner", syncCfg.Owner).Msg("Submodule repository not found at the source, not mirroring it")
+		}
+
+		cloned, err := Clone(ctx, reader, syncCfg, added)
+		if err != nil {
+			return nil, err
+		}
+
+		repositories = append(repositories, cloned...)
+		pending = cloned
+	}
+
+	return repositories, nil
+}
+
+// ReportSubmodules logs where the submodules of the repository are at the mirror. The submodule repositories
+// of the source owner are mirrored alongside it, the others stay where the submodule URLs point.
+// With the rewrite_submodule_urls setting the repository of a directory mirror is configured to clone
+// its submodules from the directory mirror, leaving .gitmodules as mirrored.
+func ReportSubmodules(ctx context.Context, syncCfg config.SyncConfig, mirrorCfg config.MirrorConfig, repository interfaces.GitRepository) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering ReportSubmodules")
+
+	submodules, err := Submodules(ctx, repository)
+	if err != nil || len(submodules) == 0 {
+		return err
+	}
+
+	urls := map[string]string{}
+
+	for _, submodule := range submodules {
+		event := logger.Info().Str("submodule", submodule.Path).Str("url", submodule.URL)
+
+		if !submodule.BelongsTo(syncCfg.GetDomain(), syncCfg.Owner) {
+			event.Msg("Submodule outside the source owner, the mirror refers to its source")
+
+			continue
+		}
+
+		mirroredURL := submoduleMirrorURL(ctx, mirrorCfg, submodule)
+		if mirroredURL == "" {
+			event.Msg("Submodule repository archived alongside")
+
+			continue
+		}
+
+		urls[submodule.Name] = mirroredURL
+
+		event.Str("mirrorURL", stringconvert.RemoveBasicAuthFromURL(ctx, mirroredURL, true)).Msg("Submodule mirrored")
+	}
+
+	if !mirrorCfg.Settings.RewriteSubmoduleURLs || len(urls) == 0 {
+		return nil
+	}
+
+	return rewriteSubmoduleURLs(ctx, filepath.Join(mirrorCfg.Path, repository.ProjectInfo().Name(ctx)), urls)
+}
+
+// submoduleMirrorURL returns where the mirror keeps a submodule repository of the source owner,
+// empty for an archive or s3 mirror.
+func submoduleMirrorURL(ctx context.Context, mirrorCfg config.MirrorConfig, submodule model.Submodule) string {
+	name := submodule.RepositoryName
+	if model.CLIOptions(ctx).AlphaNumHyphName || mirrorCfg.Settings.AlphaNumHyphName {
+		name = stringconvert.RemoveNonAlphaNumericChars(ctx, name)
+	}
+
+	switch mirrorCfg.ProviderType {
+	case config.DIRECTORY:
+		return filepath.Join(mirrorCfg.Path, name)
+	case config.ARCHIVE, config.S3:
+		return ""
+	default:
+		return mirrorURL(ctx, mirrorCfg, name)
+	}
+}
+
+// rewriteSubmoduleURLs sets the submodule URLs in the configuration of the repository at repositoryPath,
+// which git clones submodules from instead of the URLs in .gitmodules.
+func rewriteSubmoduleURLs(ctx context.Context, repositoryPath string, urls map[string]string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering rewriteSubmoduleURLs")
+
+	repo, err := git.PlainOpen(repositoryPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSubmodules, err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSubmodules, err)
+	}
+
+	for name, url := range urls {
+		if submodule, ok := cfg.Submodules[name]; ok {
+			submodule.URL = url
+
+			continue
+		}
+
+		cfg.Submodules[name] = &gogitconfig.Submodule{Name: name, URL: url}
+	}
+
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("%w: %w", ErrSubmodules, err)
+	}
+
+	logger.Info().Str("path", repositoryPath).Int("submodules", len(urls)).Msg("Rewrote submodule URLs to the directory mirror")
+
+	return nil
+}
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const testGitModules = `[submodule "lib"]
+	path = vendor/lib
+	url = ../lib.git
+[submodule "tools"]
+	path = tools
+	url = git@github.com:owner/tools.git
+[submodule "external"]
+	path = external
+	url = https://gitlab.com/other/external.git
+`
+
+// newSubmoduleRepository creates a repository whose main branch declares the test submodules.
+func newSubmoduleRepository(t *testing.T, dir string) model.Repository {
+	t.Helper()
+
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte(testGitModules), 0o600))
+
+	_, err = worktree.Add(".gitmodules")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("add submodules", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	repository, err := model.NewRepository(repo)
+	require.NoError(t, err)
+
+	repository.ProjectMetaInfo = &model.ProjectInfo{
+		OriginalName:  "app",
+		DefaultBranch: "main",
+		HTTPSURL:      "https://github.com/owner/app.git",
+	}
+
+	return repository
+}
+
+func TestSubmodules(t *testing.T) {
+	repository := newSubmoduleRepository(t, t.TempDir())
+
+	submodules, err := Submodules(testContext(), repository)
+	require.NoError(t, err)
+	require.Equal(t, []model.Submodule{
+		{Name: "external", Path: "external", URL: "https://gitlab.com/other/external.git", Host: "gitlab.com", Owner: "other", RepositoryName: "external"},
+		{Name: "lib", Path: "vendor/lib", URL: "../lib.git", Host: "github.com", Owner: "owner", RepositoryName: "lib"},
+		{Name: "tools", Path: "tools", URL: "git@github.com:owner/tools.git", Host: "github.com", Owner: "owner", RepositoryName: "tools"},
+	}, submodules)
+
+	require.True(t, submodules[1].BelongsTo("github.com", "Owner"))
+	require.False(t, submodules[0].BelongsTo("github.com", "owner"))
+}
+
+func TestReportSubmodulesRewritesDirectoryMirror(t *testing.T) {
+	mirrorDir := t.TempDir()
+	repository := newSubmoduleRepository(t, filepath.Join(mirrorDir, "app"))
+
+	syncCfg := gpsconfig.SyncConfig{BaseConfig: gpsconfig.BaseConfig{ProviderType: gpsconfig.GITHUB, Domain: "github.com", Owner: "owner"}}
+	mirrorCfg := gpsconfig.MirrorConfig{
+		BaseConfig: gpsconfig.BaseConfig{ProviderType: gpsconfig.DIRECTORY},
+		Path:       mirrorDir,
+		Settings:   gpsconfig.MirrorSettings{RewriteSubmoduleURLs: true},
+	}
+
+	require.NoError(t, ReportSubmodules(testContext(), syncCfg, mirrorCfg, repository))
+
+	repo, err := git.PlainOpen(filepath.Join(mirrorDir, "app"))
+	require.NoError(t, err)
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	require.Len(t, cfg.Submodules, 2)
+	require.Equal(t, filepath.Join(mirrorDir, "lib"), cfg.Submodules["lib"].URL)
+	require.Equal(t, filepath.Join(mirrorDir, "tools"), cfg.Submodules["tools"].URL)
+}
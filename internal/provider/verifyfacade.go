@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var (
+	ErrVerifyRefs       = errors.New("refs failed verification")
+	ErrHistoryRewrite   = errors.New("rewrites history")
+	ErrUnsignedCommit   = errors.New("tip commit is not signed")
+	ErrInvalidSignature = errors.New("tip commit signature does not verify")
+)
+
+const (
+	// quarantinePrefix is the namespace refs failing verification are pushed to in quarantine mode.
+	quarantinePrefix = "refs/quarantine/"
+
+	sshSignaturePrefix = "-----BEGIN SSH SIGNATURE-----"
+)
+
+// verify checks the refs the push updates against the refs of the mirror, before anything is pushed.
+// With verify_history an update that is not a fast-forward, or moves a tag, rewrites history, unless
+// force_push is set for the mirror. With require_signed the tip commits of the matching refs must be signed,
+// and verify against the configured keys. Failing refs refuse the whole push, or with quarantine
+// are pushed to refs/quarantine/ at the mirror instead, leaving their refs at the mirror as they are.
+func verify(ctx context.Context, mirrorCfg config.MirrorConfig, writer interfaces.MirrorWriter, repository interfaces.GitRepository, pushOption *model.PushOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering verify")
+
+	settings := mirrorCfg.Settings
+	verifyHistory := settings.VerifyHistory && !settings.ForcePush
+
+	if !verifyHistory && len(settings.RequireSigned) == 0 {
+		return nil
+	}
+
+	lister, ok := writer.(interfaces.RefLister)
+	if !ok {
+		return nil
+	}
+
+	refSpecs := settings.RefSpecs
+	if len(refSpecs) == 0 {
+		refSpecs = config.DefaultRefSpecs()
+	}
+
+	refNames, err := localRefNames(repository)
+	if err != nil {
+		return err
+	}
+
+	targetRefs, err := lister.ListRefs(ctx, model.CloneOption{URL: toGitURL(ctx, mirrorCfg, repository), AuthCfg: mirrorCfg.Auth})
+	if err != nil {
+		return fmt.Errorf("failed to list mirror refs: %w", err)
+	}
+
+	repo := repository.GoGitRepository()
+	updates := config.ExpandRefSpecs(refSpecs, refNames)
+	failed := map[string]error{}
+
+	for _, update := range updates {
+		src, dst, _ := strings.Cut(update, ":")
+
+		ref, err := repo.Reference(plumbing.ReferenceName(src), true)
+		if err != nil {
+			return fmt.Errorf("failed to read ref %s: %w", src, err)
+		}
+
+		targetHash, exists := targetRefs[dst]
+		if exists && targetHash == ref.Hash().String() {
+			continue
+		}
+
+		if verifyHistory && exists && !fastForward(repo, dst, plumbing.NewHash(targetHash), ref.Hash()) {
+			failed[dst] = ErrHistoryRewrite
+
+			continue
+		}
+
+		if config.MatchesRefPatterns(settings.RequireSigned, dst) {
+			if err := verifySignature(ctx, repo, ref.Hash(), settings); err != nil {
+				failed[dst] = err
+			}
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	failedRefs := make([]string, 0, len(failed))
+	for dst := range failed {
+		failedRefs = append(failedRefs, dst)
+	}
+
+	sort.Strings(failedRefs)
+
+	for _, dst := range failedRefs {
+		logger.Warn().Str("ref", dst).Err(failed[dst]).Bool("quarantine", settings.Quarantine).Msg("Ref failed verification")
+	}
+
+	if !settings.Quarantine {
+		return fmt.Errorf("%w: %s", ErrVerifyRefs, strings.Join(failedRefs, ", "))
+	}
+
+	pushOption.RefSpecs = quarantineRefSpecs(updates, failed, pushOption.Force)
+
+	return nil
+}
+
+// quarantineRefSpecs returns the refspecs pushing the updates, with the failed refs pushed
+// to the quarantine namespace instead. Quarantined refs are force pushed, they are replaced on every sync.
+func quarantineRefSpecs(updates []string, failed map[string]error, force bool) []string {
+	refSpecs := make([]string, 0, len(updates))
+
+	for _, update := range updates {
+		src, dst, _ := strings.Cut(update, ":")
+
+		switch {
+		case failed[dst] != nil:
+			refSpecs = append(refSpecs, "+"+src+":"+quarantinePrefix+strings.TrimPrefix(dst, "refs/"))
+		case force:
+			refSpecs = append(refSpecs, "+"+update)
+		default:
+			refSpecs = append(refSpecs, update)
+		}
+	}
+
+	return refSpecs
+}
+
+// fastForward reports whether updating the ref from target to source keeps the history of the mirror:
+// the target commit is an ancestor of the source commit. A tag never moves.
+func fastForward(repo *git.Repository, refName string, target, source plumbing.Hash) bool {
+	if strings.HasPrefix(refName, "refs/tags/") {
+		return false
+	}
+
+	// A target commit unknown to the source is not part of its history
+	targetCommit, err := repo.CommitObject(target)
+	if err != nil {
+		return false
+	}
+
+	sourceCommit, err := repo.CommitObject(source)
+	if err != nil {
+		return false
+	}
+
+	isAncestor, err := targetCommit.IsAncestor(sourceCommit)
+
+	return err == nil && isAncestor
+}
+
+// verifySignature verifies the signature of the tip commit at hash, peeling annotated tags.
+// Without signing keys and allowed signers any signature is accepted. Otherwise an OpenPGP signature
+// must verify against the signing keys and an SSH signature against the allowed signers.
+func verifySignature(ctx context.Context, repo *git.Repository, hash plumbing.Hash, settings config.MirrorSettings) error {
+	commit, err := tipCommit(repo, hash)
+	if err != nil {
+		return err
+	}
+
+	signature := commit.PGPSignature
+
+	switch {
+	case signature == "":
+		return ErrUnsignedCommit
+	case settings.SigningKeysPath == "" && settings.AllowedSignersPath == "":
+		return nil
+	case strings.HasPrefix(signature, sshSignaturePrefix):
+		if settings.AllowedSignersPath == "" {
+			return fmt.Errorf("%w: ssh signature without allowed_signers_path", ErrInvalidSignature)
+		}
+
+		return verifySSHSignature(ctx, commit, settings.AllowedSignersPath)
+	default:
+		if settings.SigningKeysPath == "" {
+			return fmt.Errorf("%w: openpgp signature without signing_keys_path", ErrInvalidSignature)
+		}
+
+		keyRing, err := os.ReadFile(settings.SigningKeysPath)
+		if err != nil {
+			return fmt.Errorf("failed to read signing keys: %w", err)
+		}
+
+		if _, err := commit.Verify(string(keyRing)); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+		}
+
+		return nil
+	}
+}
+
+// tipCommit returns the commit at hash, or the commit an annotated tag at hash points to.
+func tipCommit(repo *git.Repository, hash plumbing.Hash) (*object.Commit, error) {
+	if tag, err := repo.TagObject(hash); err == nil {
+		commit, err := tag.Commit()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tagged commit: %w", err)
+		}
+
+		return commit, nil
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	return commit, nil
+}
+
+// verifySSHSignature verifies an SSH commit signature with ssh-keygen, as git does:
+// the principals of the signing key are looked up in the allowed signers file, and the signature
+// is verified for the first of them in the git namespace.
+func verifySSHSignature(ctx context.Context, commit *object.Commit, allowedSignersPath string) error {
+	payload := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(payload); err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	reader, err := payload.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	signatureFile, err := os.CreateTemp("", "gps-signature-*")
+	if err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	signaturePath := signatureFile.Name()
+	defer os.Remove(signaturePath)
+
+	_, err = signatureFile.WriteString(commit.PGPSignature)
+	if closeErr := signatureFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	principals, err := exec.CommandContext(ctx, "ssh-keygen", "-Y", "find-principals", "-f", allowedSignersPath, "-s", signaturePath).Output()
+	if err != nil {
+		return fmt.Errorf("%w: signing key not in allowed signers", ErrInvalidSignature)
+	}
+
+	principal, _, _ := strings.Cut(strings.TrimSpace(string(principals)), "\n")
+
+	//nolint:gosec // the arguments are the configured allowed signers file and a principal listed in it
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-Y", "verify", "-f", allowedSignersPath, "-I", principal, "-n", "git", "-s", signaturePath)
+	cmd.Stdin = bytes.NewReader(content)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// refListingWriter is a mirror writer listing fixed mirror refs, failing listings with listErr and pushes with pushErr.
+type refListingWriter struct {
+	refs    map[string]string
+	listErr error
+	pushErr error
+}
+
+func (w refListingWriter) Pull(_ context.Context, _ model.PullOption) error {
+	return nil
+}
+
+func (w refListingWriter) Push(_ context.Context, _ interfaces.GitRepository, _ model.PushOption) error {
+	return w.pushErr
+}
+
+func (w refListingWriter) ListRefs(_ context.Context, _ model.CloneOption) (map[string]string, error) {
+	return w.refs, w.listErr
+}
+
+// newVerifyRepository creates a repository with two commits on main, a feature branch at the first
+// and a v1.0 tag at the second, returning it with the hashes of the commits.
+func newVerifyRepository(t *testing.T) (model.Repository, plumbing.Hash, plumbing.Hash) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	hashes := make([]plumbing.Hash, 0, 2)
+
+	for _, content := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0o600))
+
+		_, err = worktree.Add("file.txt")
+		require.NoError(t, err)
+
+		hash, err := worktree.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+
+		hashes = append(hashes, hash)
+	}
+
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature", hashes[0])))
+
+	_, err = repo.CreateTag("v1.0", hashes[1], nil)
+	require.NoError(t, err)
+
+	repository, err := model.NewRepository(repo)
+	require.NoError(t, err)
+
+	repository.ProjectMetaInfo = &model.ProjectInfo{OriginalName: "app", DefaultBranch: "main"}
+
+	return repository, hashes[0], hashes[1]
+}
+
+func TestVerify(t *testing.T) {
+	repository, first, second := newVerifyRepository(t)
+	unknown := "0123456789012345678901234567890123456789"
+
+	// main fast-forwards, feature was rewritten at the source and the tag moved
+	targetRefs := map[string]string{
+		"refs/heads/main":    first.String(),
+		"refs/heads/feature": unknown,
+		"refs/tags/v1.0":     first.String(),
+	}
+
+	tests := []struct {
+		name         string
+		settings     gpsconfig.MirrorSettings
+		wantErr      error
+		wantRefSpecs []string
+	}{
+		{
+			name:         "verification off",
+			wantRefSpecs: gpsconfig.DefaultRefSpecs(),
+		},
+		{
+			name:     "history rewrite refused",
+			settings: gpsconfig.MirrorSettings{VerifyHistory: true},
+			wantErr:  ErrVerifyRefs,
+		},
+		{
+			name:     "history rewrite quarantined",
+			settings: gpsconfig.MirrorSettings{VerifyHistory: true, Quarantine: true},
+			wantRefSpecs: []string{
+				"+refs/heads/feature:refs/quarantine/heads/feature",
+				"refs/heads/main:refs/heads/main",
+				"+refs/tags/v1.0:refs/quarantine/tags/v1.0",
+			},
+		},
+		{
+			name:         "force push allows rewrites",
+			settings:     gpsconfig.MirrorSettings{VerifyHistory: true, ForcePush: true},
+			wantRefSpecs: gpsconfig.DefaultRefSpecs(),
+		},
+		{
+			name:     "unsigned tip refused",
+			settings: gpsconfig.MirrorSettings{RequireSigned: []string{"refs/heads/main"}},
+			wantErr:  ErrVerifyRefs,
+		},
+		{
+			name:     "unsigned tip quarantined",
+			settings: gpsconfig.MirrorSettings{RequireSigned: []string{"refs/heads/m*"}, Quarantine: true},
+			wantRefSpecs: []string{
+				"refs/heads/feature:refs/heads/feature",
+				"+refs/heads/main:refs/quarantine/heads/main",
+				"refs/tags/v1.0:refs/tags/v1.0",
+			},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			mirrorCfg := gpsconfig.MirrorConfig{
+				BaseConfig: gpsconfig.BaseConfig{ProviderType: gpsconfig.GITLAB, Owner: "mirror"},
+				Settings:   tabletest.settings,
+			}
+			pushOption := model.NewPushOption("https://gitlab.com/mirror/app.git", nil, false, false, gpsconfig.AuthConfig{})
+
+			err := verify(testContext(), mirrorCfg, refListingWriter{refs: targetRefs}, repository, &pushOption)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(t, err, tabletest.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.wantRefSpecs, pushOption.RefSpecs)
+		})
+	}
+
+	require.True(t, fastForward(repository.GoGitRepository(), "refs/heads/main", first, second))
+	require.False(t, fastForward(repository.GoGitRepository(), "refs/heads/main", second, first))
+}
+
+func TestVerifySSHSignature(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	repository, _, second := newVerifyRepository(t)
+	repo := repository.GoGitRepository()
+	dir := t.TempDir()
+
+	allowedSigners := map[string]string{}
+
+	for _, name := range []string{"signer", "other"} {
+		keyPath := filepath.Join(dir, name)
+		require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", name, "-f", keyPath).Run())
+
+		publicKey, err := os.ReadFile(keyPath + ".pub")
+		require.NoError(t, err)
+
+		allowedSigners[name] = filepath.Join(dir, name+"_allowed_signers")
+		require.NoError(t, os.WriteFile(allowedSigners[name], []byte(`test@example.com namespaces="git" `+string(publicKey)), 0o600))
+	}
+
+	// Sign the tip commit the way git does, over the commit without its signature
+	commit, err := repo.CommitObject(second)
+	require.NoError(t, err)
+
+	payload := &plumbing.MemoryObject{}
+	require.NoError(t, commit.EncodeWithoutSignature(payload))
+
+	reader, err := payload.Reader()
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	sign := exec.Command("ssh-keygen", "-Y", "sign", "-f", filepath.Join(dir, "signer"), "-n", "git")
+	sign.Stdin = strings.NewReader(string(content))
+
+	signature, err := sign.Output()
+	require.NoError(t, err)
+
+	commit.PGPSignature = string(signature)
+
+	signed := repo.Storer.NewEncodedObject()
+	require.NoError(t, commit.Encode(signed))
+
+	signedHash, err := repo.Storer.SetEncodedObject(signed)
+	require.NoError(t, err)
+
+	ctx := testContext()
+
+	require.NoError(t, verifySignature(ctx, repo, signedHash, gpsconfig.MirrorSettings{}))
+	require.NoError(t, verifySignature(ctx, repo, signedHash, gpsconfig.MirrorSettings{AllowedSignersPath: allowedSigners["signer"]}))
+	require.ErrorIs(t, verifySignature(ctx, repo, signedHash, gpsconfig.MirrorSettings{AllowedSignersPath: allowedSigners["other"]}), ErrInvalidSignature)
+	require.ErrorIs(t, verifySignature(ctx, repo, signedHash, gpsconfig.MirrorSettings{SigningKeysPath: allowedSigners["signer"]}), ErrInvalidSignature)
+	require.ErrorIs(t, verifySignature(ctx, repo, second, gpsconfig.MirrorSettings{}), ErrUnsignedCommit)
+}
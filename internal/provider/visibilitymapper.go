@@ -22,16 +22,108 @@ func mapVisibility(fromProvider, toProvider, visibility string) (string, error)
 	// Define mapping based on the provided tables
 	mappings := map[string]map[string]map[string]string{
 		"gitlab": {
-			"github": {"public": "public", "internal": "private", "private": "private"},
-			"gitea":  {"public": "public", "internal": "private", "private": "private"},
+			"github":          {"public": "public", "internal": "private", "private": "private"},
+			"gitea":           {"public": "public", "internal": "private", "private": "private"},
+			"forgejo":         {"public": "public", "internal": "private", "private": "private"},
+			"gogs":            {"public": "public", "internal": "private", "private": "private"},
+			"azuredevops":     {"public": "public", "internal": "private", "private": "private"},
+			"bitbucket":       {"public": "public", "internal": "private", "private": "private"},
+			"bitbucketserver": {"public": "public", "internal": "private", "private": "private"},
 		},
 		"github": {
-			"gitlab": {"public": "public", "private": "private"},
-			"gitea":  {"public": "public", "private": "private"},
+			"gitlab":          {"public": "public", "private": "private"},
+			"gitea":           {"public": "public", "private": "private"},
+			"forgejo":         {"public": "public", "private": "private"},
+			"gogs":            {"public": "public", "private": "private"},
+			"azuredevops":     {"public": "public", "private": "private"},
+			"bitbucket":       {"public": "public", "private": "private"},
+			"bitbucketserver": {"public": "public", "private": "private"},
 		},
 		"gitea": {
-			"gitlab": {"public": "public", "private": "private", "limited": "private"},
-			"github": {"public": "public", "private": "private", "limited": "private"},
+			"gitlab":          {"public": "public", "private": "private", "limited": "private"},
+			"github":          {"public": "public", "private": "private", "limited": "private"},
+			"forgejo":         {"public": "public", "private": "private", "limited": "limited"},
+			"gogs":            {"public": "public", "private": "private", "limited": "private"},
+			"azuredevops":     {"public": "public", "private": "private", "limited": "private"},
+			"bitbucket":       {"public": "public", "private": "private", "limited": "private"},
+			"bitbucketserver": {"public": "public", "private": "private", "limited": "private"},
+		},
+		"forgejo": {
+			"gitlab":          {"public": "public", "private": "private", "limited": "private"},
+			"github":          {"public": "public", "private": "private", "limited": "private"},
+			"gitea":           {"public": "public", "private": "private", "limited": "limited"},
+			"gogs":            {"public": "public", "private": "private", "limited": "private"},
+			"azuredevops":     {"public": "public", "private": "private", "limited": "private"},
+			"bitbucket":       {"public": "public", "private": "private", "limited": "private"},
+			"bitbucketserver": {"public": "public", "private": "private", "limited": "private"},
+		},
+		"gogs": {
+			"gitlab":          {"public": "public", "private": "private"},
+			"github":          {"public": "public", "private": "private"},
+			"gitea":           {"public": "public", "private": "private"},
+			"forgejo":         {"public": "public", "private": "private"},
+			"azuredevops":     {"public": "public", "private": "private"},
+			"bitbucket":       {"public": "public", "private": "private"},
+			"bitbucketserver": {"public": "public", "private": "private"},
+		},
+		"azuredevops": {
+			"gitlab":          {"public": "public", "private": "private"},
+			"github":          {"public": "public", "private": "private"},
+			"gitea":           {"public": "public", "private": "private"},
+			"forgejo":         {"public": "public", "private": "private"},
+			"gogs":            {"public": "public", "private": "private"},
+			"bitbucket":       {"public": "public", "private": "private"},
+			"bitbucketserver": {"public": "public", "private": "private"},
+		},
+		"bitbucket": {
+			"gitlab":          {"public": "public", "private": "private"},
+			"github":          {"public": "public", "private": "private"},
+			"gitea":           {"public": "public", "private": "private"},
+			"forgejo":         {"public": "public", "private": "private"},
+			"gogs":            {"public": "public", "private": "private"},
+			"azuredevops":     {"public": "public", "private": "private"},
+			"bitbucketserver": {"public": "public", "private": "private"},
+		},
+		"bitbucketserver": {
+			"gitlab":      {"public": "public", "private": "private"},
+			"github":      {"public": "public", "private": "private"},
+			"gitea":       {"public": "public", "private": "private"},
+			"forgejo":     {"public": "public", "private": "private"},
+			"gogs":        {"public": "public", "private": "private"},
+			"azuredevops": {"public": "public", "private": "private"},
+			"bitbucket":   {"public": "public", "private": "private"},
+		},
+		// A plain git server has no visibility, its repositories are taken as private
+		"git": {
+			"gitlab":          {"private": "private"},
+			"github":          {"private": "private"},
+			"gitea":           {"private": "private"},
+			"forgejo":         {"private": "private"},
+			"gogs":            {"private": "private"},
+			"azuredevops":     {"private": "private"},
+			"bitbucket":       {"private": "private"},
+			"bitbucketserver": {"private": "private"},
+		},
+		// Restored repositories have no recorded visibility and are taken as private
+		"archive": {
+			"gitlab":          {"private": "private"},
+			"github":          {"private": "private"},
+			"gitea":           {"private": "private"},
+			"forgejo":         {"private": "private"},
+			"gogs":            {"private": "private"},
+			"azuredevops":     {"private": "private"},
+			"bitbucket":       {"private": "private"},
+			"bitbucketserver": {"private": "private"},
+		},
+		"directory": {
+			"gitlab":          {"private": "private"},
+			"github":          {"private": "private"},
+			"gitea":           {"private": "private"},
+			"forgejo":         {"private": "private"},
+			"gogs":            {"private": "private"},
+			"azuredevops":     {"private": "private"},
+			"bitbucket":       {"private": "private"},
+			"bitbucketserver": {"private": "private"},
 		},
 	}
 
@@ -45,6 +45,45 @@ func TestMapVisibility(t *testing.T) {
 		{"Gitea Private to GitHub", "gitea", "github", "private", "private", ""},
 		{"Gitea Limited to GitHub", "gitea", "github", "limited", "private", ""},
 
+		// Bitbucket Server mappings
+		{"GitLab Internal to Bitbucket Server", "gitlab", "bitbucketserver", "internal", "private", ""},
+		{"GitHub Public to Bitbucket Server", "github", "bitbucketserver", "public", "public", ""},
+		{"Gitea Limited to Bitbucket Server", "gitea", "bitbucketserver", "limited", "private", ""},
+		{"Bitbucket Server Public to GitLab", "bitbucketserver", "gitlab", "public", "public", ""},
+		{"Bitbucket Server Private to GitHub", "bitbucketserver", "github", "private", "private", ""},
+		{"Bitbucket Server Private to Gitea", "bitbucketserver", "gitea", "private", "private", ""},
+		{"Bitbucket Server Public to Bitbucket", "bitbucketserver", "bitbucket", "public", "public", ""},
+
+		// Azure DevOps mappings
+		{"GitLab Internal to Azure DevOps", "gitlab", "azuredevops", "internal", "private", ""},
+		{"GitHub Public to Azure DevOps", "github", "azuredevops", "public", "public", ""},
+		{"Azure DevOps Public to GitLab", "azuredevops", "gitlab", "public", "public", ""},
+		{"Azure DevOps Private to Bitbucket", "azuredevops", "bitbucket", "private", "private", ""},
+
+		// Bitbucket mappings
+		{"GitLab Internal to Bitbucket", "gitlab", "bitbucket", "internal", "private", ""},
+		{"Gitea Limited to Bitbucket", "gitea", "bitbucket", "limited", "private", ""},
+		{"Bitbucket Public to GitHub", "bitbucket", "github", "public", "public", ""},
+		{"Bitbucket Private to Bitbucket Server", "bitbucket", "bitbucketserver", "private", "private", ""},
+
+		// Forgejo and Gogs mappings
+		{"Gitea Limited to Forgejo", "gitea", "forgejo", "limited", "limited", ""},
+		{"Forgejo Limited to Gitea", "forgejo", "gitea", "limited", "limited", ""},
+		{"Forgejo Limited to GitHub", "forgejo", "github", "limited", "private", ""},
+		{"GitLab Internal to Forgejo", "gitlab", "forgejo", "internal", "private", ""},
+		{"Gitea Limited to Gogs", "gitea", "gogs", "limited", "private", ""},
+		{"Forgejo Limited to Gogs", "forgejo", "gogs", "limited", "private", ""},
+		{"Gogs Public to Gitea", "gogs", "gitea", "public", "public", ""},
+		{"Gogs Private to Bitbucket", "gogs", "bitbucket", "private", "private", ""},
+
+		// Plain git mappings
+		{"Git Private to GitHub", "git", "github", "private", "private", ""},
+		{"Git Private to Forgejo", "git", "forgejo", "private", "private", ""},
+
+		// Restore mappings
+		{"Archive Private to GitLab", "archive", "gitlab", "private", "private", ""},
+		{"Directory Private to GitHub", "directory", "github", "private", "private", ""},
+
 		// Case insensitivity tests
 		{"Case Insensitive Provider", "GitLab", "GitHub", "Public", "public", ""},
 		{"Case Insensitive Visibility", "gitlab", "github", "INTERNAL", "private", ""},
@@ -55,6 +94,8 @@ func TestMapVisibility(t *testing.T) {
 		{"Invalid GitLab Visibility", "gitlab", "github", "invalid", "", "invalid visibility for gitlab: invalid"},
 		{"Invalid GitHub Visibility", "github", "gitlab", "internal", "", "invalid visibility for github: internal"},
 		{"Invalid Gitea Visibility", "gitea", "github", "internal", "", "invalid visibility for gitea: internal"},
+		{"Invalid Gogs Visibility", "gogs", "github", "limited", "", "invalid visibility for gogs: limited"},
+		{"Invalid Bitbucket Server Visibility", "bitbucketserver", "github", "internal", "", "invalid visibility for bitbucketserver: internal"},
 	}
 
 	for _, tabletest := range tests {
@@ -0,0 +1,13 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package syncstate
+
+import "errors"
+
+var (
+	ErrStateDir   = errors.New("failed to find state directory")
+	ErrReadState  = errors.New("failed to read sync state")
+	ErrWriteState = errors.New("failed to write sync state")
+)
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package syncstate persists what previous sync runs pushed, so unchanged
+// repositories can be skipped without cloning them.
+//
+// The state is a JSON file, by default $XDG_STATE_HOME/gitprovidersync/state.json,
+// holding the ref tips last pushed for every source, mirror target and repository.
+package syncstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"itiquette/git-provider-sync/internal/mirror/cache"
+)
+
+const (
+	xdgStateHomeEnv = "XDG_STATE_HOME"
+	stateFilePath   = "gitprovidersync/state.json"
+	stateVersion    = 1
+)
+
+// Entry is the state recorded for a repository pushed to a mirror target.
+type Entry struct {
+	Refs     map[string]string `json:"refs"`      // Ref name to commit hash, as listed from the source
+	SyncedAt time.Time         `json:"synced_at"` // When the refs were last pushed
+}
+
+// Store holds the sync state of all repositories. It is safe for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	entries  map[string]Entry
+	recorded map[string]bool // Keys recorded since the state was loaded
+}
+
+type stateFile struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+type storeKey struct{}
+
+// DefaultPath returns the state file path under $XDG_STATE_HOME,
+// falling back to ~/.local/state when it is not set.
+func DefaultPath() (string, error) {
+	if stateHome, ok := os.LookupEnv(xdgStateHomeEnv); ok && stateHome != "" {
+		return filepath.Join(stateHome, stateFilePath), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrStateDir, err)
+	}
+
+	return filepath.Join(home, ".local", "state", stateFilePath), nil
+}
+
+// Key identifies a repository of a source pushed to a mirror target.
+func Key(source, target, repository string) string {
+	return source + "|" + target + "|" + repository
+}
+
+// Load reads the state file at path. A missing file gives an empty Store.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, entries: map[string]Entry{}, recorded: map[string]bool{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReadState, err)
+	}
+
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrReadState, path, err)
+	}
+
+	if file.Entries != nil {
+		store.entries = file.Entries
+	}
+
+	return store, nil
+}
+
+// Save writes the state to its file, replacing it atomically.
+// The file is locked while it is read again, merged with the entries recorded by this run and written,
+// so runs sharing the file keep each other's entries.
+func (s *Store) Save(ctx context.Context) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := cache.Lock(ctx, s.path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWriteState, err)
+	}
+
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil && err == nil {
+			err = fmt.Errorf("%w: %w", ErrWriteState, unlockErr)
+		}
+	}()
+
+	current, err := Load(s.path)
+	if err != nil {
+		return err
+	}
+
+	for key := range s.recorded {
+		current.entries[key] = s.entries[key]
+	}
+
+	s.entries = current.entries
+
+	data, err := json.MarshalIndent(stateFile{Version: stateVersion, Entries: s.entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWriteState, err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), ".state-*.json")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWriteState, err)
+	}
+
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+
+		return fmt.Errorf("%w: %w", ErrWriteState, err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrWriteState, err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), s.path); err != nil {
+		return fmt.Errorf("%w: %w", ErrWriteState, err)
+	}
+
+	return nil
+}
+
+// Path returns the path of the state file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get returns the entry recorded for key.
+func (s *Store) Get(key string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+
+	return entry, ok
+}
+
+// Unchanged reports whether refs equal the refs last recorded for key.
+func (s *Store) Unchanged(key string, refs map[string]string) bool {
+	entry, ok := s.Get(key)
+
+	return ok && maps.Equal(entry.Refs, refs)
+}
+
+// Record stores refs as pushed for key at syncedAt.
+func (s *Store) Record(key string, refs map[string]string, syncedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = Entry{Refs: maps.Clone(refs), SyncedAt: syncedAt.UTC()}
+	s.recorded[key] = true
+}
+
+// WithStore returns a new context with the given Store added.
+func WithStore(ctx context.Context, store *Store) context.Context {
+	return context.WithValue(ctx, storeKey{}, store)
+}
+
+// FromContext returns the Store of ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Store {
+	store, _ := ctx.Value(storeKey{}).(*Store)
+
+	return store
+}
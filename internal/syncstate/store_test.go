@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package syncstate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(xdgStateHomeEnv, "/state")
+
+	path, err := DefaultPath()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("/state", "gitprovidersync", "state.json"), path)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+
+	_, ok := store.Get(Key("src", "dst", "repo"))
+	require.False(t, ok)
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{invalid"), 0o600))
+
+	_, err := Load(path)
+	require.ErrorIs(t, err, ErrReadState)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	key := Key("gitlab.com/owner", "github.com/owner", "repo")
+	refs := map[string]string{"refs/heads/main": "abc"}
+	syncedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	store, err := Load(path)
+	require.NoError(t, err)
+
+	store.Record(key, refs, syncedAt)
+	require.NoError(t, store.Save(context.Background()))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+
+	entry, ok := loaded.Get(key)
+	require.True(t, ok)
+	require.Equal(t, refs, entry.Refs)
+	require.True(t, syncedAt.Equal(entry.SyncedAt))
+}
+
+func TestSaveKeepsEntriesOfOtherRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	syncedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Both runs load the state before either saves it
+	first, err := Load(path)
+	require.NoError(t, err)
+
+	second, err := Load(path)
+	require.NoError(t, err)
+
+	first.Record(Key("src", "dst", "first"), map[string]string{"refs/heads/main": "abc"}, syncedAt)
+	second.Record(Key("src", "dst", "second"), map[string]string{"refs/heads/main": "def"}, syncedAt)
+
+	require.NoError(t, first.Save(context.Background()))
+	require.NoError(t, second.Save(context.Background()))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+
+	for _, key := range []string{Key("src", "dst", "first"), Key("src", "dst", "second")} {
+		_, ok := loaded.Get(key)
+		require.True(t, ok, key)
+	}
+}
+
+func TestUnchanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded map[string]string
+		refs     map[string]string
+		want     bool
+	}{
+		{
+			name: "nothing recorded",
+			refs: map[string]string{"refs/heads/main": "abc"},
+			want: false,
+		},
+		{
+			name:     "same refs",
+			recorded: map[string]string{"refs/heads/main": "abc", "refs/tags/v1": "def"},
+			refs:     map[string]string{"refs/heads/main": "abc", "refs/tags/v1": "def"},
+			want:     true,
+		},
+		{
+			name:     "moved branch",
+			recorded: map[string]string{"refs/heads/main": "abc"},
+			refs:     map[string]string{"refs/heads/main": "123"},
+			want:     false,
+		},
+		{
+			name:     "new tag",
+			recorded: map[string]string{"refs/heads/main": "abc"},
+			refs:     map[string]string{"refs/heads/main": "abc", "refs/tags/v1": "def"},
+			want:     false,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			store, err := Load(filepath.Join(t.TempDir(), "state.json"))
+			require.NoError(t, err)
+
+			key := Key("src", "dst", "repo")
+			if tabletest.recorded != nil {
+				store.Record(key, tabletest.recorded, time.Now())
+			}
+
+			require.Equal(t, tabletest.want, store.Unchanged(key, tabletest.refs))
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	require.Nil(t, FromContext(context.Background()))
+
+	store := &Store{}
+	require.Same(t, store, FromContext(WithStore(context.Background(), store)))
+}
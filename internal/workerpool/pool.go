@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package workerpool provides a bounded worker pool for processing
+// a known number of work items concurrently.
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// Run calls work once for every index in [0, count), with at most size calls running at the same time.
+// A size less than 1 is treated as 1, which processes the items serially and in order.
+//
+// When a call to work returns an error, the context passed to the remaining calls is cancelled
+// and no further items are started. Run waits for all started calls to return and then
+// returns the error of the lowest failing index, or nil if every call succeeded.
+//
+// Parameters:
+//   - ctx: The parent context for the work.
+//   - size: The maximum number of concurrent calls to work.
+//   - count: The number of work items.
+//   - work: The function processing the item at the given index.
+//
+// Returns:
+//   - error: The first error by index, or nil.
+func Run(ctx context.Context, size, count int, work func(ctx context.Context, index int) error) error {
+	if size < 1 {
+		size = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, count)
+	semaphore := make(chan struct{}, size)
+
+	var waitGroup sync.WaitGroup
+
+	for index := range count {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+
+			if err := work(ctx, index); err != nil {
+				errs[index] = err
+
+				cancel()
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
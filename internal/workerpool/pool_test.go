@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2024 itiquette/git-provider-sync
+//
+// SPDX-License-Identifier: EUPL-1.2
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	errWork := errors.New("work failed")
+
+	tests := []struct {
+		name        string
+		size        int
+		count       int
+		failAt      int
+		wantErr     error
+		wantMaxBusy int32
+	}{
+		{name: "serial when size is zero", size: 0, count: 5, failAt: -1, wantMaxBusy: 1},
+		{name: "bounded by size", size: 3, count: 10, failAt: -1, wantMaxBusy: 3},
+		{name: "size larger than count", size: 10, count: 2, failAt: -1, wantMaxBusy: 2},
+		{name: "no work", size: 2, count: 0, failAt: -1},
+		{name: "returns work error", size: 2, count: 6, failAt: 1, wantErr: errWork},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			var busy, maxBusy atomic.Int32
+
+			done := make([]bool, tabletest.count)
+
+			err := Run(context.Background(), tabletest.size, tabletest.count, func(_ context.Context, index int) error {
+				current := busy.Add(1)
+				defer busy.Add(-1)
+
+				for {
+					seen := maxBusy.Load()
+					if current <= seen || maxBusy.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+
+				time.Sleep(5 * time.Millisecond)
+
+				if index == tabletest.failAt {
+					return errWork
+				}
+
+				done[index] = true
+
+				return nil
+			})
+
+			if tabletest.wantErr != nil {
+				require.ErrorIs(t, err, tabletest.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.wantMaxBusy, maxBusy.Load())
+
+			for index := range done {
+				require.True(t, done[index])
+			}
+		})
+	}
+}
+
+func TestRunStopsAfterError(t *testing.T) {
+	var started atomic.Int32
+
+	err := Run(context.Background(), 1, 10, func(_ context.Context, index int) error {
+		started.Add(1)
+
+		if index == 2 {
+			return errors.New("fail")
+		}
+
+		return nil
+	})
+
+	require.Error(t, err)
+	require.Equal(t, int32(3), started.Load())
+}